@@ -27,28 +27,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	osExec "os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	cfg "github.com/st-tech/gatling-commander/pkg/config"
 	"github.com/st-tech/gatling-commander/pkg/external/cloudstorages"
+	"github.com/st-tech/gatling-commander/pkg/external/metricsproviders"
 	slackTools "github.com/st-tech/gatling-commander/pkg/external/slack"
 	sheetTools "github.com/st-tech/gatling-commander/pkg/external/spreadsheet"
+	"github.com/st-tech/gatling-commander/pkg/internal/abort"
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
 	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/history"
 	kubeapiTools "github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+	"github.com/st-tech/gatling-commander/pkg/internal/runreport"
+	"github.com/st-tech/gatling-commander/pkg/internal/runstate"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
 
 	"github.com/spf13/cobra"
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
 type execFlags struct {
-	skipBuild bool
+	skipBuild  bool
+	dryRun     bool
+	force      bool
+	followLogs bool
+	resume     string
+	baseline   string
+	outputs    []string
 }
 
 type loadtestExecError struct {
@@ -62,10 +84,30 @@ type metricsUsageRatio struct {
 	memory float64
 }
 
+// loadtestResult has results of each loadtest used for deciding whether next loadtest is executed.
+type loadtestResult struct {
+	gatlingReport     *gatlingTools.GatlingReport
+	gatlingStats      *gatlingTools.GatlingStats // nil when stats of each request are not loaded
+	metricsUsageRatio metricsUsageRatio
+	comparison        *baseline.Comparison
+	slo               *slo.Result // nil when no SLO assertion is set
+}
+
+// baselineSource has previous runs from which baseline of each loadtest is found.
+type baselineSource struct {
+	runs        []*runstate.RunState
+	pinnedRunID string
+}
+
 type cloudStorageOperator interface {
 	Fetch(ctx context.Context, path string) ([]byte, error)
 }
 
+type simulationLogOperator interface {
+	List(ctx context.Context, dirPath string) ([]string, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
 type notifyOperator interface {
 	Notify(msg string) error
 }
@@ -75,7 +117,13 @@ type serviceConfig struct {
 	spreadsheetId    string
 	failFast         bool
 	targetLatency    float64
-	targetPercentile uint32
+	targetPercentile float64
+	requests         []cfg.RequestTargetConfig
+	baseline         *cfg.BaselineConfig
+	abortRules       *cfg.AbortRulesConfig
+	metricsProvider  *cfg.MetricsProviderConfig
+	simulationLog    *cfg.SimulationLogConfig
+	slo              *cfg.SLOConfig
 }
 
 type checkContinueToExecResult struct {
@@ -90,12 +138,59 @@ func newExecFlags() *execFlags {
 
 func (f *execFlags) addFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&f.skipBuild, "skip-build", false, "skip build flag")
+	cmd.Flags().BoolVar(
+		&f.dryRun,
+		"dry-run",
+		false,
+		"print Gatling manifests which would be created, without building image, creating objects and writing reports",
+	)
+	cmd.Flags().BoolVar(
+		&f.force,
+		"force",
+		false,
+		"delete running Gatling objects of the same service instead of refusing to start loadtest",
+	)
+	cmd.Flags().BoolVar(
+		&f.followLogs,
+		"follow-logs",
+		false,
+		"print logs of Gatling runner and reporter pods, and save them to logs directory in run directory",
+	)
+	cmd.Flags().StringVar(
+		&f.resume,
+		"resume",
+		"",
+		"path of run-state file of interrupted run, completed scenarios of the run are skipped",
+	)
+	cmd.Flags().StringVar(
+		&f.baseline,
+		"baseline",
+		"",
+		"run ID used as baseline of all services, instead of baseline.runID in config or the last green run",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.outputs,
+		"output",
+		nil,
+		"write result of each loadtest for CI as junit=<path> or json=<path>, can be specified multiple times",
+	)
 }
 
 func (f *execFlags) validateFlags(config *cfg.Config) error {
 	if f.skipBuild && config.ImageURL == "" {
 		return fmt.Errorf("skip-build flag specified, but there is no imageURL value")
 	}
+	if f.resume != "" && f.dryRun {
+		return fmt.Errorf("resume flag can not be specified with dry-run flag")
+	}
+	if len(f.outputs) > 0 && f.dryRun {
+		return fmt.Errorf("output flag can not be specified with dry-run flag")
+	}
+	for _, output := range f.outputs {
+		if _, err := runreport.ParseOutput(output); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -115,13 +210,20 @@ func NewCmdExec(baseName string, config *cfg.Config) *cobra.Command {
 		This command load Gatling Report and get load test target container metrics, and record it in specified Google Sheets.
 		Complete documentation is available at https://github.com/st-tech/gatling-commander/docs`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := runExec(cmd, config, flags)
-			if config.SlackConfig.WebhookURL != "" {
+			state, err := runExec(cmd, config, flags)
+			if len(flags.outputs) > 0 && !flags.dryRun {
+				writeRunReports(flags.outputs, config.GetRunsDir(), state, err)
+			}
+			if config.SlackConfig.WebhookURL != "" && !flags.dryRun {
 				isSuccess := false
 				if err == nil {
 					isSuccess = true
 				}
-				err := runNotify(config.SlackConfig, isSuccess)
+				var details []string
+				if state != nil {
+					details = collectNotificationDetails(*state)
+				}
+				err := runNotify(config.SlackConfig, isSuccess, details)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error failed to notify slack %v\n", err)
 				} else {
@@ -144,41 +246,98 @@ Each loadtest of service run in order.
 If failFast or targetLatency value is set and loadtest finished with this condition, next loadtest of same service is
 not executed. (checkContinueToExec)
 The error occured in each loadtest will be output after all loadtest finished.
+
+If search field is set to service, loadtests of the service are generated by capacity search. (runCapacitySearch)
+
+Progress of each loadtest is recorded to run-state file in run directory. When resume flag is specified, run ID and
+image of the run-state file are reused, and completed loadtests and stopped services are skipped.
+
+Each loadtest result is compared with baseline found in previous runs under runsDir. If any loadtest regressed or
+violated SLO assertion of stop or fail-run severity, runExec returns error after all loadtests finished.
+Run state is returned after loadtests are executed, to notify its details.
+
+SIGINT or SIGTERM cancels run, and running loadtests are recorded as cancelled after their Gatling objects are
+deleted, then error wrapping context.Canceled is returned. The second signal exits immediately. (handleSignals)
 */
-func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) error {
+func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) (*runstate.RunState, error) {
 	ctx, cancel := context.WithCancel(context.Background())
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, os.Interrupt)
+	signalCh := make(chan os.Signal, 2)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	defer func() {
 		signal.Stop(signalCh)
+		close(signalCh)
 		cancel()
 	}()
-	go func() {
-		<-signalCh
-		cancel()
-	}()
+	go handleSignals(signalCh, cancel, os.Exit)
 
 	/*
 		Build and push image.
 		https://go.dev/src/time/format.go
 		Format is YYYYMMDDhhmm.
 	*/
-	execDate := time.Now().Format("200601021504")
+	execTime := time.Now()
+	execDate := execTime.Format("200601021504")
 	imgTag := config.ImagePrefix + "-" + execDate
+	// runID is used to name Gatling objects, so objects of parallel runs and scenarios don't collide.
+	runID := gatlingTools.NewRunID(execTime)
 	var imgURL string
 	if err := flags.validateFlags(config); err != nil {
-		return fmt.Errorf("config param or argument invalid %v", err)
+		return nil, fmt.Errorf("config param or argument invalid %v", err)
 	}
-	if !flags.skipBuild {
+	if flags.dryRun {
+		// Image is not built in dry run, so render URL of image which would be built.
+		imgURL = config.ImageURL
+		if imgURL == "" {
+			imgURL = fmt.Sprintf("%s:%s", config.ImageRepository, imgTag)
+		}
+		return nil, renderGatlingManifests(cmd.OutOrStdout(), config, imgURL, runID)
+	}
+
+	var resumedState *runstate.RunState
+	if flags.resume != "" {
+		state, err := runstate.Load(flags.resume)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load run-state file to resume, %v", err)
+		}
+		resumedState = state
+		runID = state.RunID
+	}
+	fmt.Printf("run id %v\n", runID)
+	if resumedState != nil {
+		// Image of resumed run is reused, so that all loadtests of the run are executed with same image.
+		imgURL = resumedState.ImageURL
+	} else if !flags.skipBuild {
 		genImageURL, err := buildPushImage(config.ImageRepository, imgTag, config.GatlingDockerfileDir)
 		if err != nil {
-			return fmt.Errorf("gatling image build error %v", err)
+			return nil, fmt.Errorf("gatling image build error %v", err)
 		}
 		imgURL = genImageURL
 	} else {
 		imgURL = config.ImageURL
 	}
 
+	recorder, err := initRunStateRecorder(config, flags.resume, resumedState, runID, imgURL, execTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init run-state file, %v", err)
+	}
+	fmt.Printf("run state is recorded to %v\n", recorder.Path())
+	if err := history.WriteConfigSnapshot(recorder.RunDir(), redactConfig(*config)); err != nil {
+		return nil, err
+	}
+
+	baselineRuns, err := runstate.ListRuns(config.GetRunsDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list previous runs for baseline, %v", err)
+	}
+	baselines := baselineSource{
+		runs:        baselineRuns,
+		pinnedRunID: flags.baseline,
+	}
+	statusWatcher, err := startGatlingStatusWatcher(ctx, config, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch gatling objects, %v", err)
+	}
+
 	/*
 		Create channel for receive error in each loadtest run (runLoadtestAndRecord).
 		Set number of loadtests of service as max error buffer length.
@@ -192,10 +351,44 @@ func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) error {
 		go func(ctx context.Context, s cfg.Service) {
 			defer wg.Done()
 			serviceConfig := extractServiceConfig(s)
+			if serviceState := recorder.Service(serviceConfig.name); serviceState.Stopped {
+				fmt.Printf(
+					"service %v loadtest skipped, it was stopped in resumed run, %v\n",
+					serviceConfig.name,
+					serviceState.StopReason,
+				)
+				return
+			}
+			if s.Search != nil {
+				if _, err := runCapacitySearch(
+					ctx, config, statusWatcher, s, imgURL, runID, flags.force, flags.followLogs, recorder, baselines,
+				); err != nil {
+					loadtestErrorCh <- loadtestExecError{
+						serviceName:  serviceConfig.name,
+						scenarioName: "capacity search",
+						err:          err,
+					}
+				}
+				return
+			}
 			for _, scenarioSpec := range s.ScenarioSpecs {
+				// following scenarios are not started after run is cancelled, and kept pending to be resumed.
+				if ctx.Err() != nil {
+					return
+				}
 				serviceName := serviceConfig.name
 				scenarioName := scenarioSpec.Name
 				scenarioSubName := scenarioSpec.SubName
+				scenarioState := recorder.Scenario(serviceName, scenarioName, scenarioSubName)
+				if scenarioState.Get().Status == runstate.StatusCompleted {
+					fmt.Printf(
+						"service %v loadtest %v %v skipped, it was completed in resumed run\n",
+						serviceName,
+						scenarioName,
+						scenarioSubName,
+					)
+					continue
+				}
 				/*
 					loadtestExecError struct type has err field.
 					when error occured, write it to this field and log at parent function too.
@@ -204,23 +397,37 @@ func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) error {
 					serviceName:  serviceName,
 					scenarioName: fmt.Sprintf("%v %v", scenarioName, scenarioSubName),
 				}
-				gatlingReport, err := runLoadtestAndRecord(
+				result, err := runLoadtestAndRecord(
 					ctx,
 					config.GatlingContextName,
+					statusWatcher,
 					imgURL,
+					runID,
 					config.BaseManifest,
 					config.StartupTimeoutSec,
 					config.ExecTimeoutSec,
+					config.CloudStorage,
+					config.RunnerSaturation,
+					flags.force,
+					flags.followLogs,
 					serviceConfig,
-					s.TargetPodConfig,
+					s.TargetPodConfigs(),
 					scenarioSpec,
+					scenarioState,
+					baselines,
 				)
 				if err != nil {
+					err = wrapCancelled(ctx, err)
+					updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+						recordLoadtestError(state, err)
+					})
 					occuredErr.err = err
 					loadtestErrorCh <- occuredErr
 					return
 				}
-				checkContinue, err := checkContinueToExec(serviceConfig, *gatlingReport)
+				checkContinue, err := checkContinueToExec(
+					serviceConfig, *result.gatlingReport, result.gatlingStats, result.comparison, result.slo,
+				)
 				if err != nil {
 					occuredErr.err = err
 					loadtestErrorCh <- occuredErr
@@ -228,6 +435,9 @@ func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) error {
 				}
 				// This cancel condition is not caused by the loadtest error, so only log and finish goroutine.
 				if !checkContinue.shouldContinue {
+					if err := recorder.StopService(serviceName, checkContinue.message); err != nil {
+						fmt.Fprintf(os.Stderr, "failed to record run state, %v\n", err)
+					}
 					fmt.Printf(
 						"service %v loadtest %v execution canceled, %v\n",
 						occuredErr.serviceName,
@@ -239,140 +449,1423 @@ func runExec(cmd *cobra.Command, config *cfg.Config, flags *execFlags) error {
 			}
 		}(ctx, service)
 	}
-	wg.Wait()
-	close(loadtestErrorCh)
-	if len(loadtestErrorCh) > 0 {
-		for result := range loadtestErrorCh {
+	wg.Wait()
+	close(loadtestErrorCh)
+	failedCount := len(loadtestErrorCh)
+	for result := range loadtestErrorCh {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error: failed to run loadtest service %v scenario %v, error %v\n",
+			result.serviceName,
+			result.scenarioName,
+			result.err,
+		)
+	}
+	if ctx.Err() != nil {
+		state := recorder.State()
+		return &state, fmt.Errorf("run cancelled, %w", ctx.Err())
+	}
+	if failedCount > 0 {
+		state := recorder.State()
+		return &state, fmt.Errorf("more than one loadtest scenario failed")
+	}
+	state := recorder.State()
+	regressions := collectRegressions(state)
+	for _, regression := range regressions {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", regression)
+	}
+	sloFailures := collectSLOFailures(state)
+	for _, sloFailure := range sloFailures {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", sloFailure)
+	}
+	if len(regressions) > 0 {
+		return &state, fmt.Errorf("regression detected in %v loadtests", len(regressions))
+	}
+	if len(sloFailures) > 0 {
+		return &state, fmt.Errorf("slo violated in %v loadtests", len(sloFailures))
+	}
+	return &state, nil
+}
+
+/*
+writeRunReports writes result of each loadtest of run to outputs, such as "junit=results/junit.xml".
+
+Reports are written even when run failed before loadtests, so that CI can tell the run failed. Failure of writing
+a report is only printed, not to hide the result of the run.
+*/
+func writeRunReports(outputs []string, runsDir string, state *runstate.RunState, runErr error) {
+	if state == nil {
+		state = &runstate.RunState{}
+	}
+	var records []history.Record
+	if state.RunID != "" {
+		loaded, err := history.ListRecords(runsDir, history.Filter{RunID: state.RunID})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error failed to load history records for run report %v\n", err)
+		}
+		records = loaded
+	}
+	report := runreport.New(*state, records, runErr)
+	for _, value := range outputs {
+		output, err := runreport.ParseOutput(value)
+		if err == nil {
+			err = output.Write(report)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error failed to write run report %v, %v\n", value, err)
+			continue
+		}
+		fmt.Printf("run report is written to %v\n", output.Path)
+	}
+}
+
+/*
+collectNotificationDetails returns summaries of loadtests to be noticed in run.
+
+Loadtests which were cancelled, of which load generator saturated, target pods were not healthy or SLO assertion
+was violated are summarized.
+*/
+func collectNotificationDetails(state runstate.RunState) []string {
+	var details []string
+	for _, service := range state.Services {
+		for _, scenario := range service.Scenarios {
+			if scenario.Status == runstate.StatusCancelled {
+				details = append(details, fmt.Sprintf(
+					"service %v scenario %v %v, cancelled", service.Name, scenario.Name, scenario.SubName,
+				))
+			}
+			if scenario.LoadGeneratorSaturated {
+				details = append(details, fmt.Sprintf(
+					"service %v scenario %v %v, load generator saturated, %v",
+					service.Name,
+					scenario.Name,
+					scenario.SubName,
+					strings.Join(scenario.SaturationReasons, "; "),
+				))
+			}
+			if len(scenario.UnhealthyTargets) > 0 {
+				details = append(details, fmt.Sprintf(
+					"service %v scenario %v %v, %v",
+					service.Name,
+					scenario.Name,
+					scenario.SubName,
+					strings.Join(scenario.UnhealthyTargets, "; "),
+				))
+			}
+			if len(scenario.SLO.Violations()) > 0 {
+				details = append(details, fmt.Sprintf(
+					"service %v scenario %v %v, %v", service.Name, scenario.Name, scenario.SubName, scenario.SLO.Summary(),
+				))
+			}
+		}
+	}
+	return details
+}
+
+/*
+collectRegressions returns summaries of regressed loadtests in run.
+
+Capacity search trials are meant to overload the target until it fails, so their regressions don't fail run.
+*/
+func collectRegressions(state runstate.RunState) []string {
+	var regressions []string
+	for _, service := range state.Services {
+		if service.Capacity != nil {
+			continue
+		}
+		for _, scenario := range service.Scenarios {
+			if scenario.Comparison.Regressed() {
+				regressions = append(regressions, fmt.Sprintf(
+					"service %v scenario %v %v, %v",
+					service.Name,
+					scenario.Name,
+					scenario.SubName,
+					scenario.Comparison.Summary(),
+				))
+			}
+		}
+	}
+	return regressions
+}
+
+/*
+collectSLOFailures returns summaries of loadtests which violated SLO assertion of stop or fail-run severity in run.
+
+SLO of capacity search trials decides capacity, so it doesn't fail run.
+*/
+func collectSLOFailures(state runstate.RunState) []string {
+	var sloFailures []string
+	for _, service := range state.Services {
+		if service.Capacity != nil {
+			continue
+		}
+		for _, scenario := range service.Scenarios {
+			if scenario.SLO.Failed() {
+				sloFailures = append(sloFailures, fmt.Sprintf(
+					"service %v scenario %v %v, %v",
+					service.Name,
+					scenario.Name,
+					scenario.SubName,
+					scenario.SLO.Summary(),
+				))
+			}
+		}
+	}
+	return sloFailures
+}
+
+/*
+runLoadtestAndRecord is main logic in exec command.
+
+runLoadtestAndRecord Create gatling object and run loadtest, fetch container metrics of each loadtest target.
+Wait loadtest running with statusWatcher and get gatling report, write report to spreadsheet.
+Gatling object, report path and outputs are recorded to run-state file through scenarioState.
+Loadtest result is compared with baseline, and the comparison is written to spreadsheet and run-state file.
+SLO assertions of service and scenarioSpec are evaluated with the result, and recorded to run-state file.
+When runnerSaturation is set, metrics of Gatling runner pods are fetched too, and saturation of them is recorded.
+When followLogs is true, logs of Gatling runner and reporter pods are printed and saved while Gatling Job running.
+*/
+func runLoadtestAndRecord(
+	ctx context.Context,
+	k8sCtxName string,
+	statusWatcher *gatlingTools.StatusWatcher,
+	imgURL, runID, manifestPath string,
+	waitStartupTimeout int32,
+	waitExecTimeout int32,
+	cloudStorageConfig cfg.CloudStorageConfig,
+	runnerSaturation *cfg.RunnerSaturationConfig,
+	force, followLogs bool,
+	serviceConfig serviceConfig,
+	targetPodConfigs []cfg.TargetPodConfig,
+	scenarioSpec cfg.ScenarioSpec,
+	scenarioState *runstate.ScenarioRecorder,
+	baselineSource baselineSource,
+) (*loadtestResult, error) {
+	scenarioName := scenarioSpec.Name
+	serviceName := serviceConfig.name
+
+	fmt.Printf("Start service %v loadtest %v\n", serviceName, scenarioName)
+	startedAt := time.Now()
+
+	sloConfig := cfg.MergeSLO(serviceConfig.slo, scenarioSpec.SLO)
+	sloAssertions, err := sloConfig.ParseAssertions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse slo assertions, %v", err)
+	}
+
+	gatling, err := loadAndPatchBaseGatling(serviceName, imgURL, runID, scenarioSpec, manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch gatling struct field, %v", err)
+	}
+
+	// Fetch resources limit value of each target before run loadtest.
+	targets := make([]abort.Target, 0, len(targetPodConfigs))
+	for _, podConfig := range targetPodConfigs {
+		podCl, err := kubeapiTools.InitClient(podConfig.ContextName)
+		fmt.Printf(
+			"service %v loadtest %v, k8s target %v pod client initialized\n", serviceName, scenarioName, podConfig.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init target %v pod k8s cluster client, %v", podConfig.Name, err)
+		}
+		resourcesLimit, err := kubeapiTools.FetchContainerResourcesLimit(ctx, podCl, podConfig)
+		fmt.Printf(
+			"service %v loadtest %v, target %v pod resources limit fetched\n", serviceName, scenarioName, podConfig.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get target %v pod spec resources %v", podConfig.Name, err)
+		}
+		targets = append(targets, abort.Target{PodConfig: podConfig, ResourcesLimit: *resourcesLimit, PodCl: podCl})
+	}
+
+	k8sGatlingClient, err := kubeapiTools.InitClient(k8sCtxName)
+	fmt.Printf("service %v loadtest %v, k8s gatling client initialized\n", serviceName, scenarioName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init k8s cluster client, %v", err)
+	}
+
+	attached, err := createOrAttachGatling(ctx, k8sGatlingClient, gatling, force, scenarioState.Get())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gatling object, %v", err)
+	}
+	if attached {
+		fmt.Printf(
+			"service %v loadtest %v, attached to Gatling Object %v of resumed run\n",
+			serviceName,
+			scenarioName,
+			gatling.ObjectMeta.Name,
+		)
+	} else {
+		fmt.Printf(
+			"service %v loadtest %v, Gatling Object %v created\n", serviceName, scenarioName, gatling.ObjectMeta.Name,
+		)
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.Status = runstate.StatusRunning
+		state.GatlingName = gatling.ObjectMeta.Name
+		state.GatlingNamespace = gatling.ObjectMeta.Namespace
+		state.Error = ""
+		state.Outcome = ""
+	})
+
+	err = gatlingTools.WaitGatlingJobStartup(ctx, k8sGatlingClient, statusWatcher, gatling, waitStartupTimeout)
+	fmt.Printf("service %v loadtest %v, Gatling Job Started\n", serviceName, scenarioName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait gatling job start, %w", err)
+	}
+
+	for i, target := range targets {
+		metricsSource, err := newTargetMetricsSource(serviceConfig.metricsProvider, target)
+		fmt.Printf(
+			"service %v loadtest %v, target %v metrics source %v initialized\n",
+			serviceName,
+			scenarioName,
+			target.PodConfig.Name,
+			targetMetricsSourceName(serviceConfig.metricsProvider),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init metrics source for fetch target %v metrics, %v", target.PodConfig.Name, err)
+		}
+		targets[i].Metrics = metricsSource
+	}
+
+	abortChecker, err := newAbortChecker(ctx, k8sCtxName, serviceConfig, targets, gatling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init abort rules monitor, %v", err)
+	}
+
+	wg := new(sync.WaitGroup)
+	informJobFinishCh := make(chan bool, 1)
+	// Background fetchers and watchers are stopped on every return path, not to leave them polling APIs after
+	// loadtest was aborted or failed.
+	var stopWatchingOnce sync.Once
+	stopWatching := func() {
+		stopWatchingOnce.Do(func() {
+			// closed channel informs job finished to every background goroutine which did not receive the value.
+			close(informJobFinishCh)
+			wg.Wait()
+		})
+	}
+	defer stopWatching()
+	metricsSeriesChs := make([]chan kubeapiTools.MetricsSeries, 0, len(targets))
+	podHealthChs := make([]chan kubeapiTools.PodHealth, 0, len(targets))
+	// Fetch target container metrics and watch health of target pods of each target in background during loadtest.
+	watchingAt := time.Now()
+	for _, target := range targets {
+		metricsSeriesCh := make(chan kubeapiTools.MetricsSeries, 1)
+		metricsSeriesChs = append(metricsSeriesChs, metricsSeriesCh)
+		podHealthCh := make(chan kubeapiTools.PodHealth, 1)
+		podHealthChs = append(podHealthChs, podHealthCh)
+		wg.Add(2)
+		go kubeapiTools.FetchContainerMetricsSeries(
+			ctx, wg, target.PodCl, target.Metrics, metricsSeriesCh, informJobFinishCh, target.PodConfig,
+		)
+		go kubeapiTools.WatchPodHealth(ctx, wg, target.PodCl, podHealthCh, informJobFinishCh, target.PodConfig, watchingAt)
+	}
+	// Fetch runner container metrics of Gatling runner pods in background to check saturation of load generator.
+	var runnerSeriesCh chan kubeapiTools.MetricsSeries
+	if runnerSaturation != nil {
+		runnerMetricsCl, err := kubeapiTools.InitMetricsClient(k8sCtxName)
+		fmt.Printf("service %v loadtest %v, k8s gatling runner metrics client initialized\n", serviceName, scenarioName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init k8s client for fetch gatling runner metrics, %v", err)
+		}
+		runnerSeriesCh = make(chan kubeapiTools.MetricsSeries, 1)
+		wg.Add(1)
+		go kubeapiTools.FetchContainerMetricsSeries(
+			ctx,
+			wg,
+			k8sGatlingClient,
+			kubeapiTools.MetricsServerSource{Client: runnerMetricsCl},
+			runnerSeriesCh,
+			informJobFinishCh,
+			runnerPodConfig(k8sCtxName, gatling),
+		)
+	}
+
+	stopFollowingLogs := func() {}
+	if followLogs {
+		stop, logPath, err := startFollowingLogs(ctx, k8sCtxName, gatling, serviceName, scenarioName, scenarioState)
+		if err != nil {
+			return nil, fmt.Errorf("failed to follow logs of gatling pods, %v", err)
+		}
+		stopFollowingLogs = stop
+		updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+			state.Outputs = append(state.Outputs, fmt.Sprintf("logs %v", logPath))
+		})
+	}
+
+	// Wait until Gatling job completed.
+	runningAt := time.Now()
+	fmt.Printf("service %v loadtest %v, waiting Gatling Job Running\n", serviceName, scenarioName)
+	err = gatlingTools.WaitGatlingJobRunning(
+		ctx, k8sGatlingClient, statusWatcher, gatling, waitExecTimeout, informJobFinishCh, abortChecker,
+	)
+	stopFollowingLogs()
+	fmt.Printf("service %v loadtest %v, waiting Gatling Job completed\n", serviceName, scenarioName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait gatling job running, %w", err)
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.Outcome = string(gatlingTools.OutcomeSucceeded)
+	})
+	finishedAt := time.Now()
+	stopWatching() // Wait background goroutines finish and send their results.
+	providerMetrics := collectProviderMetrics(ctx, serviceConfig, scenarioName, runningAt, finishedAt)
+
+	targetMetricses := make([]history.TargetMetrics, 0, len(targets))
+	var metricsUsageRatio metricsUsageRatio
+	for i, target := range targets {
+		close(metricsSeriesChs[i])
+		var recordedSeries *kubeapiTools.MetricsSeries // nil when no sample was fetched
+		if metricsSeries, ok := <-metricsSeriesChs[i]; !ok {
+			fmt.Fprintf(
+				os.Stderr,
+				"target %v metricsSeriesCh value is empty, so each metricsUsage field value is 0\n",
+				target.PodConfig.Name,
+			)
+		} else {
+			recordedSeries = &metricsSeries
+			fmt.Printf(
+				"service %v loadtest %v, target %v container cpu %v, memory %v\n",
+				serviceName,
+				scenarioName,
+				target.PodConfig.Name,
+				formatResourceStats(metricsSeries.Cpu, "m"),
+				formatResourceStats(metricsSeries.Memory, "B"),
+			)
+			printPodUtilisation(serviceName, scenarioName, target.PodConfig.Name, metricsSeries)
+		}
+		targetMetrics, ratio := summarizeTargetMetrics(target.PodConfig.Name, recordedSeries, target.ResourcesLimit)
+		targetMetrics.Source = targetMetricsSourceName(serviceConfig.metricsProvider)
+		close(podHealthChs[i])
+		if podHealth, ok := <-podHealthChs[i]; ok {
+			targetMetrics.Health = &podHealth
+			printPodHealth(serviceName, scenarioName, target.PodConfig.Name, podHealth)
+		}
+		targetMetricses = append(targetMetricses, targetMetrics)
+		if i == 0 {
+			// metrics of the primary target are written to spreadsheet and used by baseline and capacity search.
+			metricsUsageRatio = ratio
+		}
+	}
+	primaryMetrics := targetMetricses[0].ContainerMetrics
+	if unhealthyTargets := collectUnhealthyTargets(targetMetricses); len(unhealthyTargets) > 0 {
+		updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+			state.UnhealthyTargets = unhealthyTargets
+		})
+	}
+
+	var runnerMetrics *history.RunnerMetrics // nil when saturation of load generator is not checked
+	if runnerSaturation != nil {
+		close(runnerSeriesCh)
+		var runnerSeries *kubeapiTools.MetricsSeries
+		if series, ok := <-runnerSeriesCh; ok {
+			runnerSeries = &series
+		}
+		runnerMetrics, err = checkRunnerSaturation(runnerSeries, gatling.Spec.PodSpec.Resources, *runnerSaturation)
+		if err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"service %v loadtest %v, skip load generator saturation check, %v\n",
+				serviceName,
+				scenarioName,
+				err,
+			)
+		} else if runnerMetrics.Saturated {
+			for _, reason := range runnerMetrics.SaturationReasons {
+				fmt.Fprintf(
+					os.Stderr,
+					"Warning: service %v loadtest %v, load generator saturated, %v\n",
+					serviceName,
+					scenarioName,
+					reason,
+				)
+			}
+			updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+				state.LoadGeneratorSaturated = true
+				state.SaturationReasons = runnerMetrics.SaturationReasons
+			})
+		}
+	}
+
+	reportStoragePath, err := gatlingTools.GetGatlingReportStoragePath(ctx, k8sGatlingClient, gatling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gatling report storage path, %v", err)
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.ReportStoragePath = reportStoragePath
+	})
+
+	// Storage backend is chosen from the scheme of report storage path, or cloudStorageSpec provider of Gatling object.
+	storageOp, err := cloudstorages.NewOperator(
+		ctx, reportStoragePath, cloudStorageOptions(cloudStorageConfig, gatling),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cloud storage operator client, %v", err)
+	}
+
+	// Fetch storage path from Gatling object and fetch gatlingReport from storage. And parse jsonBytes of gatlingReport
+	// to GatlingReport object.
+	gatlingReport, err := loadGatlingReportFromCloudStorage(ctx, storageOp, k8sGatlingClient, gatling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gatling report from cloud storage, %v", err)
+	}
+	// Percentiles of the report depend on gatling.conf, the default ones are assumed when they can not be read.
+	reportPercentiles, err := loadReportPercentilesFromCloudStorage(ctx, storageOp, reportStoragePath)
+	if err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"service %v loadtest %v, assume default report percentiles %v, %v\n",
+			serviceName,
+			scenarioName,
+			gatlingTools.DefaultReportPercentiles,
+			err,
+		)
+		reportPercentiles = gatlingTools.DefaultReportPercentiles
+	}
+	gatlingReport.Percentiles = reportPercentiles
+	// Stats of each request are optional, loadtest result is still recorded without them.
+	gatlingStats, err := loadGatlingStatsFromCloudStorage(ctx, storageOp, reportStoragePath)
+	if err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"service %v loadtest %v, skip results of each request, %v\n",
+			serviceName,
+			scenarioName,
+			err,
+		)
+	}
+	gatlingStats.SetPercentiles(reportPercentiles)
+	printRequestStats(serviceName, scenarioName, gatlingStats)
+
+	var simulationLogStats *gatlingTools.SimulationLogStats // nil when simulation.log is not loaded
+	if serviceConfig.simulationLog != nil {
+		simulationLogStats, err = loadSimulationLogFromCloudStorage(
+			ctx, storageOp, reportStoragePath, serviceConfig.simulationLog.WarmUpSec,
+			serviceConfig.exactPercentiles(sloAssertions)...,
+		)
+		if err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"service %v loadtest %v, skip simulation.log series, %v\n",
+				serviceName,
+				scenarioName,
+				err,
+			)
+		} else {
+			printSimulationLogStats(serviceName, scenarioName, simulationLogStats)
+			gatlingReport.ExactPercentiles = simulationLogStats.Summary.Percentiles
+		}
+	}
+
+	// Gatling object is named per run, so it is deleted once its report is fetched not to leave objects, Jobs and pods
+	// of every run in the namespace. It is kept when its report can not be fetched, to investigate the report storage.
+	if err := gatlingTools.DeleteGatling(ctx, k8sGatlingClient, gatling); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"service %v loadtest %v, failed to delete completed Gatling Object %v, %v\n",
+			serviceName,
+			scenarioName,
+			gatling.ObjectMeta.Name,
+			err,
+		)
+	}
+
+	metrics := baseline.NewMetrics(gatlingReport, primaryMetrics.CPUUsageMean)
+	if simulationLogStats != nil && simulationLogStats.Summary.Requests > 0 {
+		// Summary of simulation.log excludes warm-up window, so steady state is compared with baseline.
+		metrics = baseline.NewMetricsFromSimulationLog(
+			simulationLogStats.Summary, serviceConfig.simulationLog.WarmUpSec, primaryMetrics.CPUUsageMean,
+		)
+	}
+	if metrics.LatencyUnknown && serviceConfig.baseline != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"service %v loadtest %v, not comparable with baseline, p95 or p99 latency is not in report percentiles %v\n",
+			serviceName,
+			scenarioName,
+			gatlingReport.ReportPercentiles(),
+		)
+	}
+	comparison := compareWithBaseline(baselineSource, runID, serviceConfig, scenarioSpec, metrics)
+	fmt.Printf("service %v loadtest %v, %v\n", serviceName, scenarioName, comparison.Summary())
+	sloResult := evaluateSLO(sloConfig, sloAssertions, gatlingReport, metricsUsageRatio)
+	if sloResult != nil {
+		fmt.Printf("service %v loadtest %v, %v\n", serviceName, scenarioName, sloResult.Summary())
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.Metrics = &metrics
+		state.Comparison = comparison
+		state.SLO = sloResult
+	})
+
+	// Persist loadtest result to run-history store before writing it to spreadsheet, not to lose it.
+	recordPath, err := history.WriteRecord(scenarioState.RunDir(), history.Record{
+		RunID:           runID,
+		ServiceName:     serviceName,
+		ScenarioName:    scenarioName,
+		ScenarioSubName: scenarioSpec.SubName,
+		ImageURL:        imgURL,
+		StartedAt:       startedAt,
+		FinishedAt:      time.Now(),
+		Gatling:         gatling,
+		GatlingReport:   gatlingReport,
+		GatlingStats:    gatlingStats,
+		Container:       primaryMetrics,
+		Targets:         targetMetricses,
+		ProviderMetrics: providerMetrics,
+		Runner:          runnerMetrics,
+		Metrics:         metrics,
+		Comparison:      comparison,
+		SimulationLog:   simulationLogStats,
+		SLO:             sloResult,
+	})
+	if err != nil {
+		return nil, err
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.Outputs = append(state.Outputs, fmt.Sprintf("history %v", recordPath))
+	})
+	if hasMetricsSeries(targetMetricses) {
+		samplesPath, err := history.WriteMetricsSamples(scenarioState.RunDir(), gatling.ObjectMeta.Name, targetMetricses)
+		if err != nil {
+			return nil, err
+		}
+		updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+			state.Outputs = append(state.Outputs, fmt.Sprintf("metrics %v", samplesPath))
+		})
+	}
+	if simulationLogStats != nil {
+		seriesPath, err := history.WriteSimulationLogSeries(
+			scenarioState.RunDir(), gatling.ObjectMeta.Name, simulationLogStats.Series,
+		)
+		if err != nil {
+			return nil, err
+		}
+		updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+			state.Outputs = append(state.Outputs, fmt.Sprintf("series %v", seriesPath))
+		})
+	}
+
+	// Write loadtest report to spreadsheet.
+	fmt.Printf("service %v loadtest %v, start to write Gatling Report to Spreadsheets\n", serviceName, scenarioName)
+	sheetTitle, err := writeReportToSpreadsheets(
+		ctx, imgURL, serviceConfig, scenarioSpec, gatlingReport, gatlingStats, metricsUsageRatio, comparison,
+		loadGeneratorSummary(runnerMetrics),
+		targetHealthSummary(targetMetricses),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write gatling report to spreadsheets, %v", err)
+	}
+	updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+		state.Status = runstate.StatusCompleted
+		state.Outputs = append(
+			state.Outputs, fmt.Sprintf("spreadsheet %v sheet %v", serviceConfig.spreadsheetId, sheetTitle),
+		)
+	})
+
+	fmt.Printf("service %v loadtest %v succeeded\n", serviceName, scenarioName)
+	return &loadtestResult{
+		gatlingReport:     gatlingReport,
+		gatlingStats:      gatlingStats,
+		metricsUsageRatio: metricsUsageRatio,
+		comparison:        comparison,
+		slo:               sloResult,
+	}, nil
+}
+
+/*
+evaluateSLO evaluates SLO assertions of loadtest with gatlingReport and usage ratio of the primary target.
+
+Returns nil when no assertion is set.
+*/
+func evaluateSLO(
+	sloConfig *cfg.SLOConfig,
+	assertions []slo.Assertion,
+	gatlingReport *gatlingTools.GatlingReport,
+	ratio metricsUsageRatio,
+) *slo.Result {
+	values := slo.Values{
+		Report:        gatlingReport,
+		CPUPercent:    ratio.cpu * 100, // conv ratio to percentage
+		MemoryPercent: ratio.memory * 100,
+	}
+	if sloConfig != nil {
+		values.TargetRPS = sloConfig.TargetRPS
+	}
+	return slo.Evaluate(assertions, values)
+}
+
+/*
+startFollowingLogs starts to follow logs of runner and reporter pods of gatling in background.
+
+Logs are printed with service and scenario name, and written to per-scenario log file in run directory.
+Returns function which stops following logs and closes log file, and path of the log file.
+*/
+func startFollowingLogs(
+	ctx context.Context,
+	k8sCtxName string,
+	gatling *gatlingv1alpha1.Gatling,
+	serviceName, scenarioName string,
+	scenarioState *runstate.ScenarioRecorder,
+) (func(), string, error) {
+	cs, err := kubeapiTools.InitClientset(k8sCtxName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to init k8s clientset for gatling logs, %v", err)
+	}
+	logFile, err := history.CreateLogFile(scenarioState.RunDir(), gatling.ObjectMeta.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	follower := gatlingTools.NewLogFollower(
+		cs, gatling, fmt.Sprintf("%v %v", serviceName, scenarioName), os.Stdout, logFile,
+	)
+	follower.Start(ctx)
+	stop := func() {
+		follower.Stop()
+		if err := logFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close log file %v, %v\n", logFile.Name(), err)
+		}
+	}
+	return stop, logFile.Name(), nil
+}
+
+/*
+compareWithBaseline compares metrics of loadtest with the one of baseline, and returns nil when there is no baseline.
+
+Baseline run is pinned by baseline flag or baseline.runID in config.yaml, or the last green run is used.
+Only the baseline of which metrics are read from the same source with the same warm-up is compared.
+If baseline field is not set to service, loadtest is not compared.
+*/
+func compareWithBaseline(
+	source baselineSource,
+	runID string,
+	serviceConfig serviceConfig,
+	scenarioSpec cfg.ScenarioSpec,
+	metrics baseline.Metrics,
+) *baseline.Comparison {
+	if serviceConfig.baseline == nil {
+		return nil
+	}
+	pinnedRunID := source.pinnedRunID
+	if pinnedRunID == "" {
+		pinnedRunID = serviceConfig.baseline.RunID
+	}
+	baselineRunID, baselineScenario := runstate.FindBaseline(
+		source.runs, runID, pinnedRunID, serviceConfig.name, scenarioSpec.Name, scenarioSpec.SubName, metrics,
+	)
+	if baselineScenario == nil {
+		return nil
+	}
+	comparison := baseline.Compare(baselineRunID, *baselineScenario.Metrics, metrics, baseline.Thresholds{
+		P95IncreasePercent:           serviceConfig.baseline.P95IncreasePercent,
+		P99IncreasePercent:           serviceConfig.baseline.P99IncreasePercent,
+		FailedIncreasePoint:          serviceConfig.baseline.FailedIncreasePoint,
+		ThroughputDecreasePercent:    serviceConfig.baseline.ThroughputDecreasePercent,
+		CPUPerRequestIncreasePercent: serviceConfig.baseline.CPUPerRequestIncreasePercent,
+	})
+	return &comparison
+}
+
+/*
+runCapacitySearch run loadtests of service in capacity search mode, and returns the highest CONCURRENCY env value
+which meets the target.
+
+Each loadtest is generated from the only ScenarioSpec of service by rewriting CONCURRENCY env value, and its
+CONCURRENCY value is decided by capacity.Searcher from results of previous loadtests. Each loadtest result is written
+to spreadsheet as same as other loadtests, and the search result is recorded to run-state file as capacity.
+When run is resumed, service which capacity is already recorded is skipped, otherwise search is started again.
+*/
+func runCapacitySearch(
+	ctx context.Context,
+	config *cfg.Config,
+	statusWatcher *gatlingTools.StatusWatcher,
+	service cfg.Service,
+	imgURL, runID string,
+	force, followLogs bool,
+	recorder *runstate.Recorder,
+	baselineSource baselineSource,
+) (*capacity.Result, error) {
+	serviceConfig := extractServiceConfig(service)
+	search := *service.Search
+	if serviceState := recorder.Service(serviceConfig.name); serviceState.Capacity != nil {
+		fmt.Printf("service %v capacity search skipped, it was completed in resumed run\n", serviceConfig.name)
+		return serviceState.Capacity, nil
+	}
+	searcher, err := capacity.NewSearcher(
+		capacity.Strategy(search.Strategy), search.StartConcurrency, search.MaxConcurrency, search.Step,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init capacity searcher, %v", err)
+	}
+	for {
+		concurrency, ok := searcher.Next()
+		if !ok {
+			break
+		}
+		scenarioSpec := generateSearchScenarioSpec(service.ScenarioSpecs[0], concurrency)
+		scenarioState := recorder.Scenario(serviceConfig.name, scenarioSpec.Name, scenarioSpec.SubName)
+		fmt.Printf("service %v capacity search, start loadtest with concurrency %v\n", serviceConfig.name, concurrency)
+		result, err := runLoadtestAndRecord(
+			ctx,
+			config.GatlingContextName,
+			statusWatcher,
+			imgURL,
+			runID,
+			config.BaseManifest,
+			config.StartupTimeoutSec,
+			config.ExecTimeoutSec,
+			config.CloudStorage,
+			config.RunnerSaturation,
+			force,
+			followLogs,
+			serviceConfig,
+			service.TargetPodConfigs(),
+			scenarioSpec,
+			scenarioState,
+			baselineSource,
+		)
+		if err != nil {
+			err = wrapCancelled(ctx, err)
+			updateScenarioState(scenarioState, func(state *runstate.ScenarioState) {
+				recordLoadtestError(state, err)
+			})
+			// Aborted loadtest means the concurrency is over capacity, so continue search.
+			var abortedErr *gatlingTools.AbortedError
+			if errors.As(err, &abortedErr) {
+				reason := fmt.Sprintf("aborted, %v", abortedErr.Reason)
+				fmt.Printf(
+					"service %v capacity search, concurrency %v passed: false %v\n", serviceConfig.name, concurrency, reason,
+				)
+				searcher.Record(concurrency, false, reason)
+				continue
+			}
+			return nil, fmt.Errorf("failed to run loadtest with concurrency %v, %w", concurrency, err)
+		}
+		passed, reason, err := evaluateCapacityTrial(serviceConfig, search, *result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate loadtest with concurrency %v, %v", concurrency, err)
+		}
+		fmt.Printf(
+			"service %v capacity search, concurrency %v passed: %v %v\n", serviceConfig.name, concurrency, passed, reason,
+		)
+		searcher.Record(concurrency, passed, reason)
+	}
+	result := searcher.Result()
+	if err := recorder.SetCapacity(serviceConfig.name, result); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record run state, %v\n", err)
+	}
+	if result.Found {
+		fmt.Printf(
+			"service %v capacity is concurrency %v, %v loadtests executed\n",
+			serviceConfig.name,
+			result.Capacity,
+			len(result.Trials),
+		)
+	} else {
+		fmt.Printf(
+			"service %v capacity not found, start concurrency %v did not meet the target\n",
+			serviceConfig.name,
+			search.StartConcurrency,
+		)
+	}
+	return &result, nil
+}
+
+/*
+startGatlingStatusWatcher starts StatusWatcher of Gatling objects of run, which is shared by loadtests of all services.
+
+Gatling objects are created in namespace of base manifest, so they are watched in the namespace by run ID label.
+The watcher stops when ctx is done.
+*/
+func startGatlingStatusWatcher(
+	ctx context.Context,
+	config *cfg.Config,
+	runID string,
+) (*gatlingTools.StatusWatcher, error) {
+	baseGatling, err := gatlingTools.LoadGatlingManifest(config.BaseManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base manifest, %v", err)
+	}
+	cl, err := kubeapiTools.InitWatchClient(config.GatlingContextName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init k8s cluster client, %v", err)
+	}
+	statusWatcher := gatlingTools.NewStatusWatcher(
+		cl,
+		baseGatling.ObjectMeta.Namespace,
+		map[string]string{gatlingTools.LabelRunID: gatlingTools.SanitizeLabelValue(runID)},
+	)
+	statusWatcher.Start(ctx)
+	return statusWatcher, nil
+}
+
+// runnerPodConfig returns pod config to fetch runner container metrics of Gatling runner pods.
+func runnerPodConfig(k8sCtxName string, gatling *gatlingv1alpha1.Gatling) cfg.TargetPodConfig {
+	return cfg.TargetPodConfig{
+		Name:          gatlingTools.RunnerContainerName,
+		ContextName:   k8sCtxName,
+		Namespace:     gatling.ObjectMeta.Namespace,
+		LabelKey:      gatlingTools.RunnerJobNameLabelKey,
+		LabelValue:    gatlingTools.RunnerJobName(gatling),
+		ContainerName: gatlingTools.RunnerContainerName,
+	}
+}
+
+/*
+checkRunnerSaturation compares peak usage of each Gatling runner pod with podSpec.resources of Gatling object.
+
+Returns error when resources of runner container is not specified, because saturation can not be checked.
+When no sample was fetched, runner pods are regarded as not saturated.
+*/
+func checkRunnerSaturation(
+	series *kubeapiTools.MetricsSeries,
+	resources corev1.ResourceRequirements,
+	runnerSaturation cfg.RunnerSaturationConfig,
+) (*history.RunnerMetrics, error) {
+	limit, err := kubeapiTools.ContainerResourcesLimits(resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get podSpec resources of gatling, %v", err)
+	}
+	runnerMetrics := &history.RunnerMetrics{ResourcesLimit: *limit, Series: series}
+	if series == nil {
+		return runnerMetrics, nil
+	}
+	reasons := kubeapiTools.CheckSaturation(
+		*series, *limit, runnerSaturation.CPUPercent, runnerSaturation.MemoryPercent,
+	)
+	runnerMetrics.Saturated = len(reasons) > 0
+	runnerMetrics.SaturationReasons = reasons
+	return runnerMetrics, nil
+}
+
+// loadGeneratorSummary returns state of load generator written to loadtest report.
+func loadGeneratorSummary(runnerMetrics *history.RunnerMetrics) string {
+	if runnerMetrics == nil {
+		return "not checked"
+	}
+	if runnerMetrics.Saturated {
+		return fmt.Sprintf("load generator saturated, %v", strings.Join(runnerMetrics.SaturationReasons, "; "))
+	}
+	return "ok"
+}
+
+// printPodHealth prints health of target pods and Warning events of them observed during loadtest.
+func printPodHealth(serviceName, scenarioName, targetName string, health kubeapiTools.PodHealth) {
+	fmt.Printf("service %v loadtest %v, target %v %v\n", serviceName, scenarioName, targetName, health.Summary())
+	for _, e := range health.Events {
+		fmt.Printf(
+			"service %v loadtest %v, target %v pod %v event %v %v x%v, %v\n",
+			serviceName,
+			scenarioName,
+			targetName,
+			e.PodName,
+			e.Type,
+			e.Reason,
+			e.Count,
+			e.Message,
+		)
+	}
+}
+
+// collectUnhealthyTargets returns summaries of health of targets which pods were not healthy during loadtest.
+func collectUnhealthyTargets(targets []history.TargetMetrics) []string {
+	var unhealthyTargets []string
+	for _, target := range targets {
+		if target.Health != nil && !target.Health.Healthy() {
+			unhealthyTargets = append(unhealthyTargets, fmt.Sprintf("target %v %v", target.Name, target.Health.Summary()))
+		}
+	}
+	return unhealthyTargets
+}
+
+// targetHealthSummary returns health of target pods written to loadtest report.
+func targetHealthSummary(targets []history.TargetMetrics) string {
+	if unhealthyTargets := collectUnhealthyTargets(targets); len(unhealthyTargets) > 0 {
+		return strings.Join(unhealthyTargets, "; ")
+	}
+	for _, target := range targets {
+		if target.Health != nil {
+			return "healthy"
+		}
+	}
+	return "not checked"
+}
+
+// printPodUtilisation prints replica count and aggregate and per-pod usage of target container.
+func printPodUtilisation(serviceName, scenarioName, targetName string, series kubeapiTools.MetricsSeries) {
+	fmt.Printf(
+		"service %v loadtest %v, target %v replicas min %v max %v avg %v, "+
+			"aggregate cpu usage %.1f%%, memory usage %.1f%%\n",
+		serviceName,
+		scenarioName,
+		targetName,
+		series.Replicas.Min,
+		series.Replicas.Max,
+		series.Replicas.TimeWeightedMean,
+		series.CPUUsagePercent,
+		series.MemoryUsagePercent,
+	)
+	for _, pod := range series.Pods {
+		fmt.Printf(
+			"service %v loadtest %v, target %v pod %v cpu usage %.1f%%, memory usage %.1f%%\n",
+			serviceName,
+			scenarioName,
+			targetName,
+			pod.PodName,
+			pod.CPUUsagePercent,
+			pod.MemoryUsagePercent,
+		)
+	}
+}
+
+/*
+summarizeTargetMetrics returns container metrics of target and its usage ratio from series fetched during loadtest.
+
+Aggregate usage of series is used when it is available, not to depend on the limits of one pod when replicas changed.
+Otherwise mean usage is divided by resourcesLimit fetched before loadtest. series is nil when no sample was fetched.
+*/
+func summarizeTargetMetrics(
+	name string,
+	series *kubeapiTools.MetricsSeries,
+	resourcesLimit kubeapiTools.MetricsField,
+) (history.TargetMetrics, metricsUsageRatio) {
+	var usageMean kubeapiTools.MetricsField
+	if series != nil {
+		usageMean = series.Mean()
+	}
+	ratio := metricsUsageRatio{
+		cpu:    kubeapiTools.CalcAndRoundMetricsRatio(usageMean.Cpu, resourcesLimit.Cpu),
+		memory: kubeapiTools.CalcAndRoundMetricsRatio(usageMean.Memory, resourcesLimit.Memory),
+	}
+	if series != nil && len(series.Aggregates) > 0 {
+		ratio.cpu = series.CPUUsagePercent / 100
+		ratio.memory = series.MemoryUsagePercent / 100
+	}
+	return history.TargetMetrics{
+		Name: name,
+		ContainerMetrics: history.ContainerMetrics{
+			CPUUsageMean:       usageMean.Cpu,
+			MemoryUsageMean:    usageMean.Memory,
+			CPUUsagePercent:    ratio.cpu * 100,    // conv ratio to percentage
+			MemoryUsagePercent: ratio.memory * 100, // conv ratio to percentage
+			Series:             series,
+		},
+	}, ratio
+}
+
+/*
+newTargetMetricsSource returns source of cpu and memory usage of target container of target.
+
+Usage is fetched from metrics provider when targetUsage of metricsProvider is true, otherwise from metrics-server of
+the cluster of target. Resources limits are always read from the spec of target pods.
+*/
+func newTargetMetricsSource(
+	providerConfig *cfg.MetricsProviderConfig,
+	target abort.Target,
+) (kubeapiTools.MetricsSource, error) {
+	if providerConfig == nil || !providerConfig.TargetUsage {
+		metricsCl, err := kubeapiTools.InitMetricsClient(target.PodConfig.ContextName)
+		if err != nil {
+			return nil, err
+		}
+		return kubeapiTools.MetricsServerSource{Client: metricsCl}, nil
+	}
+	provider, err := metricsproviders.NewUsageProvider(metricsproviders.Options{
+		Type:              providerConfig.Type,
+		Address:           providerConfig.Address,
+		TargetCPUQuery:    providerConfig.TargetCPUQuery,
+		TargetMemoryQuery: providerConfig.TargetMemoryQuery,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return providerMetricsSource{provider: provider, podCl: target.PodCl}, nil
+}
+
+// targetMetricsSourceName returns name of source of target container usage, which is recorded with the metrics.
+func targetMetricsSourceName(providerConfig *cfg.MetricsProviderConfig) string {
+	if providerConfig == nil || !providerConfig.TargetUsage {
+		return "metrics-server"
+	}
+	return providerConfig.Type
+}
+
+/*
+providerMetricsSource implements kubeapiTools.MetricsSource with usage of target container from metrics provider.
+
+Metrics provider can not select pods by label selector, so running pods are listed from the cluster of target.
+*/
+type providerMetricsSource struct {
+	provider metricsproviders.UsageProvider
+	podCl    ctrlClient.Client
+}
+
+// FetchContainerMetrics returns usage of target container of running pods which match label selector of podConfig.
+func (s providerMetricsSource) FetchContainerMetrics(
+	ctx context.Context,
+	podConfig cfg.TargetPodConfig,
+) ([]kubeapiTools.MetricsSample, error) {
+	podNames, err := kubeapiTools.FetchRunningPodNames(ctx, s.podCl, podConfig)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := s.provider.ContainerUsage(ctx, podConfig.Namespace, podConfig.ContainerName, podNames)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]kubeapiTools.MetricsSample, 0, len(usages))
+	for _, usage := range usages {
+		samples = append(samples, kubeapiTools.MetricsSample{
+			PodName:   usage.PodName,
+			Timestamp: usage.Timestamp,
+			Cpu:       int64(math.Round(usage.Cpu)),
+			Memory:    int64(math.Round(usage.Memory)),
+		})
+	}
+	return samples, nil
+}
+
+/*
+collectProviderMetrics returns results of metrics provider queries of service from start to end.
+
+Metrics provider is auxiliary source of metrics, so failure is only logged and does not fail loadtest.
+Returns nil when metricsProvider is not configured or has no query.
+*/
+func collectProviderMetrics(
+	ctx context.Context,
+	serviceConfig serviceConfig,
+	scenarioName string,
+	start, end time.Time,
+) []metricsproviders.QueryResult {
+	providerConfig := serviceConfig.metricsProvider
+	if providerConfig == nil || len(providerConfig.Queries) == 0 {
+		return nil
+	}
+	queries := make([]metricsproviders.Query, 0, len(providerConfig.Queries))
+	for _, query := range providerConfig.Queries {
+		queries = append(queries, metricsproviders.Query{Name: query.Name, Query: query.Query})
+	}
+	provider, err := metricsproviders.NewProvider(metricsproviders.Options{
+		Type:    providerConfig.Type,
+		Address: providerConfig.Address,
+		Step:    time.Duration(providerConfig.StepSec) * time.Second,
+		Queries: queries,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to init metrics provider, %v\n", err)
+		return nil
+	}
+	results, err := provider.Collect(ctx, start, end)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to collect metrics provider metrics, %v\n", err)
+		return nil
+	}
+	for _, result := range results {
+		if result.Error != "" {
 			fmt.Fprintf(
 				os.Stderr,
-				"Error: failed to run loadtest service %v scenario %v, error %v\n",
-				result.serviceName,
-				result.scenarioName,
-				result.err,
+				"Error: service %v loadtest %v, metrics query %v failed, %v\n",
+				serviceConfig.name,
+				scenarioName,
+				result.Name,
+				result.Error,
 			)
+			continue
 		}
-		return fmt.Errorf("more than one loadtest scenario failed")
+		fmt.Printf(
+			"service %v loadtest %v, metrics query %v returned %v series\n",
+			serviceConfig.name,
+			scenarioName,
+			result.Name,
+			len(result.Series),
+		)
 	}
-	return nil
+	return results
+}
+
+// hasMetricsSeries returns whether one of targets has metrics series.
+func hasMetricsSeries(targets []history.TargetMetrics) bool {
+	for _, target := range targets {
+		if target.Series != nil {
+			return true
+		}
+	}
+	return false
 }
 
 /*
-runLoadtestAndRecord is main logic in exec command.
+formatResourceStats returns statistics of samples of a resource for logging.
 
-runLoadtestAndRecord Create gatling object and run loadtest, fetch loadtest target container metrics.
-Wait loadtest running and get gatling report, write report to spreadsheet.
+ex: "min 100m p50 150m p95 200m p99 240m max 250m (peak at 2023-11-13T10:50:00Z in sample-pod)"
 */
-func runLoadtestAndRecord(
+func formatResourceStats(stats kubeapiTools.ResourceStats, unit string) string {
+	return fmt.Sprintf(
+		"min %v%v p50 %v%v p95 %v%v p99 %v%v max %v%v (peak at %v in %v)",
+		stats.Min, unit,
+		stats.P50, unit,
+		stats.P95, unit,
+		stats.P99, unit,
+		stats.Max, unit,
+		stats.PeakAt.Format(time.RFC3339), stats.PeakPodName,
+	)
+}
+
+/*
+newAbortChecker returns checker of abortRules of service, returns nil when abortRules is not configured.
+
+Kubernetes clientset for reading runner pod logs is initialized only when maxRunnerErrors rule is configured.
+*/
+func newAbortChecker(
 	ctx context.Context,
 	k8sCtxName string,
-	imgURL, manifestPath string,
-	waitStartupTimeout int32,
-	waitExecTimeout int32,
 	serviceConfig serviceConfig,
-	targetPodConfig cfg.TargetPodConfig,
-	scenarioSpec cfg.ScenarioSpec,
-) (*gatlingTools.GatlingReport, error) {
-	scenarioName := scenarioSpec.Name
-	serviceName := serviceConfig.name
-
-	fmt.Printf("Start service %v loadtest %v\n", serviceName, scenarioName)
-
-	gatling, err := loadAndPatchBaseGatling(serviceName, imgURL, scenarioSpec, manifestPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to patch gatling struct field, %v", err)
+	targets []abort.Target,
+	gatling *gatlingv1alpha1.Gatling,
+) (gatlingTools.AbortChecker, error) {
+	rules := serviceConfig.abortRules
+	if rules == nil {
+		return nil, nil
 	}
-
-	k8sTargetPodClint, err := kubeapiTools.InitClient(targetPodConfig.ContextName)
-	fmt.Printf("service %v loadtest %v, k8s target pod client initialized\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init target pod k8s cluster client, %v", err)
+	var runnerCs kubernetes.Interface
+	if rules.MaxRunnerErrors != nil {
+		cs, err := kubeapiTools.InitClientset(k8sCtxName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init k8s clientset for runner logs, %v", err)
+		}
+		runnerCs = cs
 	}
-
-	// Fetch resources limit value before run loadtest.
-	containerResourcesLimit, err := kubeapiTools.FetchContainerResourcesLimit(ctx, k8sTargetPodClint, targetPodConfig)
-	fmt.Printf("service %v loadtest %v, target pod resources limit fetched\n", serviceName, scenarioName)
+	monitor, err := abort.NewMonitor(ctx, *rules, targets, runnerCs, gatling)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pod spec resources %v", err)
+		return nil, err
 	}
+	return monitor, nil
+}
 
-	k8sGatlingClient, err := kubeapiTools.InitClient(k8sCtxName)
-	fmt.Printf("service %v loadtest %v, k8s gatling client initialized\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init k8s cluster client, %v", err)
-	}
+/*
+recordLoadtestError records error of loadtest to scenario state.
 
-	err = gatlingTools.CreateGatling(ctx, k8sGatlingClient, gatling)
-	fmt.Printf("service %v loadtest %v, Gatling Object created\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gatling object, %v", err)
+When the loadtest was aborted by abort rules, the scenario is recorded as aborted with the reason.
+When the loadtest was cancelled, the scenario is recorded as cancelled.
+Outcome of Gatling Job is recorded when err is classified, such as runner-failed and timed-out.
+*/
+func recordLoadtestError(state *runstate.ScenarioState, err error) {
+	state.Status = runstate.StatusFailed
+	state.Error = err.Error()
+	if outcome := gatlingTools.OutcomeOf(err); outcome != "" {
+		state.Outcome = string(outcome)
 	}
-
-	err = gatlingTools.WaitGatlingJobStartup(ctx, k8sGatlingClient, gatling, waitStartupTimeout)
-	fmt.Printf("service %v loadtest %v, Gatling Job Started\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait gatling job start, %v", err)
+	var abortedErr *gatlingTools.AbortedError
+	if errors.As(err, &abortedErr) {
+		state.Status = runstate.StatusAborted
+		state.AbortReason = abortedErr.Reason
 	}
-
-	metricsCl, err := kubeapiTools.InitMetricsClient(targetPodConfig.ContextName)
-	fmt.Printf("service %v loadtest %v, k8s target pod metrics client initialized\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init k8s client for fetch metrics")
+	if errors.Is(err, context.Canceled) {
+		state.Status = runstate.StatusCancelled
+		state.Outcome = string(gatlingTools.OutcomeCancelled)
 	}
+}
 
-	wg := new(sync.WaitGroup)
-	wg.Add(1)
-	informJobFinishCh := make(chan bool, 1)
-	metricsUsageCh := make(chan kubeapiTools.MetricsField, 1)
-	// Fetch target container metrics in background during loadtest running.
-	go kubeapiTools.FetchContainerMetricsMean(ctx, wg, metricsCl, metricsUsageCh, informJobFinishCh, targetPodConfig)
+/*
+wrapCancelled returns err which matches context.Canceled with errors.Is when ctx is cancelled.
 
-	// Wait until Gatling job completed.
-	fmt.Printf("service %v loadtest %v, waiting Gatling Job Running\n", serviceName, scenarioName)
-	err = gatlingTools.WaitGatlingJobRunning(ctx, k8sGatlingClient, gatling, waitExecTimeout, informJobFinishCh)
-	fmt.Printf("service %v loadtest %v, waiting Gatling Job completed\n", serviceName, scenarioName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait gatling job running, %v", err)
+Error of cancelled loadtest is not always wrapping the context error, such as failure of request with cancelled
+context, so it is wrapped to be handled as cancelled.
+*/
+func wrapCancelled(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil || errors.Is(err, ctx.Err()) {
+		return err
 	}
-	close(informJobFinishCh)
+	return fmt.Errorf("%v, %w", err, ctx.Err())
+}
 
-	wg.Wait() // Wait FetchContainerMetricsMean execution finish.
-	close(metricsUsageCh)
-	metricsUsageMean, ok := <-metricsUsageCh
+/*
+handleSignals cancels run by the first signal received from signalCh, and calls exit by the second one.
+
+Cancelled run deletes running Gatling objects and records cancelled loadtests before exit, and the second signal is
+for the case the cleanup hangs. Exit code is 128 + signal number as same as shells. Return when signalCh is closed.
+*/
+func handleSignals(signalCh <-chan os.Signal, cancel context.CancelFunc, exit func(int)) {
+	sig, ok := <-signalCh
+	if !ok {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "received %v, cancel loadtests and cleanup Gatling objects, send again to force exit\n", sig)
+	cancel()
+	sig, ok = <-signalCh
 	if !ok {
-		fmt.Fprintf(os.Stderr, "metricsUsageCh value is empty, so each metricsUsage field value is 0")
+		return
+	}
+	fmt.Fprintf(os.Stderr, "received %v again, force exit\n", sig)
+	exitCode := 1
+	if sysSig, ok := sig.(syscall.Signal); ok {
+		exitCode = 128 + int(sysSig)
 	}
+	exit(exitCode)
+}
+
+/*
+generateSearchScenarioSpec returns ScenarioSpec which CONCURRENCY env value is replaced with concurrency.
 
-	// Calculate ratio from usage mean and resource limit.
-	metricsUsageRatio := metricsUsageRatio{
-		cpu:    kubeapiTools.CalcAndRoundMetricsRatio(metricsUsageMean.Cpu, containerResourcesLimit.Cpu),
-		memory: kubeapiTools.CalcAndRoundMetricsRatio(metricsUsageMean.Memory, containerResourcesLimit.Memory),
+SubName is generated from concurrency, so that each loadtest in capacity search has different Gatling object name.
+*/
+func generateSearchScenarioSpec(template cfg.ScenarioSpec, concurrency int) cfg.ScenarioSpec {
+	scenarioSpec := template
+	scenarioSpec.TestScenarioSpec = *template.TestScenarioSpec.DeepCopy()
+	for i, env := range scenarioSpec.TestScenarioSpec.Env {
+		if env.Name == "CONCURRENCY" {
+			scenarioSpec.TestScenarioSpec.Env[i].Value = strconv.Itoa(concurrency)
+		}
+	}
+	scenarioSpec.SubName = fmt.Sprintf("c%v", concurrency)
+	if template.SubName != "" {
+		scenarioSpec.SubName = fmt.Sprintf("%v-c%v", template.SubName, concurrency)
 	}
+	return scenarioSpec
+}
 
-	storageOp, err := cloudstorages.NewGoogleCloudStorageOperator(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init cloud storage operator client, %v", err)
+/*
+evaluateCapacityTrial returns whether loadtest result meets the target in capacity search, and reason when it doesn't.
+
+Check items are below.
+  - gatlingReport failed percentage is less than or equal to errorBudgetPercent
+  - targetPercentile latency is less than or equal to targetLatency, when they are specified
+  - target container cpu usage is less than or equal to cpuCeilingPercent, when it is specified
+  - no SLO assertion of stop or fail-run severity is violated
+*/
+func evaluateCapacityTrial(
+	serviceConfig serviceConfig,
+	search cfg.SearchConfig,
+	result loadtestResult,
+) (bool, string, error) {
+	var reasons []string
+	if failed := result.gatlingReport.Failed.Percentage; failed > search.ErrorBudgetPercent {
+		reasons = append(reasons, fmt.Sprintf(
+			"failed percentage exceeds error budget, budget: %v, result: %v", search.ErrorBudgetPercent, failed,
+		))
+	}
+	if serviceConfig.targetLatency != 0 && serviceConfig.targetPercentile != 0 {
+		resultLatency, err := result.gatlingReport.GetPercentileLatency(serviceConfig.targetPercentile)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to get specified percentile latency %v", err)
+		}
+		if resultLatency > serviceConfig.targetLatency {
+			reasons = append(reasons, fmt.Sprintf(
+				"latency exceeds target, target: %v, result: %v", serviceConfig.targetLatency, resultLatency,
+			))
+		}
+	}
+	cpuUsagePercent := result.metricsUsageRatio.cpu * 100 // conv ratio to percentage
+	if search.CPUCeilingPercent != 0 && cpuUsagePercent > search.CPUCeilingPercent {
+		reasons = append(reasons, fmt.Sprintf(
+			"cpu usage exceeds ceiling, ceiling: %v, result: %v", search.CPUCeilingPercent, cpuUsagePercent,
+		))
 	}
+	if result.slo.Failed() {
+		reasons = append(reasons, result.slo.Summary())
+	}
+	return len(reasons) == 0, strings.Join(reasons, ", "), nil
+}
 
-	// Fetch storage path from Gatling object and fetch gatlingReport from storage. And parse jsonBytes of gatlingReport
-	// to GatlingReport object.
-	gatlingReport, err := loadGatlingReportFromCloudStorage(ctx, storageOp, k8sGatlingClient, gatling)
+/*
+initRunStateRecorder returns runstate.Recorder which writes run-state file.
+
+When run is resumed, loaded state is written back to the run-state file specified by resume flag.
+Otherwise new run-state file is created in run directory. Loadtests written in config.yaml are added to the state,
+so loadtests added after interrupted run are executed too.
+*/
+func initRunStateRecorder(
+	config *cfg.Config,
+	resumePath string,
+	resumedState *runstate.RunState,
+	runID, imgURL string,
+	execTime time.Time,
+) (*runstate.Recorder, error) {
+	statePath := filepath.Join(config.RunDir(runID), runstate.FileName)
+	state := &runstate.RunState{
+		RunID:     runID,
+		ImageURL:  imgURL,
+		StartedAt: execTime,
+	}
+	if resumedState != nil {
+		statePath = resumePath
+		state = resumedState
+	}
+	recorder, err := runstate.NewRecorder(statePath, state)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load gatling report from cloud storage, %v", err)
+		return nil, err
+	}
+	for _, service := range config.Services {
+		// Loadtests of capacity search are recorded when they are generated.
+		if service.Search != nil {
+			continue
+		}
+		for _, scenarioSpec := range service.ScenarioSpecs {
+			if err := recorder.AddScenario(service.Name, scenarioSpec.Name, scenarioSpec.SubName); err != nil {
+				return nil, err
+			}
+		}
 	}
+	return recorder, nil
+}
 
-	// Write loadtest report to spreadsheet.
-	fmt.Printf("service %v loadtest %v, start to write Gatling Report to Spreadsheets\n", serviceName, scenarioName)
-	err = writeReportToSpreadsheets(ctx, imgURL, serviceConfig, scenarioSpec, gatlingReport, metricsUsageRatio)
-	if err != nil {
-		return nil, fmt.Errorf("failed to write gatling report to spreadsheets, %v", err)
+// redactConfig returns copy of config without secret values, it is used for config snapshot in run directory.
+func redactConfig(config cfg.Config) cfg.Config {
+	if config.SlackConfig.WebhookURL != "" {
+		config.SlackConfig.WebhookURL = "REDACTED"
 	}
+	return config
+}
 
-	fmt.Printf("service %v loadtest %v succeeded\n", serviceName, scenarioName)
-	return gatlingReport, nil
+// updateScenarioState record scenario state, failure of recording is only logged not to stop loadtest.
+func updateScenarioState(scenarioState *runstate.ScenarioRecorder, update func(state *runstate.ScenarioState)) {
+	if err := scenarioState.Update(update); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record run state, %v\n", err)
+	}
+}
+
+/*
+createOrAttachGatling create gatling object, or attach to the one created in resumed run.
+
+When scenario was running in resumed run and its gatling object still exists without error, the object is used
+instead of creating new one, and returns true. Gatling object of resumed run which can not be attached is deleted
+and created again.
+*/
+func createOrAttachGatling(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	gatling *gatlingv1alpha1.Gatling,
+	force bool,
+	previous runstate.ScenarioState,
+) (bool, error) {
+	if previous.GatlingName == gatling.ObjectMeta.Name {
+		foundGatling, err := gatlingTools.FindGatling(
+			ctx, cl, gatling.ObjectMeta.Name, gatling.ObjectMeta.Namespace,
+		)
+		if err != nil {
+			return false, err
+		}
+		if foundGatling != nil {
+			if previous.Status == runstate.StatusRunning && foundGatling.Status.Error == "" {
+				// status of attached object is waited, not the one of object which had the same name before.
+				gatling.ObjectMeta.UID = foundGatling.ObjectMeta.UID
+				return true, nil
+			}
+			fmt.Printf("delete gatling object %v of resumed run to create it again\n", foundGatling.ObjectMeta.Name)
+			if err := gatlingTools.DeleteGatling(ctx, cl, foundGatling); err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := gatlingTools.CreateGatling(ctx, cl, gatling, force); err != nil {
+		return false, err
+	}
+	return false, nil
 }
 
-// runNotify check config.yaml webhookURL parameter and notify loadtest finished to slack.
-func runNotify(slackConfig cfg.SlackConfig, isSuccess bool) error {
+// runNotify check config.yaml webhookURL parameter and notify loadtest finished to slack with details of loadtests.
+func runNotify(slackConfig cfg.SlackConfig, isSuccess bool, details []string) error {
 	webhookURL := slackConfig.WebhookURL
 	mention := slackConfig.MentionText
 
@@ -383,7 +1876,7 @@ func runNotify(slackConfig cfg.SlackConfig, isSuccess bool) error {
 	}
 
 	slackOp := slackTools.NewSlackOperator(webhookURL)
-	data := slackTools.GenerateSlackPayloadData(mention, isSuccess)
+	data := slackTools.GenerateSlackPayloadData(mention, isSuccess, details)
 	if err := notifyLoadtestResult(slackOp, data); err != nil {
 		return err
 	}
@@ -430,10 +1923,13 @@ func buildPushImage(imgRepo string, imgTag string, gatlingDockerfileDir string)
 /*
 loadAndPatchBaseGatling load k8s gatling manifest to gatling object and set config.yaml value to replace target field
 in base_manifest.yaml.
+
+Gatling object name is generated from service, scenario and runID, and labels to identify them are added.
 */
 func loadAndPatchBaseGatling(
 	serviceName string,
 	imgURL string,
+	runID string,
 	scenarioSpec cfg.ScenarioSpec,
 	baseManifest string,
 ) (*gatlingv1alpha1.Gatling, error) {
@@ -441,12 +1937,87 @@ func loadAndPatchBaseGatling(
 	if err != nil {
 		return nil, err
 	}
-	gatling.ObjectMeta.Name = serviceName
+	gatling.ObjectMeta.Name = gatlingTools.GenerateGatlingName(
+		serviceName, scenarioSpec.Name, scenarioSpec.SubName, runID,
+	)
+	if gatling.ObjectMeta.Labels == nil {
+		gatling.ObjectMeta.Labels = make(map[string]string)
+	}
+	for key, value := range gatlingTools.GenerateGatlingLabels(
+		serviceName, scenarioSpec.Name, scenarioSpec.SubName, runID,
+	) {
+		gatling.ObjectMeta.Labels[key] = value
+	}
 	gatling.Spec.PodSpec.GatlingImage = imgURL
 	gatling.Spec.TestScenarioSpec = scenarioSpec.TestScenarioSpec
 	return gatling, nil
 }
 
+/*
+renderGatlingManifests write Gatling manifests of all loadtests written in config.yaml as multi-document YAML stream.
+
+Each manifest is generated by loadAndPatchBaseGatling, so it is exactly same as the object exec command creates.
+For service in capacity search mode, only the manifest of the first loadtest is rendered.
+*/
+func renderGatlingManifests(w io.Writer, config *cfg.Config, imgURL, runID string) error {
+	for _, service := range config.Services {
+		scenarioSpecs := service.ScenarioSpecs
+		// Following loadtests of capacity search depend on results, so only the first one is rendered.
+		if service.Search != nil {
+			scenarioSpecs = []cfg.ScenarioSpec{
+				generateSearchScenarioSpec(service.ScenarioSpecs[0], service.Search.StartConcurrency),
+			}
+		}
+		for _, scenarioSpec := range scenarioSpecs {
+			gatling, err := loadAndPatchBaseGatling(service.Name, imgURL, runID, scenarioSpec, config.BaseManifest)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to patch gatling struct field, service %v scenario %v %v, %v",
+					service.Name,
+					scenarioSpec.Name,
+					scenarioSpec.SubName,
+					err,
+				)
+			}
+			manifest, err := gatlingTools.MarshalGatlingManifest(gatling)
+			if err != nil {
+				return fmt.Errorf("failed to marshal gatling manifest, %v", err)
+			}
+			fmt.Fprintf(w, "---\n# service: %v, scenario: %v %v\n", service.Name, scenarioSpec.Name, scenarioSpec.SubName)
+			if _, err := w.Write(manifest); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/*
+cloudStorageOptions returns options to init cloud storage operator.
+
+Provider and region are taken from cloudStorageSpec of Gatling object, and storage account of Azure Blob Storage
+from AZUREBLOB_ACCOUNT env of it as gatling-operator does. cloudStorage field of config overrides them.
+*/
+func cloudStorageOptions(config cfg.CloudStorageConfig, gatling *gatlingv1alpha1.Gatling) cloudstorages.Options {
+	spec := gatling.Spec.CloudStorageSpec
+	opts := cloudstorages.Options{
+		Provider:       spec.Provider,
+		Region:         spec.Region,
+		S3Endpoint:     config.S3Endpoint,
+		S3UsePathStyle: config.S3UsePathStyle,
+		AzureAccount:   config.AzureAccount,
+		AzureEndpoint:  config.AzureEndpoint,
+	}
+	if opts.AzureAccount == "" {
+		for _, env := range spec.Env {
+			if env.Name == "AZUREBLOB_ACCOUNT" {
+				opts.AzureAccount = env.Value
+			}
+		}
+	}
+	return opts
+}
+
 /*
 loadGatlingReportFromCloudStorage fetch gatling report and parse to gatling report object.
 
@@ -475,11 +2046,181 @@ func loadGatlingReportFromCloudStorage(
 	return gatlingReport, nil
 }
 
+/*
+loadReportPercentilesFromCloudStorage fetch index.html of gatling report and parse percentiles of the report.
+
+reportStoragePath is path of gatling report folder.
+*/
+func loadReportPercentilesFromCloudStorage(
+	ctx context.Context,
+	op cloudStorageOperator,
+	reportStoragePath string,
+) ([]float64, error) {
+	fetchedHTMLBytes, err := op.Fetch(ctx, reportStoragePath+"/index.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index.html, %w", err)
+	}
+	percentiles, err := gatlingTools.ParseReportPercentiles(fetchedHTMLBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse percentiles of gatling report, %w", err)
+	}
+	return percentiles, nil
+}
+
+/*
+loadGatlingStatsFromCloudStorage fetch stats of each group and request of gatling report and parse to GatlingStats.
+
+reportStoragePath is path of gatling report folder, which "/js/stats.json" is fetched from.
+*/
+func loadGatlingStatsFromCloudStorage(
+	ctx context.Context,
+	op cloudStorageOperator,
+	reportStoragePath string,
+) (*gatlingTools.GatlingStats, error) {
+	fetchedStatsBytes, err := op.Fetch(ctx, reportStoragePath+"/js/stats.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stats, %w", err)
+	}
+	gatlingStats, err := gatlingTools.BytesToGatlingStats(fetchedStatsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gatling stats, %w", err)
+	}
+	return gatlingStats, nil
+}
+
+// printRequestStats prints count, failed percentage and latency of each group and request in gatlingStats.
+func printRequestStats(serviceName, scenarioName string, gatlingStats *gatlingTools.GatlingStats) {
+	for _, node := range gatlingStats.Flatten() {
+		fmt.Printf(
+			"service %v loadtest %v, %v %v count %v, failed %v%%, mean %vms, p95 %vms, p99 %vms\n",
+			serviceName,
+			scenarioName,
+			strings.ToLower(node.Type),
+			node.Path,
+			node.Stats.NumberOfRequests.Total,
+			node.Stats.Failed.Percentage,
+			node.Stats.MeanResponseTime.Ok,
+			node.Stats.NintyFifthPercentiles.Ok,
+			node.Stats.NintyNinthPercentiles.Ok,
+		)
+	}
+}
+
+/*
+loadSimulationLogFromCloudStorage parse simulation.log of each Gatling runner in report storage path.
+
+gatling-operator uploads simulation.log of each runner pod as "<hostname>.log" to report storage path, and they
+are read as stream one by one not to load huge logs on memory. Latency of percentiles is calculated exactly for
+summary in addition to the fixed ones.
+*/
+func loadSimulationLogFromCloudStorage(
+	ctx context.Context,
+	op simulationLogOperator,
+	reportStoragePath string,
+	warmUpSec int32,
+	percentiles ...float64,
+) (*gatlingTools.SimulationLogStats, error) {
+	names, err := op.List(ctx, reportStoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list simulation.log, %w", err)
+	}
+	parser := gatlingTools.NewSimulationLogParser(warmUpSec, percentiles...)
+	var parsed int
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		if err := parseSimulationLog(ctx, op, reportStoragePath+"/"+name, parser); err != nil {
+			return nil, err
+		}
+		parsed++
+	}
+	if parsed == 0 {
+		return nil, fmt.Errorf("simulation.log is not found in %v", reportStoragePath)
+	}
+	return parser.Stats(), nil
+}
+
+// parseSimulationLog opens simulation.log of path and adds its requests to parser.
+func parseSimulationLog(
+	ctx context.Context,
+	op simulationLogOperator,
+	path string,
+	parser *gatlingTools.SimulationLogParser,
+) error {
+	rc, err := op.Open(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to open simulation.log %v, %w", path, err)
+	}
+	defer rc.Close()
+	if err := parser.Parse(rc); err != nil {
+		return fmt.Errorf("failed to parse simulation.log %v, %w", path, err)
+	}
+	return nil
+}
+
+/*
+printSimulationLogStats prints summary out of warm-up window and peak of per-second series parsed from simulation.log.
+
+Peak shows behaviour over time which is hidden in summary, such as latency spike by GC pause.
+*/
+func printSimulationLogStats(serviceName, scenarioName string, stats *gatlingTools.SimulationLogStats) {
+	summary := stats.Summary
+	fmt.Printf(
+		"service %v loadtest %v, simulation.log excluding warm-up %vs, requests %v, throughput %.1f req/s, "+
+			"failed %.2f%%, p95 %vms, p99 %vms\n",
+		serviceName,
+		scenarioName,
+		stats.WarmUpSec,
+		summary.Requests,
+		summary.Throughput,
+		summary.FailedPercentage,
+		summary.P95Latency,
+		summary.P99Latency,
+	)
+	if stats.MalformedRecords > 0 {
+		fmt.Fprintf(
+			os.Stderr,
+			"service %v loadtest %v, %v records of simulation.log can not be parsed\n",
+			serviceName,
+			scenarioName,
+			stats.MalformedRecords,
+		)
+	}
+	if len(stats.Series) == 0 {
+		return
+	}
+	peakRPS, peakP99, peakErrorRate := stats.Series[0], stats.Series[0], stats.Series[0]
+	for _, point := range stats.Series {
+		if point.Requests > peakRPS.Requests {
+			peakRPS = point
+		}
+		if point.P99Latency > peakP99.P99Latency {
+			peakP99 = point
+		}
+		if point.ErrorRate > peakErrorRate.ErrorRate {
+			peakErrorRate = point
+		}
+	}
+	fmt.Printf(
+		"service %v loadtest %v, simulation.log peak rps %v at %v, p99 %vms at %v, error rate %.2f%% at %v\n",
+		serviceName,
+		scenarioName,
+		peakRPS.Requests,
+		peakRPS.Timestamp.Format(time.RFC3339),
+		peakP99.P99Latency,
+		peakP99.Timestamp.Format(time.RFC3339),
+		peakErrorRate.ErrorRate,
+		peakErrorRate.Timestamp.Format(time.RFC3339),
+	)
+}
+
 /*
 writeReportToSpreadsheets write loadtest report to spreadsheet.
 
 Set column header name and add each row which has loadtest report value.
 The sheet create by date by service. Set column header is called only once when sheet created.
+Returns title of the sheet which report is written to.
 */
 func writeReportToSpreadsheets(
 	ctx context.Context,
@@ -487,29 +2228,32 @@ func writeReportToSpreadsheets(
 	serviceConfig serviceConfig,
 	scenarioSpec cfg.ScenarioSpec,
 	gatlingReport *gatlingTools.GatlingReport,
+	gatlingStats *gatlingTools.GatlingStats,
 	mRatio metricsUsageRatio,
-) error {
+	comparison *baseline.Comparison,
+	loadGenerator, targetHealth string,
+) (string, error) {
 	targetLatency := serviceConfig.targetLatency
 	targetPercentile := serviceConfig.targetPercentile
 	serviceName := serviceConfig.name
 
 	op, err := sheetTools.NewSpreadsheetOperator(ctx, serviceConfig.spreadsheetId)
 	if err != nil {
-		return fmt.Errorf("failed to init spreadsheet operator, %w", err)
+		return "", fmt.Errorf("failed to init spreadsheet operator, %w", err)
 	}
 	sheetTitle := fmt.Sprintf("%v-%v", scenarioSpec.Name, time.Now().Format("20060102"))
 	targetSheet, err := op.FindSheet(sheetTitle)
 	if err != nil && !errors.Is(err, &sheetTools.SheetNotFoundError{}) {
-		return fmt.Errorf("unexpected error occured when FindSheet, %w", err)
+		return "", fmt.Errorf("unexpected error occured when FindSheet, %w", err)
 	}
 	if errors.Is(err, &sheetTools.SheetNotFoundError{}) {
 		targetSheet, err = op.AddSheet(sheetTitle)
 		if err != nil {
-			return fmt.Errorf("failed to create new sheet, %w", err)
+			return "", fmt.Errorf("failed to create new sheet, %w", err)
 		}
-		targetSheet, err = op.SetColumnHeader(targetSheet)
+		targetSheet, err = op.SetColumnHeader(targetSheet, reportColumnLabels(gatlingReport))
 		if err != nil {
-			return fmt.Errorf("failed to set cell name, %w", err)
+			return "", fmt.Errorf("failed to set cell name, %w", err)
 		}
 	}
 	var targetLatencyFieldValue string
@@ -521,14 +2265,14 @@ func writeReportToSpreadsheets(
 	commonSettingValue := sheetTools.NewLoadtestCommonSettingRow(imageURL, serviceName, targetLatencyFieldValue)
 	targetSheet, err = op.SetLoadtestCommonSettingValue(commonSettingValue, targetSheet)
 	if err != nil {
-		return fmt.Errorf("failed to set loadtest common setting value %w", err)
+		return "", fmt.Errorf("failed to set loadtest common setting value %w", err)
 	}
 
 	concurrency, duration, condition, err := gatlingTools.ExtractLoadtestConditionToReport(
 		scenarioSpec.TestScenarioSpec,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to parse loadtest condition %w", err)
+		return "", fmt.Errorf("failed to parse loadtest condition %w", err)
 	}
 
 	row := sheetTools.NewLoadtestReportRow(
@@ -548,12 +2292,60 @@ func writeReportToSpreadsheets(
 		gatlingReport.OverOneThousandTwoHundredMilliSec.Percentage,
 		mRatio.cpu*100,    // conv ratio to percentage
 		mRatio.memory*100, // conv ratio to percentage
+		comparison.Summary(),
+		loadGenerator,
+		targetHealth,
 	)
 	_, err = op.AppendLoadtestReportRow(row, targetSheet)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	// Each group and request follows the row of whole loadtest, metrics of target container are not per request.
+	for _, node := range gatlingStats.Flatten() {
+		requestRow := sheetTools.NewLoadtestReportRow(
+			fmt.Sprintf("%v %v %v", scenarioSpec.SubName, strings.ToLower(node.Type), node.Path),
+			condition,
+			duration,
+			concurrency,
+			node.Stats.MaxResponseTime.Ok,
+			node.Stats.MeanResponseTime.Ok,
+			node.Stats.FiftiethPercentiles.Ok,
+			node.Stats.SeventyFifthPercentiles.Ok,
+			node.Stats.NintyFifthPercentiles.Ok,
+			node.Stats.NintyNinthPercentiles.Ok,
+			node.Stats.Failed.Percentage,
+			node.Stats.UnderEightHundredMilliSec.Percentage,
+			node.Stats.BetweenFromEightHundredToOneThousandTwoHundredMilliSec.Percentage,
+			node.Stats.OverOneThousandTwoHundredMilliSec.Percentage,
+			0,
+			0,
+			"",
+			"",
+			"",
+		)
+		if _, err := op.AppendLoadtestReportRow(requestRow, targetSheet); err != nil {
+			return "", err
+		}
+	}
+	return sheetTitle, nil
+}
+
+/*
+reportColumnLabels returns labels of spreadsheet columns from percentiles and response time groups of gatlingReport.
+
+Names of response time groups have thresholds of gatling.conf, such as "t < 800 ms".
+*/
+func reportColumnLabels(gatlingReport *gatlingTools.GatlingReport) sheetTools.ColumnLabels {
+	var labels sheetTools.ColumnLabels
+	for i, percentile := range gatlingReport.ReportPercentiles() {
+		labels.Percentiles[i] = gatlingTools.FormatPercentile(percentile)
+	}
+	labels.ResponseTimeGroups = [3]string{
+		gatlingReport.UnderEightHundredMilliSec.Name,
+		gatlingReport.BetweenFromEightHundredToOneThousandTwoHundredMilliSec.Name,
+		gatlingReport.OverOneThousandTwoHundredMilliSec.Name,
+	}
+	return labels
 }
 
 // notifyLoadtestResult call notifyOperator Notify method.
@@ -574,15 +2366,72 @@ Some cases of shouldContinue is false are below.
 - config.yaml parameter failFast is true and gatlingReport.Failed.Percentage is more than 0
 - config.yaml parameter targetLatency and targetPercentile specified,
 and gatlingReport target Percentile latency value is more than targetLatency.
+- same as above for each request of config.yaml parameter requests, which is checked with gatlingStats.
+- SLO assertion of stop severity is violated.
+- loadtest result regressed from baseline.
 */
 func checkContinueToExec(
 	serviceConfig serviceConfig,
 	gatlingReport gatlingTools.GatlingReport,
+	gatlingStats *gatlingTools.GatlingStats,
+	comparison *baseline.Comparison,
+	sloResult *slo.Result,
 ) (*checkContinueToExecResult, error) {
-	failFast := serviceConfig.failFast
-	targetLatency := serviceConfig.targetLatency
-	targetPercentile := serviceConfig.targetPercentile
+	checkResult, err := checkReportTarget(
+		gatlingReport, serviceConfig.failFast, serviceConfig.targetPercentile, serviceConfig.targetLatency,
+	)
+	if err != nil || !checkResult.shouldContinue {
+		return checkResult, err
+	}
+	for _, request := range serviceConfig.requests {
+		if gatlingStats == nil {
+			return nil, fmt.Errorf("failed to check request %v, stats of each request are not loaded", request.Name)
+		}
+		node := gatlingStats.Find(request.Name)
+		if node == nil {
+			return nil, fmt.Errorf("failed to check request %v, it is not found in gatling stats", request.Name)
+		}
+		checkResult, err := checkReportTarget(
+			node.Stats, request.FailFast, request.TargetPercentile, request.TargetLatency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("request %v %v", request.Name, err)
+		}
+		if !checkResult.shouldContinue {
+			checkResult.message = fmt.Sprintf("request %v %v", request.Name, checkResult.message)
+			return checkResult, nil
+		}
+	}
+	if sloResult.ShouldStop() {
+		return &checkContinueToExecResult{
+			shouldContinue: false,
+			message:        sloResult.Summary(),
+		}, nil
+	}
+	if comparison.Regressed() {
+		return &checkContinueToExecResult{
+			shouldContinue: false,
+			message:        comparison.Summary(),
+		}, nil
+	}
+	return &checkContinueToExecResult{
+		shouldContinue: true,
+		message:        "",
+	}, nil
+}
+
+/*
+checkReportTarget checks failed percentage and target percentile latency of gatlingReport.
 
+gatlingReport is global stats of loadtest or stats of a request, and shouldContinue is true when the report meets
+failFast and target latency.
+*/
+func checkReportTarget(
+	gatlingReport gatlingTools.GatlingReport,
+	failFast bool,
+	targetPercentile float64,
+	targetLatency float64,
+) (*checkContinueToExecResult, error) {
 	// Check failed percentage
 	if failFast {
 		if gatlingReport.Failed.Percentage > 0 {
@@ -616,6 +2465,19 @@ func checkContinueToExec(
 	}, nil
 }
 
+/*
+exactPercentiles returns percentiles of which latency is derived from simulation.log.
+
+They are targetPercentile and latency percentiles of sloAssertions, which may not be in gatling report.
+*/
+func (s serviceConfig) exactPercentiles(sloAssertions []slo.Assertion) []float64 {
+	percentiles := slo.Percentiles(sloAssertions)
+	if s.targetPercentile != 0 {
+		percentiles = append(percentiles, s.targetPercentile)
+	}
+	return percentiles
+}
+
 // extractServiceConfig extract cfg.Service struct field use for logging service metadata and so on.
 func extractServiceConfig(s cfg.Service) serviceConfig {
 	return serviceConfig{
@@ -624,5 +2486,11 @@ func extractServiceConfig(s cfg.Service) serviceConfig {
 		failFast:         s.FailFast,
 		targetLatency:    s.TargetLatency,
 		targetPercentile: s.TargetPercentile,
+		requests:         s.Requests,
+		baseline:         s.Baseline,
+		abortRules:       s.AbortRules,
+		metricsProvider:  s.MetricsProvider,
+		simulationLog:    s.SimulationLog,
+		slo:              s.SLO,
 	}
 }
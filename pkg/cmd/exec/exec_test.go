@@ -23,21 +23,44 @@ SOFTWARE.
 package exec
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	"github.com/st-tech/gatling-commander/pkg/external/cloudstorages"
+	"github.com/st-tech/gatling-commander/pkg/external/metricsproviders"
+	"github.com/st-tech/gatling-commander/pkg/internal/abort"
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
 	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
 	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/history"
+	kubeapiTools "github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
 	kubeutil "github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+	"github.com/st-tech/gatling-commander/pkg/internal/runreport"
+	"github.com/st-tech/gatling-commander/pkg/internal/runstate"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jinzhu/copier"
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsYaml "sigs.k8s.io/yaml"
 )
 
 const (
@@ -45,6 +68,7 @@ const (
 	BaseManifest              = "testdata/base_manifest.yaml"
 	Root                      = "../../.."
 	ImgURL                    = "example/gatling-scenario/sample-202308021850"
+	RunID                     = "202308021850-abcd"
 	SampleGatlingManifestPath = "testdata/sample_gatling_manifest.yaml"
 )
 
@@ -58,6 +82,22 @@ func (op *mockCloudStorageOperator) Fetch(ctx context.Context, path string) ([]b
 	return data, nil
 }
 
+func (op *mockCloudStorageOperator) List(ctx context.Context, dirPath string) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+func (op *mockCloudStorageOperator) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
 func TestLoadAndPatchBaseGatling(t *testing.T) {
 	sampleGatling, err := gatlingTools.LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
@@ -105,7 +145,7 @@ func TestLoadAndPatchBaseGatling(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gatling, err := loadAndPatchBaseGatling(ServiceName, ImgURL, tt.scenarioSpec, BaseManifest)
+			gatling, err := loadAndPatchBaseGatling(ServiceName, ImgURL, RunID, tt.scenarioSpec, BaseManifest)
 			assert.NoError(t, err)
 			if diff := cmp.Diff(*sampleGatling, *gatling); (diff == "") == tt.hasDiff {
 				t.Errorf("%v: unexpected diff found %v, diff %v", tt.hasDiff, diff != "", diff)
@@ -114,6 +154,84 @@ func TestLoadAndPatchBaseGatling(t *testing.T) {
 	}
 }
 
+func TestRenderGatlingManifests(t *testing.T) {
+	scenarioSpecs := []cfg.ScenarioSpec{
+		{
+			Name:    "sample-scenario",
+			SubName: "10rps",
+			TestScenarioSpec: gatlingv1alpha1.TestScenarioSpec{
+				SimulationClass: "SampleScenario",
+				Parallelism:     1,
+				Env: []corev1.EnvVar{
+					{
+						Name:  "CONCURRENCY",
+						Value: "10",
+					},
+				},
+			},
+		},
+		{
+			Name:    "sample-scenario",
+			SubName: "20rps",
+			TestScenarioSpec: gatlingv1alpha1.TestScenarioSpec{
+				SimulationClass: "SampleScenario",
+				Parallelism:     2,
+				Env: []corev1.EnvVar{
+					{
+						Name:  "CONCURRENCY",
+						Value: "10",
+					},
+				},
+			},
+		},
+	}
+	config := &cfg.Config{
+		BaseManifest: BaseManifest,
+		Services: []cfg.Service{
+			{
+				Name:          ServiceName,
+				ScenarioSpecs: scenarioSpecs,
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	err := renderGatlingManifests(&out, config, ImgURL, RunID)
+	assert.NoError(t, err)
+
+	documents := strings.Split(strings.TrimPrefix(out.String(), "---\n"), "---\n")
+	assert.Len(t, documents, len(scenarioSpecs))
+	for i, document := range documents {
+		assert.NotContains(t, document, "status:")
+		assert.NotContains(t, document, "creationTimestamp:")
+		var gatling gatlingv1alpha1.Gatling
+		err := sigsYaml.Unmarshal([]byte(document), &gatling)
+		assert.NoError(t, err)
+		expected, err := loadAndPatchBaseGatling(ServiceName, ImgURL, RunID, scenarioSpecs[i], BaseManifest)
+		assert.NoError(t, err)
+		if diff := cmp.Diff(expected.Spec, gatling.Spec); diff != "" {
+			t.Errorf("unexpected diff found in rendered manifest, diff %v", diff)
+		}
+		assert.Equal(t, expected.ObjectMeta.Name, gatling.ObjectMeta.Name)
+		assert.Equal(t, expected.ObjectMeta.Labels, gatling.ObjectMeta.Labels)
+	}
+}
+
+func TestRenderGatlingManifests_Failed(t *testing.T) {
+	config := &cfg.Config{
+		BaseManifest: "testdata/not_exists.yaml",
+		Services: []cfg.Service{
+			{
+				Name:          ServiceName,
+				ScenarioSpecs: []cfg.ScenarioSpec{{Name: "sample-scenario"}},
+			},
+		},
+	}
+	var out bytes.Buffer
+	err := renderGatlingManifests(&out, config, ImgURL, RunID)
+	assert.Error(t, err)
+}
+
 func TestValidateFlags(t *testing.T) {
 	// assign to var to refer to it as a pointer
 	skipBuildTrue := true
@@ -165,6 +283,42 @@ func TestValidateFlags(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			name: "invalid flag value (resume specified with dryRun)",
+			flags: execFlags{
+				dryRun: true,
+				resume: "state.json",
+			},
+			config: cfg.Config{
+				ImageURL: "",
+			},
+			expected: fmt.Errorf("resume flag can not be specified with dry-run flag"),
+		},
+		{
+			name: "valid flag value (junit and json outputs)",
+			flags: execFlags{
+				outputs: []string{"junit=results/junit.xml", "json=results/result.json"},
+			},
+			config:   cfg.Config{},
+			expected: nil,
+		},
+		{
+			name: "invalid flag value (output specified with dryRun)",
+			flags: execFlags{
+				dryRun:  true,
+				outputs: []string{"junit=results/junit.xml"},
+			},
+			config:   cfg.Config{},
+			expected: fmt.Errorf("output flag can not be specified with dry-run flag"),
+		},
+		{
+			name: "invalid flag value (unsupported output format)",
+			flags: execFlags{
+				outputs: []string{"html=results/index.html"},
+			},
+			config:   cfg.Config{},
+			expected: fmt.Errorf("unsupported output format html, specify junit or json"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -174,6 +328,72 @@ func TestValidateFlags(t *testing.T) {
 	}
 }
 
+func TestCreateOrAttachGatling(t *testing.T) {
+	tests := []struct {
+		name             string
+		existingStatus   *gatlingv1alpha1.GatlingStatus
+		previous         runstate.ScenarioState
+		expectedAttached bool
+	}{
+		{
+			name:             "attach to running gatling object of resumed run",
+			existingStatus:   &gatlingv1alpha1.GatlingStatus{RunnerStartTime: 1},
+			previous:         runstate.ScenarioState{Status: runstate.StatusRunning},
+			expectedAttached: true,
+		},
+		{
+			name:             "recreate failed gatling object of resumed run",
+			existingStatus:   &gatlingv1alpha1.GatlingStatus{Error: "runner job failed"},
+			previous:         runstate.ScenarioState{Status: runstate.StatusFailed},
+			expectedAttached: false,
+		},
+		{
+			name:             "recreate running gatling object when resumed run recorded it as failed",
+			existingStatus:   &gatlingv1alpha1.GatlingStatus{RunnerStartTime: 1},
+			previous:         runstate.ScenarioState{Status: runstate.StatusFailed},
+			expectedAttached: false,
+		},
+		{
+			name:             "create gatling object of pending scenario",
+			existingStatus:   nil,
+			previous:         runstate.ScenarioState{Status: runstate.StatusPending},
+			expectedAttached: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := kubeutil.InitFakeClient()
+			gatling, err := gatlingTools.LoadGatlingManifest(SampleGatlingManifestPath)
+			assert.NoError(t, err)
+			if tt.existingStatus != nil {
+				existingGatling := gatling.DeepCopy()
+				existingGatling.ObjectMeta.UID = "existing-uid"
+				existingGatling.Status = *tt.existingStatus
+				err = cl.Create(context.TODO(), existingGatling)
+				assert.NoError(t, err)
+				tt.previous.GatlingName = gatling.ObjectMeta.Name
+			}
+
+			attached, err := createOrAttachGatling(context.TODO(), cl, gatling, false, tt.previous)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAttached, attached)
+
+			foundGatling, err := gatlingTools.FindGatling(
+				context.TODO(), cl, gatling.ObjectMeta.Name, gatling.ObjectMeta.Namespace,
+			)
+			assert.NoError(t, err)
+			if assert.NotNil(t, foundGatling) && tt.expectedAttached {
+				// status of the attached object is waited by its UID.
+				assert.Equal(t, foundGatling.ObjectMeta.UID, gatling.ObjectMeta.UID)
+			}
+			if foundGatling != nil && !tt.expectedAttached {
+				// created gatling object has no status.
+				assert.Equal(t, gatlingv1alpha1.GatlingStatus{}, foundGatling.Status)
+			}
+		})
+	}
+}
+
 func TestLoadGatlingReportFromCloudStorage(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
 	reportCompletedGatling, err := gatlingTools.LoadGatlingManifest(SampleGatlingManifestPath)
@@ -250,6 +470,108 @@ func TestLoadGatlingReportFromCloudStorage_Fail(t *testing.T) {
 	}
 }
 
+func TestLoadGatlingStatsFromCloudStorage(t *testing.T) {
+	op := &mockCloudStorageOperator{}
+	gatlingStats, err := loadGatlingStatsFromCloudStorage(context.TODO(), op, "testdata/gatling_report_sample")
+	assert.NoError(t, err)
+	assert.Equal(t, "All Requests", gatlingStats.Name)
+	assert.Equal(t, 3, len(gatlingStats.Flatten()))
+
+	_, err = loadGatlingStatsFromCloudStorage(context.TODO(), op, "testdata/not_exists")
+	assert.Error(t, err)
+}
+
+func TestLoadReportPercentilesFromCloudStorage(t *testing.T) {
+	op := &mockCloudStorageOperator{}
+	percentiles, err := loadReportPercentilesFromCloudStorage(context.TODO(), op, "testdata/gatling_report_sample")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{50, 75, 95, 99.9}, percentiles)
+
+	_, err = loadReportPercentilesFromCloudStorage(context.TODO(), op, "testdata/not_exists")
+	assert.Error(t, err)
+}
+
+func TestLoadSimulationLogFromCloudStorage(t *testing.T) {
+	op := &mockCloudStorageOperator{}
+	stats, err := loadSimulationLogFromCloudStorage(context.TODO(), op, "testdata/gatling_report_sample", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(stats.Series))
+	assert.Equal(t, gatlingTools.SimulationLogSummary{
+		StartedAt:        time.Unix(1700000001, 0).UTC(),
+		FinishedAt:       time.Unix(1700000003, 0).UTC(),
+		Requests:         2,
+		Failed:           1,
+		FailedPercentage: 50,
+		Throughput:       1,
+		MeanLatency:      30,
+		P50Latency:       30,
+		P75Latency:       30,
+		P95Latency:       30,
+		P99Latency:       30,
+		MaxLatency:       30,
+	}, stats.Summary)
+
+	stats, err = loadSimulationLogFromCloudStorage(context.TODO(), op, "testdata/gatling_report_sample", 1, 99.9)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"99.9": 30}, stats.Summary.Percentiles)
+
+	_, err = loadSimulationLogFromCloudStorage(context.TODO(), op, "testdata/gatling_report_sample/js", 0)
+	assert.EqualError(t, err, "simulation.log is not found in testdata/gatling_report_sample/js")
+	_, err = loadSimulationLogFromCloudStorage(context.TODO(), op, "testdata/not_exists", 0)
+	assert.Error(t, err)
+}
+
+func TestCloudStorageOptions(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           cfg.CloudStorageConfig
+		cloudStorageSpec gatlingv1alpha1.CloudStorageSpec
+		expected         cloudstorages.Options
+	}{
+		{
+			name:   "provider and region are taken from gatling object",
+			config: cfg.CloudStorageConfig{S3Endpoint: "http://minio:9000", S3UsePathStyle: true},
+			cloudStorageSpec: gatlingv1alpha1.CloudStorageSpec{
+				Provider: "aws",
+				Bucket:   "report-bucket",
+				Region:   "ap-northeast-1",
+			},
+			expected: cloudstorages.Options{
+				Provider:       "aws",
+				Region:         "ap-northeast-1",
+				S3Endpoint:     "http://minio:9000",
+				S3UsePathStyle: true,
+			},
+		},
+		{
+			name: "azure storage account is taken from gatling object env",
+			cloudStorageSpec: gatlingv1alpha1.CloudStorageSpec{
+				Provider: "azure",
+				Bucket:   "report-container",
+				Env:      []corev1.EnvVar{{Name: "AZUREBLOB_ACCOUNT", Value: "account-in-manifest"}},
+			},
+			expected: cloudstorages.Options{Provider: "azure", AzureAccount: "account-in-manifest"},
+		},
+		{
+			name:   "azure storage account in config overrides gatling object env",
+			config: cfg.CloudStorageConfig{AzureAccount: "account-in-config"},
+			cloudStorageSpec: gatlingv1alpha1.CloudStorageSpec{
+				Provider: "azure",
+				Bucket:   "report-container",
+				Env:      []corev1.EnvVar{{Name: "AZUREBLOB_ACCOUNT", Value: "account-in-manifest"}},
+			},
+			expected: cloudstorages.Options{Provider: "azure", AzureAccount: "account-in-config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gatling := &gatlingv1alpha1.Gatling{}
+			gatling.Spec.CloudStorageSpec = tt.cloudStorageSpec
+			assert.Equal(t, tt.expected, cloudStorageOptions(tt.config, gatling))
+		})
+	}
+}
+
 func TestCheckContinueToExec_FailFast(t *testing.T) {
 	var failedExistsReport gatling.GatlingReport
 	sampleReport := gatling.GatlingReport{
@@ -304,7 +626,7 @@ func TestCheckContinueToExec_FailFast(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			checkContinue, err := checkContinueToExec(tt.serviceConfig, tt.gatlingReport)
+			checkContinue, err := checkContinueToExec(tt.serviceConfig, tt.gatlingReport, nil, nil, nil)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, checkContinue)
 		})
@@ -384,7 +706,7 @@ func TestCheckContinueToExec_TargetLatency(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			checkContinue, err := checkContinueToExec(tt.serviceConfig, sampleReport)
+			checkContinue, err := checkContinueToExec(tt.serviceConfig, sampleReport, nil, nil, nil)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, checkContinue)
 		})
@@ -426,14 +748,937 @@ func TestCheckContinueToExec_Failed(t *testing.T) {
 			gatlingReport: sampleReport,
 			expected: fmt.Errorf(
 				"failed to get specified percentile latency %v",
-				fmt.Errorf("specified percentile value is not matched to GatlingReport field"),
+				fmt.Errorf(
+					"specified percentile 80 is not matched to GatlingReport percentiles [50 75 95 99], "+
+						"and not derived from simulation.log",
+				),
 			),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := checkContinueToExec(tt.serviceConfig, sampleReport)
+			_, err := checkContinueToExec(tt.serviceConfig, sampleReport, nil, nil, nil)
 			assert.Equal(t, tt.expected, err)
 		})
 	}
 }
+
+func TestCheckContinueToExec_Requests(t *testing.T) {
+	statsBytes, err := os.ReadFile("testdata/gatling_report_sample/js/stats.json")
+	assert.NoError(t, err)
+	gatlingStats, err := gatlingTools.BytesToGatlingStats(statsBytes)
+	assert.NoError(t, err)
+	tests := []struct {
+		name          string
+		serviceConfig serviceConfig
+		gatlingStats  *gatlingTools.GatlingStats
+		expected      *checkContinueToExecResult
+		expectedErr   error
+	}{
+		{
+			name: "requests meet targets, should continue",
+			serviceConfig: serviceConfig{
+				requests: []cfg.RequestTargetConfig{
+					{Name: "home", FailFast: true, TargetPercentile: 99, TargetLatency: 200},
+					{Name: "checkout", TargetPercentile: 99, TargetLatency: 600},
+				},
+			},
+			gatlingStats: gatlingStats,
+			expected:     &checkContinueToExecResult{shouldContinue: true, message: ""},
+		},
+		{
+			name: "request failed with failFast, should not continue",
+			serviceConfig: serviceConfig{
+				requests: []cfg.RequestTargetConfig{{Name: "checkout / pay", FailFast: true}},
+			},
+			gatlingStats: gatlingStats,
+			expected: &checkContinueToExecResult{
+				shouldContinue: false,
+				message:        "request checkout / pay failed percentage greater than 0",
+			},
+		},
+		{
+			name: "request latency over target, should not continue",
+			serviceConfig: serviceConfig{
+				requests: []cfg.RequestTargetConfig{{Name: "pay", TargetPercentile: 99, TargetLatency: 500}},
+			},
+			gatlingStats: gatlingStats,
+			expected: &checkContinueToExecResult{
+				shouldContinue: false,
+				message:        "request pay latency below specified target, target: 500, result: 590",
+			},
+		},
+		{
+			name: "request not found",
+			serviceConfig: serviceConfig{
+				requests: []cfg.RequestTargetConfig{{Name: "login", FailFast: true}},
+			},
+			gatlingStats: gatlingStats,
+			expectedErr:  fmt.Errorf("failed to check request login, it is not found in gatling stats"),
+		},
+		{
+			name: "stats not loaded",
+			serviceConfig: serviceConfig{
+				requests: []cfg.RequestTargetConfig{{Name: "home", FailFast: true}},
+			},
+			gatlingStats: nil,
+			expectedErr:  fmt.Errorf("failed to check request home, stats of each request are not loaded"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkContinue, err := checkContinueToExec(tt.serviceConfig, gatling.GatlingReport{}, tt.gatlingStats, nil, nil)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, checkContinue)
+		})
+	}
+}
+
+func TestFormatResourceStats(t *testing.T) {
+	stats := kubeapiTools.ResourceStats{
+		Min:         100,
+		Max:         250,
+		Mean:        160,
+		P50:         150,
+		P95:         200,
+		P99:         240,
+		PeakPodName: "sample-pod",
+		PeakAt:      time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC),
+	}
+	assert.Equal(
+		t,
+		"min 100m p50 150m p95 200m p99 240m max 250m (peak at 2023-11-13T10:50:00Z in sample-pod)",
+		formatResourceStats(stats, "m"),
+	)
+}
+
+func TestSummarizeTargetMetrics(t *testing.T) {
+	limit := kubeapiTools.MetricsField{Cpu: 1000, Memory: 2000}
+	series := &kubeapiTools.MetricsSeries{
+		Cpu:    kubeapiTools.ResourceStats{Mean: 500},
+		Memory: kubeapiTools.ResourceStats{Mean: 500},
+	}
+	seriesWithAggregates := &kubeapiTools.MetricsSeries{
+		Cpu:                kubeapiTools.ResourceStats{Mean: 500},
+		Memory:             kubeapiTools.ResourceStats{Mean: 500},
+		Aggregates:         []kubeapiTools.AggregateSample{{Replicas: 2}},
+		CPUUsagePercent:    40,
+		MemoryUsagePercent: 20,
+	}
+	tests := []struct {
+		name          string
+		series        *kubeapiTools.MetricsSeries
+		expected      history.ContainerMetrics
+		expectedRatio metricsUsageRatio
+	}{
+		{
+			name:          "no series",
+			series:        nil,
+			expected:      history.ContainerMetrics{},
+			expectedRatio: metricsUsageRatio{},
+		},
+		{
+			name:   "usage divided by resources limit",
+			series: series,
+			expected: history.ContainerMetrics{
+				CPUUsageMean:       500,
+				MemoryUsageMean:    500,
+				CPUUsagePercent:    50,
+				MemoryUsagePercent: 25,
+				Series:             series,
+			},
+			expectedRatio: metricsUsageRatio{cpu: 0.5, memory: 0.25},
+		},
+		{
+			name:   "aggregate usage",
+			series: seriesWithAggregates,
+			expected: history.ContainerMetrics{
+				CPUUsageMean:       500,
+				MemoryUsageMean:    500,
+				CPUUsagePercent:    40,
+				MemoryUsagePercent: 20,
+				Series:             seriesWithAggregates,
+			},
+			expectedRatio: metricsUsageRatio{cpu: 0.4, memory: 0.2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetMetrics, ratio := summarizeTargetMetrics("sample-target", tt.series, limit)
+			assert.Equal(t, history.TargetMetrics{Name: "sample-target", ContainerMetrics: tt.expected}, targetMetrics)
+			assert.Equal(t, tt.expectedRatio, ratio)
+		})
+	}
+}
+
+func TestCheckRunnerSaturation(t *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("2000"),
+		},
+	}
+	limit := kubeapiTools.MetricsField{Cpu: 1000, Memory: 2000}
+	series := &kubeapiTools.MetricsSeries{
+		Pods: []kubeapiTools.PodMetricsStats{
+			{PodName: "runner-a", Cpu: kubeapiTools.ResourceStats{Max: 950}},
+		},
+	}
+	tests := []struct {
+		name             string
+		series           *kubeapiTools.MetricsSeries
+		resources        corev1.ResourceRequirements
+		expected         *history.RunnerMetrics
+		expectedSummary  string
+		expectedErrExist bool
+	}{
+		{
+			name:      "saturated",
+			series:    series,
+			resources: resources,
+			expected: &history.RunnerMetrics{
+				ResourcesLimit:    limit,
+				Saturated:         true,
+				SaturationReasons: []string{"pod runner-a cpu usage 95.0% exceeded 90%"},
+				Series:            series,
+			},
+			expectedSummary: "load generator saturated, pod runner-a cpu usage 95.0% exceeded 90%",
+		},
+		{
+			name:            "no series",
+			series:          nil,
+			resources:       resources,
+			expected:        &history.RunnerMetrics{ResourcesLimit: limit},
+			expectedSummary: "ok",
+		},
+		{
+			name:             "resources not specified",
+			series:           series,
+			resources:        corev1.ResourceRequirements{},
+			expected:         nil,
+			expectedSummary:  "not checked",
+			expectedErrExist: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runnerMetrics, err := checkRunnerSaturation(
+				tt.series, tt.resources, cfg.RunnerSaturationConfig{CPUPercent: 90},
+			)
+			assert.Equal(t, tt.expectedErrExist, err != nil)
+			assert.Equal(t, tt.expected, runnerMetrics)
+			assert.Equal(t, tt.expectedSummary, loadGeneratorSummary(runnerMetrics))
+		})
+	}
+}
+
+func TestTargetHealthSummary(t *testing.T) {
+	healthy := &kubeapiTools.PodHealth{}
+	unhealthy := &kubeapiTools.PodHealth{Restarts: 2, OOMKills: 2}
+	tests := []struct {
+		name            string
+		targets         []history.TargetMetrics
+		expected        string
+		expectedTargets []string
+	}{
+		{
+			name:     "not watched",
+			targets:  []history.TargetMetrics{{Name: "api"}},
+			expected: "not checked",
+		},
+		{
+			name:     "healthy",
+			targets:  []history.TargetMetrics{{Name: "api", Health: healthy}, {Name: "worker", Health: healthy}},
+			expected: "healthy",
+		},
+		{
+			name:     "unhealthy",
+			targets:  []history.TargetMetrics{{Name: "api", Health: healthy}, {Name: "worker", Health: unhealthy}},
+			expected: "target worker restarts 2, oomKills 2, evictions 0, readiness flaps 0",
+			expectedTargets: []string{
+				"target worker restarts 2, oomKills 2, evictions 0, readiness flaps 0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, targetHealthSummary(tt.targets))
+			assert.Equal(t, tt.expectedTargets, collectUnhealthyTargets(tt.targets))
+		})
+	}
+}
+
+func TestCollectNotificationDetails(t *testing.T) {
+	state := runstate.RunState{
+		Services: []runstate.ServiceState{
+			{
+				Name: "sample-service",
+				Scenarios: []runstate.ScenarioState{
+					{Name: "sample-scenario", SubName: "1"},
+					{
+						Name:                   "sample-scenario",
+						SubName:                "2",
+						LoadGeneratorSaturated: true,
+						SaturationReasons:      []string{"pod runner-a cpu usage 95.0% exceeded 90%"},
+						UnhealthyTargets: []string{
+							"target api restarts 1, oomKills 1, evictions 0, readiness flaps 0",
+						},
+					},
+					{Name: "sample-scenario", SubName: "3", Status: runstate.StatusCancelled},
+					{
+						Name:    "sample-scenario",
+						SubName: "4",
+						SLO: &slo.Result{Assertions: []slo.AssertionResult{
+							{Assertion: "cpu% < 70", Severity: slo.SeverityWarn, Passed: false, Actual: 75, Threshold: 70},
+						}},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"service sample-service scenario sample-scenario 2, load generator saturated, " +
+			"pod runner-a cpu usage 95.0% exceeded 90%",
+		"service sample-service scenario sample-scenario 2, " +
+			"target api restarts 1, oomKills 1, evictions 0, readiness flaps 0",
+		"service sample-service scenario sample-scenario 3, cancelled",
+		"service sample-service scenario sample-scenario 4, slo violated: cpu% < 70 [warn] (result: 75)",
+	}, collectNotificationDetails(state))
+}
+
+func TestCollectProviderMetrics(t *testing.T) {
+	start := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	// stand-in of Prometheus server which returns the same series for every query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"sample-pod"},"values":[[1699872600,"0.25"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		serviceConfig serviceConfig
+		expected      []metricsproviders.QueryResult
+	}{
+		{
+			name:          "metricsProvider is not configured",
+			serviceConfig: serviceConfig{name: "sample-service"},
+			expected:      nil,
+		},
+		{
+			name: "prometheus",
+			serviceConfig: serviceConfig{
+				name: "sample-service",
+				metricsProvider: &cfg.MetricsProviderConfig{
+					Type:    "prometheus",
+					Address: server.URL,
+					Queries: []cfg.MetricsQueryConfig{{Name: "cpu-throttling", Query: "throttling"}},
+				},
+			},
+			expected: []metricsproviders.QueryResult{
+				{
+					Name:  "cpu-throttling",
+					Query: "throttling",
+					Series: []metricsproviders.Series{
+						{
+							Labels:  map[string]string{"pod": "sample-pod"},
+							Samples: []metricsproviders.Sample{{Timestamp: start, Value: 0.25}},
+							Min:     0.25, Max: 0.25, Mean: 0.25, Last: 0.25,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported provider is only logged",
+			serviceConfig: serviceConfig{
+				name: "sample-service",
+				metricsProvider: &cfg.MetricsProviderConfig{
+					Type:    "datadog",
+					Queries: []cfg.MetricsQueryConfig{{Name: "up", Query: "up"}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "only target usage is fetched from prometheus",
+			serviceConfig: serviceConfig{
+				name: "sample-service",
+				metricsProvider: &cfg.MetricsProviderConfig{
+					Type: "prometheus", Address: server.URL, TargetUsage: true,
+				},
+			},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := collectProviderMetrics(
+				context.Background(), tt.serviceConfig, "sample-scenario", start, start.Add(5*time.Minute),
+			)
+			assert.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestProviderMetricsSource(t *testing.T) {
+	podCl := kubeutil.InitFakeClient()
+	for _, podName := range []string{"sample-pod-1", "sample-pod-0"} {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: "sample-namespace",
+				Labels:    map[string]string{"app": "sample-app"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		assert.NoError(t, podCl.Create(context.Background(), &pod))
+	}
+	// stand-in of Prometheus server which serves instant query of usage of the running pods
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		if r.URL.Path != "/api/v1/query" || !strings.Contains(query, `pod=~"sample-pod-0|sample-pod-1"`) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected request"}`))
+			return
+		}
+		value := "1048576"
+		if strings.Contains(query, "container_cpu_usage_seconds_total") {
+			value = "250.4"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"sample-pod-0"},"value":[1699872600,"` + value + `"]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	providerConfig := &cfg.MetricsProviderConfig{Type: "prometheus", Address: server.URL, TargetUsage: true}
+	assert.Equal(t, "prometheus", targetMetricsSourceName(providerConfig))
+	assert.Equal(t, "metrics-server", targetMetricsSourceName(nil))
+	source, err := newTargetMetricsSource(providerConfig, abort.Target{PodCl: podCl})
+	assert.NoError(t, err)
+	samples, err := source.FetchContainerMetrics(context.Background(), cfg.TargetPodConfig{
+		Namespace:     "sample-namespace",
+		LabelKey:      "app",
+		LabelValue:    "sample-app",
+		ContainerName: "sample-container",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []kubeapiTools.MetricsSample{
+		{
+			PodName:   "sample-pod-0",
+			Timestamp: time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC),
+			Cpu:       250,
+			Memory:    1048576,
+		},
+	}, samples)
+}
+
+func TestRecordLoadtestError(t *testing.T) {
+	tests := []struct {
+		name                string
+		err                 error
+		expectedStatus      runstate.ScenarioStatus
+		expectedAbortReason string
+		expectedOutcome     string
+	}{
+		{
+			name:           "failed loadtest",
+			err:            fmt.Errorf("failed to get gatling report"),
+			expectedStatus: runstate.StatusFailed,
+		},
+		{
+			name: "runner failed loadtest",
+			err: fmt.Errorf(
+				"failed to wait gatling job running, %w",
+				&gatlingTools.RunnerFailedError{Reason: "runner job sample-runner failed, BackoffLimitExceeded"},
+			),
+			expectedStatus:  runstate.StatusFailed,
+			expectedOutcome: "runner-failed",
+		},
+		{
+			name: "cancelled loadtest",
+			err: fmt.Errorf(
+				"failed to wait gatling job running, %w", &gatlingTools.CancelledError{Cause: context.Canceled},
+			),
+			expectedStatus:  runstate.StatusCancelled,
+			expectedOutcome: "cancelled",
+		},
+		{
+			name:            "loadtest failed by cancelled request",
+			err:             fmt.Errorf("failed to get target pod spec resources, %w", context.Canceled),
+			expectedStatus:  runstate.StatusCancelled,
+			expectedOutcome: "cancelled",
+		},
+		{
+			name: "timed out loadtest",
+			err: fmt.Errorf(
+				"failed to wait gatling job start, %w", &gatlingTools.TimedOutError{Reason: "timeout 1800 execeeded"},
+			),
+			expectedStatus:  runstate.StatusFailed,
+			expectedOutcome: "timed-out",
+		},
+		{
+			name: "aborted loadtest",
+			err: fmt.Errorf(
+				"failed to wait gatling job running, %w",
+				&gatlingTools.AbortedError{Reason: "target container cpu usage 95.0% exceeded 90% for 1m0s"},
+			),
+			expectedStatus:      runstate.StatusAborted,
+			expectedAbortReason: "target container cpu usage 95.0% exceeded 90% for 1m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := runstate.ScenarioState{Status: runstate.StatusRunning}
+			recordLoadtestError(&state, tt.err)
+			assert.Equal(t, tt.expectedStatus, state.Status)
+			assert.Equal(t, tt.err.Error(), state.Error)
+			assert.Equal(t, tt.expectedAbortReason, state.AbortReason)
+			assert.Equal(t, tt.expectedOutcome, state.Outcome)
+		})
+	}
+}
+
+func TestWrapCancelled(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requestErr := fmt.Errorf("failed to get target pod spec resources")
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		err              error
+		expectedCanceled bool
+	}{
+		{
+			name:             "error of running context is returned as it is",
+			ctx:              context.Background(),
+			err:              requestErr,
+			expectedCanceled: false,
+		},
+		{
+			name:             "error of cancelled context is wrapped",
+			ctx:              cancelledCtx,
+			err:              requestErr,
+			expectedCanceled: true,
+		},
+		{
+			name:             "nil error is returned as it is",
+			ctx:              cancelledCtx,
+			err:              nil,
+			expectedCanceled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapCancelled(tt.ctx, tt.err)
+			assert.Equal(t, tt.expectedCanceled, errors.Is(err, context.Canceled))
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestHandleSignals(t *testing.T) {
+	tests := []struct {
+		name             string
+		signals          []os.Signal
+		expectedCanceled bool
+		expectedExitCode int
+	}{
+		{
+			name:             "no signal",
+			signals:          nil,
+			expectedCanceled: false,
+			expectedExitCode: -1,
+		},
+		{
+			name:             "first signal cancels run",
+			signals:          []os.Signal{syscall.SIGTERM},
+			expectedCanceled: true,
+			expectedExitCode: -1,
+		},
+		{
+			name:             "second signal exits",
+			signals:          []os.Signal{os.Interrupt, os.Interrupt},
+			expectedCanceled: true,
+			expectedExitCode: 130,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			signalCh := make(chan os.Signal, len(tt.signals))
+			for _, sig := range tt.signals {
+				signalCh <- sig
+			}
+			close(signalCh)
+			exitCode := -1
+			handleSignals(signalCh, cancel, func(code int) { exitCode = code })
+			assert.Equal(t, tt.expectedCanceled, ctx.Err() != nil)
+			assert.Equal(t, tt.expectedExitCode, exitCode)
+		})
+	}
+}
+
+func TestGenerateSearchScenarioSpec(t *testing.T) {
+	template := cfg.ScenarioSpec{
+		Name:    "sample-scenario",
+		SubName: "",
+		TestScenarioSpec: gatlingv1alpha1.TestScenarioSpec{
+			SimulationClass: "SampleSimulation",
+			Parallelism:     1,
+			Env: []corev1.EnvVar{
+				{Name: "ENV", Value: "dev"},
+				{Name: "CONCURRENCY", Value: "10"},
+			},
+		},
+	}
+	scenarioSpec := generateSearchScenarioSpec(template, 40)
+	assert.Equal(t, "sample-scenario", scenarioSpec.Name)
+	assert.Equal(t, "c40", scenarioSpec.SubName)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "ENV", Value: "dev"},
+		{Name: "CONCURRENCY", Value: "40"},
+	}, scenarioSpec.TestScenarioSpec.Env)
+	// template is not modified.
+	assert.Equal(t, "10", template.TestScenarioSpec.Env[1].Value)
+
+	template.SubName = "search"
+	assert.Equal(t, "search-c40", generateSearchScenarioSpec(template, 40).SubName)
+}
+
+func TestEvaluateCapacityTrial(t *testing.T) {
+	sampleReport := gatling.GatlingReport{
+		NintyNinthPercentiles: gatling.GatlingReportStats{
+			Ok: 100,
+		},
+		Failed: gatling.GatlingReportGroup{
+			Percentage: 2,
+		},
+	}
+	search := cfg.SearchConfig{
+		ErrorBudgetPercent: 5,
+		CPUCeilingPercent:  80,
+	}
+
+	tests := []struct {
+		name           string
+		serviceConfig  serviceConfig
+		search         cfg.SearchConfig
+		cpuRatio       float64
+		slo            *slo.Result
+		expectedPassed bool
+		expectedReason string
+	}{
+		{
+			name:           "all targets are met",
+			serviceConfig:  serviceConfig{targetLatency: 200, targetPercentile: 99},
+			search:         search,
+			cpuRatio:       0.5,
+			expectedPassed: true,
+			expectedReason: "",
+		},
+		{
+			name:           "latency and cpu exceed target",
+			serviceConfig:  serviceConfig{targetLatency: 50, targetPercentile: 99},
+			search:         search,
+			cpuRatio:       0.9,
+			expectedPassed: false,
+			expectedReason: "latency exceeds target, target: 50, result: 100, " +
+				"cpu usage exceeds ceiling, ceiling: 80, result: 90",
+		},
+		{
+			name:           "failed percentage exceeds error budget",
+			serviceConfig:  serviceConfig{},
+			search:         cfg.SearchConfig{ErrorBudgetPercent: 1},
+			cpuRatio:       0.9,
+			expectedPassed: false,
+			expectedReason: "failed percentage exceeds error budget, budget: 1, result: 2",
+		},
+		{
+			name:          "slo assertion of fail-run severity is violated",
+			serviceConfig: serviceConfig{},
+			search:        search,
+			cpuRatio:      0.5,
+			slo: &slo.Result{Assertions: []slo.AssertionResult{
+				{Assertion: "p99 < 50ms", Severity: slo.SeverityFailRun, Passed: false, Actual: 100, Threshold: 50},
+				{Assertion: "cpu% < 40", Severity: slo.SeverityWarn, Passed: false, Actual: 50, Threshold: 40},
+			}},
+			expectedPassed: false,
+			expectedReason: "slo violated: p99 < 50ms [fail-run] (result: 100), cpu% < 40 [warn] (result: 50)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, reason, err := evaluateCapacityTrial(tt.serviceConfig, tt.search, loadtestResult{
+				gatlingReport:     &sampleReport,
+				metricsUsageRatio: metricsUsageRatio{cpu: tt.cpuRatio},
+				slo:               tt.slo,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPassed, passed)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestCompareWithBaseline(t *testing.T) {
+	scenarioSpec := cfg.ScenarioSpec{Name: "sample-scenario", SubName: "1"}
+	source := baselineSource{
+		runs: []*runstate.RunState{
+			{
+				RunID: "202311131050-1a2b",
+				Services: []runstate.ServiceState{
+					{
+						Name: ServiceName,
+						Scenarios: []runstate.ScenarioState{
+							{
+								Name:    "sample-scenario",
+								SubName: "1",
+								Status:  runstate.StatusCompleted,
+								Metrics: &baseline.Metrics{P99Latency: 100},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	baselineConfig := &cfg.BaselineConfig{P99IncreasePercent: 10}
+
+	tests := []struct {
+		name            string
+		source          baselineSource
+		baselineConfig  *cfg.BaselineConfig
+		metrics         baseline.Metrics
+		expectedSummary string
+	}{
+		{
+			name:            "regressed from last green run",
+			source:          source,
+			baselineConfig:  baselineConfig,
+			metrics:         baseline.Metrics{P99Latency: 150, Source: baseline.SourceReport},
+			expectedSummary: "regression vs 202311131050-1a2b: p99 +50.0% (100 -> 150)",
+		},
+		{
+			name:            "metrics of different source are not compared",
+			source:          source,
+			baselineConfig:  baselineConfig,
+			metrics:         baseline.Metrics{P99Latency: 150, Source: baseline.SourceSimulationLog, WarmUpSec: 60},
+			expectedSummary: "no baseline",
+		},
+		{
+			name:            "baseline not set to service",
+			source:          source,
+			baselineConfig:  nil,
+			expectedSummary: "no baseline",
+		},
+		{
+			name:            "pinned run not found",
+			source:          baselineSource{runs: source.runs, pinnedRunID: "202311131050-ffff"},
+			baselineConfig:  baselineConfig,
+			expectedSummary: "no baseline",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := compareWithBaseline(
+				tt.source,
+				RunID,
+				serviceConfig{name: ServiceName, baseline: tt.baselineConfig},
+				scenarioSpec,
+				tt.metrics,
+			)
+			assert.Equal(t, tt.expectedSummary, comparison.Summary())
+		})
+	}
+}
+
+func TestCheckContinueToExec_Regression(t *testing.T) {
+	comparison := &baseline.Comparison{
+		BaselineRunID: "202311131050-1a2b",
+		Regressions: []baseline.Regression{
+			{Metric: baseline.MetricP99Latency, Baseline: 100, Current: 150, Change: 50},
+		},
+	}
+	checkContinue, err := checkContinueToExec(serviceConfig{}, gatling.GatlingReport{}, nil, comparison, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &checkContinueToExecResult{
+		shouldContinue: false,
+		message:        "regression vs 202311131050-1a2b: p99 +50.0% (100 -> 150)",
+	}, checkContinue)
+}
+
+func TestCheckContinueToExec_SLO(t *testing.T) {
+	tests := []struct {
+		name      string
+		sloResult *slo.Result
+		expected  *checkContinueToExecResult
+	}{
+		{
+			name: "assertion of stop severity is violated, should not continue",
+			sloResult: &slo.Result{Assertions: []slo.AssertionResult{
+				{Assertion: "p99 < 300ms", Severity: slo.SeverityStop, Passed: false, Actual: 350, Threshold: 300},
+			}},
+			expected: &checkContinueToExecResult{
+				shouldContinue: false,
+				message:        "slo violated: p99 < 300ms [stop] (result: 350)",
+			},
+		},
+		{
+			name: "assertion of fail-run severity is violated, should continue",
+			sloResult: &slo.Result{Assertions: []slo.AssertionResult{
+				{Assertion: "p99 < 300ms", Severity: slo.SeverityFailRun, Passed: false, Actual: 350, Threshold: 300},
+			}},
+			expected: &checkContinueToExecResult{
+				shouldContinue: true,
+				message:        "",
+			},
+		},
+		{
+			name:      "no slo, should continue",
+			sloResult: nil,
+			expected: &checkContinueToExecResult{
+				shouldContinue: true,
+				message:        "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkContinue, err := checkContinueToExec(serviceConfig{}, gatling.GatlingReport{}, nil, nil, tt.sloResult)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, checkContinue)
+		})
+	}
+}
+
+func TestEvaluateSLO(t *testing.T) {
+	sloConfig := &cfg.SLOConfig{
+		TargetRPS: 200,
+		Assertions: []cfg.SLOAssertionConfig{
+			{Assert: "rps >= 95% of target", Severity: "fail-run"},
+			{Assert: "memory% < 80", Severity: "warn"},
+		},
+	}
+	assertions, err := sloConfig.ParseAssertions()
+	assert.NoError(t, err)
+	report := &gatling.GatlingReport{
+		MeanNumberOfRequestsPerSecond: gatling.GatlingReportStats{Total: 180},
+	}
+	sloResult := evaluateSLO(sloConfig, assertions, report, metricsUsageRatio{cpu: 0.5, memory: 0.6})
+	assert.Equal(t, []slo.AssertionResult{
+		{Assertion: "rps >= 95% of target", Severity: slo.SeverityFailRun, Passed: false, Actual: 180, Threshold: 190},
+		{Assertion: "memory% < 80", Severity: slo.SeverityWarn, Passed: true, Actual: 60, Threshold: 80},
+	}, sloResult.Assertions)
+
+	assert.Nil(t, evaluateSLO(nil, nil, report, metricsUsageRatio{}))
+}
+
+func TestCollectRegressions(t *testing.T) {
+	regressed := &baseline.Comparison{
+		BaselineRunID: "run-1",
+		Regressions:   []baseline.Regression{{Metric: baseline.MetricP99Latency, Baseline: 100, Current: 150, Change: 50}},
+	}
+	state := runstate.RunState{
+		Services: []runstate.ServiceState{
+			{
+				Name: "sample-service",
+				Scenarios: []runstate.ScenarioState{
+					{Name: "sample-scenario", SubName: "1", Comparison: regressed},
+					{Name: "sample-scenario", SubName: "2", Comparison: &baseline.Comparison{BaselineRunID: "run-1"}},
+					{Name: "sample-scenario", SubName: "3"},
+				},
+			},
+			{
+				// capacity search trials which overload the target don't fail run.
+				Name:     "search-service",
+				Capacity: &capacity.Result{Found: true, Capacity: 10},
+				Scenarios: []runstate.ScenarioState{
+					{Name: "search-scenario", SubName: "concurrency 20", Comparison: regressed},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"service sample-service scenario sample-scenario 1, regression vs run-1: p99 +50.0% (100 -> 150)",
+	}, collectRegressions(state))
+}
+
+func TestCollectSLOFailures(t *testing.T) {
+	failed := &slo.Result{Assertions: []slo.AssertionResult{
+		{Assertion: "p99 < 300ms", Severity: slo.SeverityFailRun, Passed: false, Actual: 350, Threshold: 300},
+	}}
+	warned := &slo.Result{Assertions: []slo.AssertionResult{
+		{Assertion: "p99 < 300ms", Severity: slo.SeverityWarn, Passed: false, Actual: 350, Threshold: 300},
+	}}
+	state := runstate.RunState{
+		Services: []runstate.ServiceState{
+			{
+				Name: "sample-service",
+				Scenarios: []runstate.ScenarioState{
+					{Name: "sample-scenario", SubName: "1", SLO: failed},
+					{Name: "sample-scenario", SubName: "2", SLO: warned},
+					{Name: "sample-scenario", SubName: "3"},
+				},
+			},
+			{
+				// SLO of capacity search trials doesn't fail run.
+				Name:      "search-service",
+				Capacity:  &capacity.Result{Found: true, Capacity: 10},
+				Scenarios: []runstate.ScenarioState{{Name: "search-scenario", SubName: "concurrency 20", SLO: failed}},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"service sample-service scenario sample-scenario 1, slo violated: p99 < 300ms [fail-run] (result: 350)",
+	}, collectSLOFailures(state))
+}
+
+func TestWriteRunReports(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "result.json")
+	junitPath := filepath.Join(dir, "junit.xml")
+
+	// report is written even when run failed before run state is initialized.
+	writeRunReports(
+		[]string{"json=" + jsonPath, "junit=" + junitPath},
+		filepath.Join(dir, "runs"),
+		nil,
+		fmt.Errorf("gatling image build error"),
+	)
+	jsonBytes, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	var report runreport.Report
+	assert.NoError(t, json.Unmarshal(jsonBytes, &report))
+	assert.False(t, report.Passed)
+	assert.Equal(t, "gatling image build error", report.Error)
+	_, err = os.Stat(junitPath)
+	assert.NoError(t, err)
+
+	state := &runstate.RunState{
+		RunID: RunID,
+		Services: []runstate.ServiceState{
+			{
+				Name:      ServiceName,
+				Scenarios: []runstate.ScenarioState{{Name: "sample-scenario", SubName: "1", Status: runstate.StatusCompleted}},
+			},
+		},
+	}
+	writeRunReports([]string{"json=" + jsonPath}, filepath.Join(dir, "runs"), state, nil)
+	jsonBytes, err = os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	report = runreport.Report{}
+	assert.NoError(t, json.Unmarshal(jsonBytes, &report))
+	assert.True(t, report.Passed)
+	assert.Equal(t, runreport.Counts{Tests: 1}, report.Counts)
+}
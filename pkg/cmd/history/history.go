@@ -0,0 +1,363 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package history implements command which browses loadtest results persisted in local run-history store.
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	historyTools "github.com/st-tech/gatling-commander/pkg/internal/history"
+	"github.com/st-tech/gatling-commander/pkg/internal/runstate"
+
+	"github.com/spf13/cobra"
+)
+
+// CmdName is name of history command. root command skip its own config validation for this command.
+const CmdName = "history"
+
+const (
+	dateLayout       = "2006-01-02"
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+)
+
+type filterFlags struct {
+	runID        string
+	serviceName  string
+	scenarioName string
+	from         string
+	to           string
+}
+
+type exportFlags struct {
+	format string
+	output string
+}
+
+// showResult is output of show command.
+type showResult struct {
+	Run     *runstate.RunState    `json:"run"`
+	Config  json.RawMessage       `json:"config,omitempty"`
+	Records []historyTools.Record `json:"records"`
+}
+
+func (f *filterFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.runID, "run-id", "", "show only results of the run")
+	cmd.Flags().StringVar(&f.serviceName, "service", "", "show only results of the service")
+	cmd.Flags().StringVar(&f.scenarioName, "scenario", "", "show only results of the scenario")
+	cmd.Flags().StringVar(&f.from, "from", "", "show only results started on or after the date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&f.to, "to", "", "show only results started on or before the date (YYYY-MM-DD)")
+}
+
+// filter returns history.Filter from flags, dates are parsed in local time zone and to date is inclusive.
+func (f *filterFlags) filter() (historyTools.Filter, error) {
+	filter := historyTools.Filter{
+		RunID:        f.runID,
+		ServiceName:  f.serviceName,
+		ScenarioName: f.scenarioName,
+	}
+	if f.from != "" {
+		from, err := time.ParseInLocation(dateLayout, f.from, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("from flag must be YYYY-MM-DD format, %v", err)
+		}
+		filter.From = from
+	}
+	if f.to != "" {
+		to, err := time.ParseInLocation(dateLayout, f.to, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("to flag must be YYYY-MM-DD format, %v", err)
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// NewCmdHistory creates the `history` command and its subcommands.
+func NewCmdHistory(baseName string, config *cfg.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CmdName,
+		Short: "Browse load test results recorded in local run-history store",
+		Long: `The history command browse load test results which exec command recorded in run directories under runsDir.
+		Complete documentation is available at https://github.com/st-tech/gatling-commander/docs`,
+	}
+	cmd.AddCommand(newCmdList(config))
+	cmd.AddCommand(newCmdShow(config))
+	cmd.AddCommand(newCmdExport(config))
+	return cmd
+}
+
+func newCmdList(config *cfg.Config) *cobra.Command {
+	flags := &filterFlags{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List load test results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter()
+			if err != nil {
+				return err
+			}
+			return runList(cmd.OutOrStdout(), config.GetRunsDir(), filter)
+		},
+	}
+	flags.addFlags(cmd)
+	return cmd
+}
+
+func newCmdShow(config *cfg.Config) *cobra.Command {
+	flags := &filterFlags{}
+	cmd := &cobra.Command{
+		Use:   "show <run ID>",
+		Short: "Show run state, config snapshot and load test results of the run as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.runID = args[0]
+			filter, err := flags.filter()
+			if err != nil {
+				return err
+			}
+			return runShow(cmd.OutOrStdout(), config.GetRunsDir(), filter)
+		},
+	}
+	cmd.Flags().StringVar(&flags.serviceName, "service", "", "show only results of the service")
+	cmd.Flags().StringVar(&flags.scenarioName, "scenario", "", "show only results of the scenario")
+	return cmd
+}
+
+func newCmdExport(config *cfg.Config) *cobra.Command {
+	flags := &filterFlags{}
+	export := &exportFlags{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export load test results as JSON or CSV",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := flags.filter()
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if export.output != "" {
+				f, err := os.Create(export.output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file, %v", err)
+				}
+				defer f.Close()
+				w = f
+			}
+			return runExport(w, config.GetRunsDir(), filter, export.format)
+		},
+	}
+	flags.addFlags(cmd)
+	cmd.Flags().StringVar(&export.format, "format", exportFormatJSON, "export format, json or csv")
+	cmd.Flags().StringVarP(&export.output, "output", "o", "", "output file path, defaults to stdout")
+	return cmd
+}
+
+// runList print load test results which match filter as table.
+func runList(w io.Writer, runsDir string, filter historyTools.Filter) error {
+	records, err := historyTools.ListRecords(runsDir, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list history records, %v", err)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(
+		tw,
+		"RUN ID\tSERVICE\tSCENARIO\tSUB NAME\tSTARTED AT\tP95 (ms)\tP99 (ms)\tFAILED (%)\tREQ/S\tBASELINE\tSLO\t"+
+			"LOAD GENERATOR",
+	)
+	formatLatency := func(metrics baseline.Metrics, latency float64) string {
+		if metrics.LatencyUnknown {
+			return "-"
+		}
+		return fmt.Sprint(latency)
+	}
+	for _, r := range records {
+		fmt.Fprintf(
+			tw,
+			"%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%.4g\t%v\t%v\t%v\n",
+			r.RunID,
+			r.ServiceName,
+			r.ScenarioName,
+			r.ScenarioSubName,
+			r.StartedAt.Local().Format("2006-01-02 15:04"),
+			formatLatency(r.Metrics, r.Metrics.P95Latency),
+			formatLatency(r.Metrics, r.Metrics.P99Latency),
+			r.Metrics.FailedPercentage,
+			r.Metrics.Throughput,
+			r.Comparison.Summary(),
+			r.SLO.Summary(),
+			r.LoadGeneratorState(),
+		)
+	}
+	return tw.Flush()
+}
+
+// runShow print run state, config snapshot and load test results of the run as JSON.
+func runShow(w io.Writer, runsDir string, filter historyTools.Filter) error {
+	runDir := filepath.Join(runsDir, filter.RunID)
+	state, err := runstate.Load(filepath.Join(runDir, runstate.FileName))
+	if err != nil {
+		return fmt.Errorf("failed to load run %v, %v", filter.RunID, err)
+	}
+	records, err := historyTools.ListRecords(runsDir, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list history records, %v", err)
+	}
+	result := showResult{
+		Run:     state,
+		Records: records,
+	}
+	// config snapshot doesn't exist in run recorded by older version, so it is optional.
+	if config, err := historyTools.LoadConfigSnapshot(runDir); err == nil {
+		result.Config = config
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// runExport write load test results which match filter in specified format.
+func runExport(w io.Writer, runsDir string, filter historyTools.Filter, format string) error {
+	records, err := historyTools.ListRecords(runsDir, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list history records, %v", err)
+	}
+	switch format {
+	case exportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case exportFormatCSV:
+		return writeCSV(w, records)
+	default:
+		return fmt.Errorf("unsupported export format %v, specify %v or %v", format, exportFormatJSON, exportFormatCSV)
+	}
+}
+
+/*
+writeCSV write main values of records as CSV, nested objects such as Gatling object are not included.
+
+Row of whole loadtest has empty request column, and it is followed by rows of each group and request of the loadtest
+which have request path in request column. Target container metrics and baseline are not written to them.
+*/
+func writeCSV(w io.Writer, records []historyTools.Record) error {
+	csvWriter := csv.NewWriter(w)
+	header := []string{
+		"runID", "serviceName", "scenarioName", "scenarioSubName", "imageURL", "startedAt", "finishedAt",
+		"requests", "meanLatency", "maxLatency", "p50Latency", "p75Latency", "p95Latency", "p99Latency",
+		"failedPercentage", "throughput", "cpuUsagePercent", "memoryUsagePercent", "cpuPerRequest", "baseline",
+		"loadGenerator", "request",
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	// percentiles of the report depend on gatling.conf, so latency is empty when percentile is not in the report.
+	formatPercentileLatency := func(report *gatlingTools.GatlingReport, percentile float64) string {
+		latency, err := report.GetPercentileLatency(percentile)
+		if err != nil {
+			return ""
+		}
+		return formatFloat(latency)
+	}
+	formatMetricsLatency := func(metrics baseline.Metrics, latency float64) string {
+		if metrics.LatencyUnknown {
+			return ""
+		}
+		return formatFloat(latency)
+	}
+	for _, r := range records {
+		commonRow := []string{
+			r.RunID,
+			r.ServiceName,
+			r.ScenarioName,
+			r.ScenarioSubName,
+			r.ImageURL,
+			r.StartedAt.Format(time.RFC3339),
+			r.FinishedAt.Format(time.RFC3339),
+		}
+		row := append([]string{}, commonRow...)
+		if report := r.GatlingReport; report != nil {
+			row = append(row,
+				formatFloat(report.NumberOfRequests.Total),
+				formatFloat(report.MeanResponseTime.Ok),
+				formatFloat(report.MaxResponseTime.Ok),
+				formatPercentileLatency(report, 50),
+				formatPercentileLatency(report, 75),
+			)
+		} else {
+			row = append(row, "", "", "", "", "")
+		}
+		row = append(row,
+			formatMetricsLatency(r.Metrics, r.Metrics.P95Latency),
+			formatMetricsLatency(r.Metrics, r.Metrics.P99Latency),
+			formatFloat(r.Metrics.FailedPercentage),
+			formatFloat(r.Metrics.Throughput),
+			formatFloat(r.Container.CPUUsagePercent),
+			formatFloat(r.Container.MemoryUsagePercent),
+			formatFloat(r.Metrics.CPUPerRequest),
+			r.Comparison.Summary(),
+			r.LoadGeneratorState(),
+			"",
+		)
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+		for _, node := range r.GatlingStats.Flatten() {
+			requestRow := append([]string{}, commonRow...)
+			requestRow = append(requestRow,
+				formatFloat(node.Stats.NumberOfRequests.Total),
+				formatFloat(node.Stats.MeanResponseTime.Ok),
+				formatFloat(node.Stats.MaxResponseTime.Ok),
+				formatPercentileLatency(&node.Stats, 50),
+				formatPercentileLatency(&node.Stats, 75),
+				formatPercentileLatency(&node.Stats, 95),
+				formatPercentileLatency(&node.Stats, 99),
+				formatFloat(node.Stats.Failed.Percentage),
+				formatFloat(node.Stats.MeanNumberOfRequestsPerSecond.Total),
+				"", "", "", "", "",
+				node.Path,
+			)
+			if err := csvWriter.Write(requestRow); err != nil {
+				return err
+			}
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
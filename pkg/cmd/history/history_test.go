@@ -0,0 +1,178 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	historyTools "github.com/st-tech/gatling-commander/pkg/internal/history"
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const RunsDir = "testdata/runs"
+
+func TestRunList(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        historyTools.Filter
+		expectedLines int
+	}{
+		{
+			name:          "list all results",
+			filter:        historyTools.Filter{},
+			expectedLines: 2,
+		},
+		{
+			name:          "no result matches filter",
+			filter:        historyTools.Filter{ServiceName: "other-service"},
+			expectedLines: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runList(&out, RunsDir, tt.filter)
+			assert.NoError(t, err)
+			lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+			assert.Equal(t, tt.expectedLines, len(lines))
+			assert.Contains(t, string(lines[0]), "RUN ID")
+			if tt.expectedLines > 1 {
+				assert.Contains(t, string(lines[1]), "202311131050-1a2b")
+				assert.Contains(t, string(lines[1]), "no baseline")
+				assert.Contains(t, string(lines[1]), "no slo")
+			}
+		})
+	}
+}
+
+func TestRunShow(t *testing.T) {
+	var out bytes.Buffer
+	err := runShow(&out, RunsDir, historyTools.Filter{RunID: "202311131050-1a2b"})
+	assert.NoError(t, err)
+	var result showResult
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "202311131050-1a2b", result.Run.RunID)
+	assert.Equal(t, 1, len(result.Records))
+	assert.Contains(t, string(result.Config), "gatling-cluster-context-name")
+
+	err = runShow(&out, RunsDir, historyTools.Filter{RunID: "202311131050-ffff"})
+	assert.Error(t, err)
+}
+
+func TestRunExport(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+		err      error
+	}{
+		{
+			name:   "export as csv",
+			format: exportFormatCSV,
+			expected: "runID,serviceName,scenarioName,scenarioSubName,imageURL,startedAt,finishedAt,requests," +
+				"meanLatency,maxLatency,p50Latency,p75Latency,p95Latency,p99Latency,failedPercentage,throughput," +
+				"cpuUsagePercent,memoryUsagePercent,cpuPerRequest,baseline,loadGenerator,request\n" +
+				"202311131050-1a2b,sample-service,sample-scenario,1,example/gatling-scenario/sample-202311131050," +
+				"2023-11-13T10:50:00Z,2023-11-13T10:54:00Z,6000,40,900,30,50,90,120,0.1,25,25,10,10,no baseline,,\n" +
+				"202311131050-1a2b,sample-service,sample-scenario,1,example/gatling-scenario/sample-202311131050," +
+				"2023-11-13T10:50:00Z,2023-11-13T10:54:00Z,6000,40,900,30,50,90,120,0.1,25,,,,,,home\n",
+		},
+		{
+			name:   "unsupported format",
+			format: "xml",
+			err:    fmt.Errorf("unsupported export format xml, specify json or csv"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runExport(&out, RunsDir, historyTools.Filter{}, tt.format)
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				assert.Equal(t, tt.expected, out.String())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	err := runExport(&out, RunsDir, historyTools.Filter{}, exportFormatJSON)
+	assert.NoError(t, err)
+	var records []historyTools.Record
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, float64(120), records[0].GatlingReport.NintyNinthPercentiles.Ok)
+}
+
+func TestRunExport_CustomizedPercentiles(t *testing.T) {
+	runsDir := t.TempDir()
+	startedAt := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	report := &gatlingTools.GatlingReport{
+		FiftiethPercentiles:     gatlingTools.GatlingReportStats{Ok: 30},
+		SeventyFifthPercentiles: gatlingTools.GatlingReportStats{Ok: 80},
+		NintyFifthPercentiles:   gatlingTools.GatlingReportStats{Ok: 120},
+		NintyNinthPercentiles:   gatlingTools.GatlingReportStats{Ok: 200},
+		// percentiles customized by gatling.conf, p75 and p95 are not in the report.
+		Percentiles: []float64{50, 90, 99, 99.9},
+	}
+	_, err := historyTools.WriteRecord(filepath.Join(runsDir, "202311131050-1a2b"), historyTools.Record{
+		RunID:         "202311131050-1a2b",
+		ServiceName:   "sample-service",
+		ScenarioName:  "sample-scenario",
+		StartedAt:     startedAt,
+		FinishedAt:    startedAt.Add(4 * time.Minute),
+		Gatling:       &gatlingv1alpha1.Gatling{ObjectMeta: metav1.ObjectMeta{Name: "sample-service-sample-scenario"}},
+		GatlingReport: report,
+		Metrics:       baseline.NewMetrics(report, 0),
+	})
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, runExport(&out, runsDir, historyTools.Filter{}, exportFormatCSV))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	// requests, meanLatency, maxLatency, p50Latency, p75Latency, p95Latency and p99Latency follow finishedAt.
+	assert.Contains(t, lines[1], "2023-11-13T10:54:00Z,0,0,0,30,,,,0,0")
+}
+
+func TestFilterFlags(t *testing.T) {
+	flags := filterFlags{from: "2023-11-13", to: "2023-11-13"}
+	filter, err := flags.filter()
+	assert.NoError(t, err)
+	// to date is inclusive.
+	assert.Equal(t, filter.From.AddDate(0, 0, 1), filter.To)
+
+	flags = filterFlags{from: "2023/11/13"}
+	_, err = flags.filter()
+	assert.Error(t, err)
+}
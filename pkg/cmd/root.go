@@ -32,6 +32,8 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/st-tech/gatling-commander/pkg/cmd/exec"
+	"github.com/st-tech/gatling-commander/pkg/cmd/history"
+	"github.com/st-tech/gatling-commander/pkg/cmd/validate"
 	cfg "github.com/st-tech/gatling-commander/pkg/config"
 )
 
@@ -118,6 +120,10 @@ func NewGatlingCommanderCommand(o gatlingCommanderOptions) *cobra.Command {
 			os.Exit(1)
 		}
 
+		if foundCmd, _, err := cmds.Find(o.Arguments[1:]); err == nil && skipFieldValueValidation(foundCmd) {
+			return
+		}
+
 		if err := config.ValidateFieldValue(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: invalid config param %v\n", err)
 			os.Exit(1)
@@ -125,5 +131,23 @@ func NewGatlingCommanderCommand(o gatlingCommanderOptions) *cobra.Command {
 	})
 	cmds.CompletionOptions.DisableDefaultCmd = true
 	cmds.AddCommand(exec.NewCmdExec(rootCmdName, &config))
+	cmds.AddCommand(validate.NewCmdValidate(rootCmdName, &config))
+	cmds.AddCommand(history.NewCmdHistory(rootCmdName, &config))
 	return cmds
 }
+
+/*
+skipFieldValueValidation returns whether config validation in root command is skipped for the command.
+
+validate command reports every problem by itself, so skip validation which stops at the first error.
+history command only reads runsDir, so it can be used even if config is not valid for exec command.
+*/
+func skipFieldValueValidation(cmd *cobra.Command) bool {
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		// check the subcommand which is direct child of root command.
+		if !cmd.Parent().HasParent() {
+			return cmd.Name() == validate.CmdName || cmd.Name() == history.CmdName
+		}
+	}
+	return false
+}
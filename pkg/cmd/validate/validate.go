@@ -0,0 +1,154 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package validate implements command which preflights config.yaml before load test is launched.
+package validate
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	kubeapiTools "github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+
+	"github.com/spf13/cobra"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CmdName is name of validate command. root command skip its own config validation for this command.
+const CmdName = "validate"
+
+type validateFlags struct {
+	skipCluster bool
+}
+
+// clientInitializer returns kubeapi client of specified context name.
+type clientInitializer func(k8sCtxName string) (ctrlClient.Client, error)
+
+func newValidateFlags() *validateFlags {
+	f := &validateFlags{}
+	return f
+}
+
+func (f *validateFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.skipCluster, "skip-cluster", false, "skip checks which access Kubernetes clusters")
+}
+
+// NewCmdValidate creates the `validate` command.
+func NewCmdValidate(baseName string, config *cfg.Config) *cobra.Command {
+	flags := newValidateFlags()
+
+	cmd := &cobra.Command{
+		Use:   CmdName,
+		Short: "Check configuration and clusters before load test is launched",
+		Long: `The validate command load configuration file which has specified path with config arguments.
+		And run all static checks of configuration and check clusters which load test use.
+		Every problem found is reported at once, so fix them and run this command again.
+		Complete documentation is available at https://github.com/st-tech/gatling-commander/docs`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(cmd.Context(), cmd.OutOrStdout(), config, flags, kubeapiTools.InitClient)
+		},
+	}
+
+	flags.addFlags(cmd)
+	return cmd
+}
+
+// runValidate run static checks and cluster checks, and print all found problems.
+func runValidate(
+	ctx context.Context,
+	w io.Writer,
+	config *cfg.Config,
+	flags *validateFlags,
+	initClient clientInitializer,
+) error {
+	problems := config.ValidateStatic()
+	if !flags.skipCluster {
+		problems = append(problems, checkClusters(ctx, config, initClient)...)
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "config is valid, no problem found\n")
+		return nil
+	}
+	fmt.Fprintf(w, "%v problems found\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  - %v\n", problem)
+	}
+	return fmt.Errorf("config validation failed with %v problems", len(problems))
+}
+
+/*
+checkClusters returns problems found in clusters which load test use.
+
+Check items are below.
+  - gatlingContextName and contextName of each target can be resolved
+  - Gatling CRD is installed in the cluster of gatlingContextName
+  - target pods and container are found by each target
+*/
+func checkClusters(ctx context.Context, config *cfg.Config, initClient clientInitializer) []error {
+	var problems []error
+	clients := make(map[string]ctrlClient.Client)
+	// getClient initialize client once per context, and record problem only at first failure.
+	getClient := func(k8sCtxName string) (ctrlClient.Client, bool) {
+		if cl, exist := clients[k8sCtxName]; exist {
+			return cl, cl != nil
+		}
+		cl, err := initClient(k8sCtxName)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("context %v can not be resolved, %v", k8sCtxName, err))
+			cl = nil
+		}
+		clients[k8sCtxName] = cl
+		return cl, cl != nil
+	}
+
+	if config.GatlingContextName != "" {
+		if cl, ok := getClient(config.GatlingContextName); ok {
+			// namespace of Gatling object is only set in base manifest, static checks report the case it can't be loaded.
+			if gatling, err := gatlingTools.LoadGatlingManifest(config.BaseManifest); err == nil {
+				if err := gatlingTools.CheckGatlingCRDInstalled(ctx, cl, gatling.ObjectMeta.Namespace); err != nil {
+					problems = append(problems, err)
+				}
+			}
+		}
+	}
+
+	for _, service := range config.Services {
+		for _, podConfig := range service.TargetPodConfigs() {
+			if podConfig.ContextName == "" {
+				continue
+			}
+			cl, ok := getClient(podConfig.ContextName)
+			if !ok {
+				continue
+			}
+			if _, err := kubeapiTools.FetchContainerResourcesLimit(ctx, cl, podConfig); err != nil {
+				problems = append(problems, fmt.Errorf(
+					"service %v target %v pod not found, %v", service.Name, podConfig.Name, err,
+				))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,185 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	GatlingContextName   = "gatling-context"
+	TargetPodContextName = "target-pod-context"
+	UnknownContextName   = "unknown-context"
+)
+
+func newSampleConfig() cfg.Config {
+	return cfg.Config{
+		GatlingContextName:   GatlingContextName,
+		ImageRepository:      "example/gatling-scenario",
+		ImagePrefix:          "sample",
+		ImageURL:             "example/gatling-scenario:sample",
+		GatlingDockerfileDir: "testdata",
+		BaseManifest:         "testdata/base_manifest.yaml",
+		StartupTimeoutSec:    1800,
+		ExecTimeoutSec:       10800,
+		Services: []cfg.Service{
+			{
+				Name:          "sample-service",
+				SpreadsheetId: "sample-id",
+				TargetPodConfig: cfg.TargetPodConfig{
+					ContextName:   TargetPodContextName,
+					Namespace:     "sample-namespace",
+					LabelKey:      "app",
+					LabelValue:    "sample-app",
+					ContainerName: "sample-container",
+				},
+				ScenarioSpecs: []cfg.ScenarioSpec{
+					{
+						Name:    "sample-scenario",
+						SubName: "10rps",
+						TestScenarioSpec: gatlingv1alpha1.TestScenarioSpec{
+							SimulationClass: "SampleSimulation",
+							Parallelism:     1,
+							Env: []corev1.EnvVar{
+								{Name: "CONCURRENCY", Value: "10"},
+								{Name: "DURATION", Value: "180"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	samplePod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sample-pod",
+			Namespace: "sample-namespace",
+			Labels: map[string]string{
+				"app": "sample-app",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "sample-container",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: "Running",
+		},
+	}
+	err := cl.Create(context.TODO(), &samplePod)
+	assert.NoError(t, err)
+	initClient := func(k8sCtxName string) (ctrlClient.Client, error) {
+		if k8sCtxName == UnknownContextName {
+			return nil, fmt.Errorf("context %v does not exist", k8sCtxName)
+		}
+		return cl, nil
+	}
+
+	validConfig := newSampleConfig()
+	unknownContextConfig := newSampleConfig()
+	unknownContextConfig.Services[0].TargetPodConfig.ContextName = UnknownContextName
+	podNotFoundConfig := newSampleConfig()
+	podNotFoundConfig.Services[0].TargetPodConfig.LabelValue = "not-exists-app"
+	multiProblemsConfig := newSampleConfig()
+	multiProblemsConfig.GatlingContextName = UnknownContextName
+	multiProblemsConfig.ExecTimeoutSec = 60
+	multiProblemsConfig.Services[0].TargetPodConfig.ContainerName = "not-exists-container"
+
+	tests := []struct {
+		name           string
+		config         cfg.Config
+		flags          validateFlags
+		expectedOutput string
+		expectedErr    error
+	}{
+		{
+			name:           "no problem found",
+			config:         validConfig,
+			expectedOutput: "config is valid, no problem found\n",
+		},
+		{
+			name:   "target pod context can not be resolved",
+			config: unknownContextConfig,
+			expectedOutput: fmt.Sprintf(
+				"1 problems found\n  - context %v can not be resolved, context %v does not exist\n",
+				UnknownContextName,
+				UnknownContextName,
+			),
+			expectedErr: fmt.Errorf("config validation failed with 1 problems"),
+		},
+		{
+			name:   "target pod not found",
+			config: podNotFoundConfig,
+			expectedOutput: "1 problems found\n" +
+				"  - service sample-service target sample-container pod not found, no match pods to specified label\n",
+			expectedErr: fmt.Errorf("config validation failed with 1 problems"),
+		},
+		{
+			name:        "all problems are reported",
+			config:      multiProblemsConfig,
+			expectedErr: fmt.Errorf("config validation failed with 3 problems"),
+		},
+		{
+			name:           "cluster checks skipped",
+			config:         unknownContextConfig,
+			flags:          validateFlags{skipCluster: true},
+			expectedOutput: "config is valid, no problem found\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runValidate(context.TODO(), &out, &tt.config, &tt.flags, initClient)
+			assert.Equal(t, tt.expectedErr, err)
+			if tt.expectedOutput != "" {
+				assert.Equal(t, tt.expectedOutput, out.String())
+			}
+		})
+	}
+}
@@ -27,23 +27,55 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
 	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
 	"github.com/st-tech/gatling-commander/pkg/util"
 )
 
 // Config map config/config.yaml field value.
 type Config struct {
-	GatlingContextName   string      `yaml:"gatlingContextName"`
-	ImageRepository      string      `yaml:"imageRepository"`
-	ImagePrefix          string      `yaml:"imagePrefix"`
-	ImageURL             string      `yaml:"imageURL"`
-	GatlingDockerfileDir string      `yaml:"gatlingDockerfileDir"`
-	BaseManifest         string      `yaml:"baseManifest"`
-	StartupTimeoutSec    int32       `yaml:"startupTimeoutSec"`
-	ExecTimeoutSec       int32       `yaml:"execTimeoutSec"`
-	SlackConfig          SlackConfig `yaml:"slackConfig"`
-	Services             []Service   `yaml:"services"`
+	GatlingContextName   string                  `yaml:"gatlingContextName"`
+	ImageRepository      string                  `yaml:"imageRepository"`
+	ImagePrefix          string                  `yaml:"imagePrefix"`
+	ImageURL             string                  `yaml:"imageURL"`
+	GatlingDockerfileDir string                  `yaml:"gatlingDockerfileDir"`
+	BaseManifest         string                  `yaml:"baseManifest"`
+	StartupTimeoutSec    int32                   `yaml:"startupTimeoutSec"`
+	ExecTimeoutSec       int32                   `yaml:"execTimeoutSec"`
+	RunsDir              string                  `yaml:"runsDir"`
+	CloudStorage         CloudStorageConfig      `yaml:"cloudStorage"`
+	RunnerSaturation     *RunnerSaturationConfig `yaml:"runnerSaturation"`
+	SlackConfig          SlackConfig             `yaml:"slackConfig"`
+	Services             []Service               `yaml:"services"`
+}
+
+// MetricsProviderPrometheus is metricsProvider type which queries Prometheus HTTP API.
+const MetricsProviderPrometheus = "prometheus"
+
+// DefaultRunsDir is directory in which run directories are created when runsDir is not specified.
+const DefaultRunsDir = ".gatling-commander/runs"
+
+/*
+RunDir returns directory path to store files of run which has runID, such as run-state file.
+
+Run directories are created under runsDir, or DefaultRunsDir when it is not specified.
+*/
+func (c *Config) RunDir(runID string) string {
+	return filepath.Join(c.GetRunsDir(), runID)
+}
+
+// GetRunsDir returns runsDir, or DefaultRunsDir when it is not specified.
+func (c *Config) GetRunsDir() string {
+	if c.RunsDir == "" {
+		return DefaultRunsDir
+	}
+	return c.RunsDir
 }
 
 /*
@@ -54,62 +86,180 @@ Check items are below.
   - each of Service object field required value is set
   - each of TargetPodConfig object is valid
   - Service objects TargetPercentile and TargetLatency fields value are valid
+  - Service objects Requests field value is valid
+
+Only the first problem found is returned. Use ValidateStatic to get all of them.
 */
 func (c *Config) ValidateFieldValue() error {
+	if errs := c.fieldValueErrors(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+/*
+ValidateStatic returns every problem found in config without accessing any cluster.
+
+In addition to ValidateFieldValue check items, check items are below.
+  - baseManifest file can be loaded as Gatling object
+  - Dockerfile exists in gatlingDockerfileDir when imageURL is not specified
+  - each ScenarioSpec has simulationClass
+  - each ScenarioSpec CONCURRENCY env value can be parsed
+  - each ScenarioSpec DURATION env value is not longer than execTimeoutSec
+*/
+func (c *Config) ValidateStatic() []error {
+	errs := c.fieldValueErrors()
+	if c.BaseManifest != "" {
+		if _, err := gatling.LoadGatlingManifest(c.BaseManifest); err != nil {
+			errs = append(errs, fmt.Errorf("config param baseManifest %v can not be loaded, %v", c.BaseManifest, err))
+		}
+	}
+	if c.ImageURL == "" && c.GatlingDockerfileDir != "" {
+		dockerfilePath := filepath.Join(c.GatlingDockerfileDir, "Dockerfile")
+		if _, err := os.Stat(dockerfilePath); err != nil {
+			errs = append(errs, fmt.Errorf("config param gatlingDockerfileDir has no Dockerfile, %v", err))
+		}
+	}
+	for _, service := range c.Services {
+		for _, scenarioSpec := range service.ScenarioSpecs {
+			for _, err := range validateScenarioSpec(scenarioSpec, c.ExecTimeoutSec) {
+				errs = append(errs, fmt.Errorf(
+					"service %v scenario %v%v: %v", service.Name, scenarioSpec.Name, scenarioSpec.SubName, err,
+				))
+			}
+		}
+	}
+	return errs
+}
+
+// fieldValueErrors returns all errors of ValidateFieldValue check items in order of check.
+func (c *Config) fieldValueErrors() []error {
+	var errs []error
 	if c.GatlingContextName == "" {
-		return fmt.Errorf("config param gatlingContextName is required")
+		errs = append(errs, fmt.Errorf("config param gatlingContextName is required"))
 	}
 	if c.ImageRepository == "" {
-		return fmt.Errorf("config param imageRepostory is required")
+		errs = append(errs, fmt.Errorf("config param imageRepostory is required"))
 	}
 	if c.ImagePrefix == "" {
-		return fmt.Errorf("config param imagePrefix is required")
+		errs = append(errs, fmt.Errorf("config param imagePrefix is required"))
 	}
 	if c.GatlingDockerfileDir == "" {
-		return fmt.Errorf("config param gatlingDockerfileDir is required")
+		errs = append(errs, fmt.Errorf("config param gatlingDockerfileDir is required"))
 	}
 	if c.BaseManifest == "" {
-		return fmt.Errorf("config param baseManifest is required")
+		errs = append(errs, fmt.Errorf("config param baseManifest is required"))
 	}
 	if c.StartupTimeoutSec == 0 {
-		return fmt.Errorf("config param startupTimeout is required")
+		errs = append(errs, fmt.Errorf("config param startupTimeout is required"))
 	}
 	if c.ExecTimeoutSec == 0 {
-		return fmt.Errorf("config param execTimeout is required")
+		errs = append(errs, fmt.Errorf("config param execTimeout is required"))
 	}
+	if c.RunnerSaturation != nil {
+		if err := validateRunnerSaturationField(*c.RunnerSaturation); err != nil {
+			errs = append(errs, fmt.Errorf("config param runnerSaturation field value is invalid %v", err))
+		}
+	}
+	reportPercentiles := c.reportPercentiles()
 	serviceNames := make([]string, 0, len(c.Services))
 	for _, service := range c.Services {
 		if service.Name == "" {
-			return fmt.Errorf("config param service[].name is required")
+			errs = append(errs, fmt.Errorf("config param service[].name is required"))
 		}
 		if service.SpreadsheetId == "" {
-			return fmt.Errorf("config param service[].spreadsheetID is required")
+			errs = append(errs, fmt.Errorf("config param service[].spreadsheetID is required"))
 		}
-		err := validateGetTargetPodRequiredField(service.TargetPodConfig)
-		if err != nil {
-			return fmt.Errorf("config param filter target pod param is invalid %v", err)
+		if err := validateTargetsField(service); err != nil {
+			errs = append(errs, fmt.Errorf("config param filter target pod param is invalid %v", err))
 		}
-		err = validateTargetLatencyField(service.TargetPercentile, service.TargetLatency)
+		err := validateTargetLatencyField(service.TargetPercentile, service.TargetLatency)
 		if err != nil {
-			return fmt.Errorf("config param check latency field value is invalid %v", err)
+			errs = append(errs, fmt.Errorf("config param check latency field value is invalid %v", err))
+		}
+		if err := validateRequestsField(service.Requests); err != nil {
+			errs = append(errs, fmt.Errorf("config param requests field value is invalid %v", err))
+		}
+		if err := validatePercentilesField(service, reportPercentiles); err != nil {
+			errs = append(errs, fmt.Errorf("config param percentile field value is invalid %v", err))
+		}
+		if service.Search != nil {
+			if err := validateSearchField(*service.Search, service.ScenarioSpecs); err != nil {
+				errs = append(errs, fmt.Errorf("config param search field value is invalid %v", err))
+			}
+		}
+		if service.Baseline != nil {
+			if err := validateBaselineField(*service.Baseline); err != nil {
+				errs = append(errs, fmt.Errorf("config param baseline field value is invalid %v", err))
+			}
+		}
+		if service.AbortRules != nil {
+			if err := validateAbortRulesField(*service.AbortRules); err != nil {
+				errs = append(errs, fmt.Errorf("config param abortRules field value is invalid %v", err))
+			}
+		}
+		if service.MetricsProvider != nil {
+			if err := validateMetricsProviderField(*service.MetricsProvider); err != nil {
+				errs = append(errs, fmt.Errorf("config param metricsProvider field value is invalid %v", err))
+			}
+		}
+		if err := validateSLOField(service); err != nil {
+			errs = append(errs, fmt.Errorf("config param slo field value is invalid %v", err))
+		}
+		if service.SimulationLog != nil && service.SimulationLog.WarmUpSec < 0 {
+			errs = append(errs, fmt.Errorf("config param simulationLog field value is invalid warmUpSec must not be negative"))
 		}
 		serviceNames = append(serviceNames, service.Name)
 		scenarioSpecNames := make([]string, 0, len(service.ScenarioSpecs))
 		for _, scenarioSpec := range service.ScenarioSpecs {
-			scenarioSpecNames = append(scenarioSpecNames, scenarioSpec.Name+scenarioSpec.SubName)
+			// Scenarios are distinguished by the sanitized name, since it is used in Gatling object name and labels.
+			scenarioSpecNames = append(
+				scenarioSpecNames, gatling.SanitizeScenarioName(scenarioSpec.Name, scenarioSpec.SubName),
+			)
 		}
 		if err := util.CheckDuplicate(scenarioSpecNames); err != nil {
-			return fmt.Errorf("%v, config.yaml scenarioSpec name duplicated in service %v", err, service.Name)
+			errs = append(errs, fmt.Errorf("%v, config.yaml scenarioSpec name duplicated in service %v", err, service.Name))
 		}
 	}
 	if err := util.CheckDuplicate(serviceNames); err != nil {
-		return fmt.Errorf("%v config.yaml service name duplicated", err)
+		errs = append(errs, fmt.Errorf("%v config.yaml service name duplicated", err))
 	}
-	return nil
+	return errs
+}
+
+/*
+validateScenarioSpec returns problems of ScenarioSpec which only show up after Gatling object created.
+
+DURATION env value is treated as seconds, and compared with execTimeoutSec.
+*/
+func validateScenarioSpec(scenarioSpec ScenarioSpec, execTimeoutSec int32) []error {
+	var errs []error
+	if scenarioSpec.TestScenarioSpec.SimulationClass == "" {
+		errs = append(errs, fmt.Errorf("testScenarioSpec.simulationClass is required"))
+	}
+	if _, _, _, err := gatling.ExtractLoadtestConditionToReport(scenarioSpec.TestScenarioSpec); err != nil {
+		errs = append(errs, fmt.Errorf("failed to parse CONCURRENCY env value, %v", err))
+	}
+	for _, env := range scenarioSpec.TestScenarioSpec.Env {
+		if env.Name != "DURATION" {
+			continue
+		}
+		duration, err := strconv.ParseFloat(env.Value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse DURATION env value as seconds, %v", err))
+			continue
+		}
+		if duration > float64(execTimeoutSec) {
+			errs = append(errs, fmt.Errorf(
+				"DURATION env value %v is longer than execTimeoutSec %v", env.Value, execTimeoutSec,
+			))
+		}
+	}
+	return errs
 }
 
 // validateTargetLatencyField validate config.yaml target latency field value.
-func validateTargetLatencyField(percentile uint32, latency float64) error {
+func validateTargetLatencyField(percentile float64, latency float64) error {
 	if latency < float64(0) {
 		return fmt.Errorf("invalid latency value specified, it must be more than 0")
 	}
@@ -120,8 +270,13 @@ func validateTargetLatencyField(percentile uint32, latency float64) error {
 	return nil
 }
 
-// checkTargetLatencyRequiredField check required field for check target latency.
-func checkTargetLatencyRequiredField(percentile uint32, latency float64) error {
+/*
+checkTargetLatencyRequiredField check required field for check target latency.
+
+Percentile is not limited to percentiles of gatling report here, it is checked with gatling.conf and simulationLog
+field by validatePercentilesField.
+*/
+func checkTargetLatencyRequiredField(percentile float64, latency float64) error {
 	if percentile == 0 && latency == 0 { // case: config.yaml targetPercentile & targetLatency value is empty
 		return nil
 	}
@@ -129,10 +284,302 @@ func checkTargetLatencyRequiredField(percentile uint32, latency float64) error {
 	if percentile == 0 || latency == 0 {
 		return fmt.Errorf("percentile must be set with latency, one of these is empty")
 	}
-	gatlingReport := &gatling.GatlingReport{}
-	if _, err := gatlingReport.GetPercentileLatency(percentile); err != nil { // validate specified percentile value
+	if percentile < 0 || percentile > 100 {
+		return fmt.Errorf("percentile must be more than 0 and not more than 100")
+	}
+	return nil
+}
+
+/*
+reportPercentiles returns percentiles of gatling report configured in gatling.conf of gatlingDockerfileDir.
+
+nil is returned when gatling.conf can not be read, then percentiles are not validated statically and they are checked
+with the report after loadtest.
+*/
+func (c *Config) reportPercentiles() []float64 {
+	if c.GatlingDockerfileDir == "" {
+		return nil
+	}
+	percentiles, err := gatling.LoadConfPercentiles(filepath.Join(c.GatlingDockerfileDir, "conf", "gatling.conf"))
+	if err != nil {
+		return nil
+	}
+	return percentiles
+}
+
+/*
+validatePercentilesField validate percentiles of service can be read from gatling report or simulation.log.
+
+Gatling report has latency of reportPercentiles configured in gatling.conf, and latency of the other percentiles is
+calculated from simulation.log of all requests. So check items are below, so that they don't fail after loadtest
+finished. Nothing is checked when reportPercentiles is nil, since they are unknown until loadtest finished.
+  - targetPercentile and percentiles of slo assertions other than the report ones require simulationLog
+  - targetPercentile of each request is one of the report ones, since stats of each request are read from the report
+*/
+func validatePercentilesField(service Service, reportPercentiles []float64) error {
+	if reportPercentiles == nil {
+		return nil
+	}
+	isReportPercentile := func(percentile float64) bool {
+		return slices.Contains(reportPercentiles, percentile)
+	}
+	if service.SimulationLog == nil && service.TargetPercentile != 0 && !isReportPercentile(service.TargetPercentile) {
+		return fmt.Errorf(
+			"targetPercentile %v requires simulationLog, percentiles of gatling report are %v",
+			service.TargetPercentile,
+			reportPercentiles,
+		)
+	}
+	for _, request := range service.Requests {
+		if request.TargetPercentile != 0 && !isReportPercentile(request.TargetPercentile) {
+			return fmt.Errorf(
+				"request %v targetPercentile %v must be one of percentiles of gatling report %v",
+				request.Name,
+				request.TargetPercentile,
+				reportPercentiles,
+			)
+		}
+	}
+	if service.SimulationLog != nil {
+		return nil
+	}
+	for _, scenarioSpec := range service.ScenarioSpecs {
+		// invalid assertions are reported by validateSLOField.
+		assertions, _ := MergeSLO(service.SLO, scenarioSpec.SLO).ParseAssertions()
+		for _, percentile := range slo.Percentiles(assertions) {
+			if !isReportPercentile(percentile) {
+				return fmt.Errorf(
+					"scenarioSpec %v slo percentile %v requires simulationLog, percentiles of gatling report are %v",
+					scenarioSpec.Name,
+					percentile,
+					reportPercentiles,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+validateRequestsField validate config.yaml requests field value of service.
+
+Check items are below.
+  - each request has unique name
+  - targetPercentile and targetLatency of each request are valid
+*/
+func validateRequestsField(requests []RequestTargetConfig) error {
+	requestNames := make([]string, 0, len(requests))
+	for _, request := range requests {
+		if request.Name == "" {
+			return fmt.Errorf("requests field name is required")
+		}
+		if err := validateTargetLatencyField(request.TargetPercentile, request.TargetLatency); err != nil {
+			return fmt.Errorf("request %v %v", request.Name, err)
+		}
+		requestNames = append(requestNames, request.Name)
+	}
+	if err := util.CheckDuplicate(requestNames); err != nil {
+		return fmt.Errorf("%v, requests name duplicated", err)
+	}
+	return nil
+}
+
+/*
+validateSLOField validate config.yaml slo field value of service and its scenarioSpecs.
+
+Check items are below.
+  - each assertion is valid, and its severity is one of stop, fail-run and warn
+  - targetRPS is not negative
+  - targetRPS is set to service or scenarioSpec when its assertion is compared with target rps
+*/
+func validateSLOField(service Service) error {
+	if _, err := service.SLO.ParseAssertions(); err != nil {
 		return err
 	}
+	for _, scenarioSpec := range service.ScenarioSpecs {
+		sloConfig := MergeSLO(service.SLO, scenarioSpec.SLO)
+		if sloConfig == nil {
+			continue
+		}
+		if sloConfig.TargetRPS < 0 {
+			return fmt.Errorf("scenarioSpec %v targetRPS must not be negative", scenarioSpec.Name)
+		}
+		assertions, err := sloConfig.ParseAssertions()
+		if err != nil {
+			return fmt.Errorf("scenarioSpec %v %v", scenarioSpec.Name, err)
+		}
+		for _, assertion := range assertions {
+			if assertion.OfTarget && sloConfig.TargetRPS == 0 {
+				return fmt.Errorf("scenarioSpec %v assertion %q requires targetRPS", scenarioSpec.Name, assertion.Expr)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+validateSearchField validate config.yaml search field value.
+
+Check items are below.
+  - strategy, startConcurrency, maxConcurrency and step are valid as capacity search parameters
+  - errorBudgetPercent and cpuCeilingPercent are between 0 and 100
+  - service has exactly one ScenarioSpec which has CONCURRENCY env, it is used as template of loadtests
+*/
+func validateSearchField(search SearchConfig, scenarioSpecs []ScenarioSpec) error {
+	if err := capacity.ValidateParams(
+		capacity.Strategy(search.Strategy), search.StartConcurrency, search.MaxConcurrency, search.Step,
+	); err != nil {
+		return err
+	}
+	if search.ErrorBudgetPercent < 0 || search.ErrorBudgetPercent > 100 {
+		return fmt.Errorf("errorBudgetPercent must be between 0 and 100")
+	}
+	if search.CPUCeilingPercent < 0 || search.CPUCeilingPercent > 100 {
+		return fmt.Errorf("cpuCeilingPercent must be between 0 and 100")
+	}
+	if len(scenarioSpecs) != 1 {
+		return fmt.Errorf("search requires exactly one scenarioSpec as template, got %v", len(scenarioSpecs))
+	}
+	for _, env := range scenarioSpecs[0].TestScenarioSpec.Env {
+		if env.Name == "CONCURRENCY" {
+			return nil
+		}
+	}
+	return fmt.Errorf("scenarioSpec used as search template has no CONCURRENCY env")
+}
+
+// validateBaselineField validate config.yaml baseline field value, each threshold must not be negative.
+func validateBaselineField(baseline BaselineConfig) error {
+	thresholds := []struct {
+		name  string
+		value float64
+	}{
+		{name: "p95IncreasePercent", value: baseline.P95IncreasePercent},
+		{name: "p99IncreasePercent", value: baseline.P99IncreasePercent},
+		{name: "failedIncreasePoint", value: baseline.FailedIncreasePoint},
+		{name: "throughputDecreasePercent", value: baseline.ThroughputDecreasePercent},
+		{name: "cpuPerRequestIncreasePercent", value: baseline.CPUPerRequestIncreasePercent},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 {
+			return fmt.Errorf("%v must not be negative", threshold.name)
+		}
+	}
+	return nil
+}
+
+/*
+validateAbortRulesField validate config.yaml abortRules field value.
+
+Check items are below.
+  - cpuPercent and memoryPercent are between 0 and 100
+  - sustainedSec, maxRestarts and maxRunnerErrors are not negative
+*/
+func validateAbortRulesField(abortRules AbortRulesConfig) error {
+	if abortRules.CPUPercent < 0 || abortRules.CPUPercent > 100 {
+		return fmt.Errorf("cpuPercent must be between 0 and 100")
+	}
+	if abortRules.MemoryPercent < 0 || abortRules.MemoryPercent > 100 {
+		return fmt.Errorf("memoryPercent must be between 0 and 100")
+	}
+	if abortRules.SustainedSec < 0 {
+		return fmt.Errorf("sustainedSec must not be negative")
+	}
+	if abortRules.MaxRestarts != nil && *abortRules.MaxRestarts < 0 {
+		return fmt.Errorf("maxRestarts must not be negative")
+	}
+	if abortRules.MaxRunnerErrors != nil && *abortRules.MaxRunnerErrors < 0 {
+		return fmt.Errorf("maxRunnerErrors must not be negative")
+	}
+	return nil
+}
+
+/*
+validateRunnerSaturationField validate config.yaml runnerSaturation field value.
+
+Check items are below.
+  - cpuPercent and memoryPercent are between 0 and 100
+  - at least one of them is set
+*/
+func validateRunnerSaturationField(runnerSaturation RunnerSaturationConfig) error {
+	if runnerSaturation.CPUPercent < 0 || runnerSaturation.CPUPercent > 100 {
+		return fmt.Errorf("cpuPercent must be between 0 and 100")
+	}
+	if runnerSaturation.MemoryPercent < 0 || runnerSaturation.MemoryPercent > 100 {
+		return fmt.Errorf("memoryPercent must be between 0 and 100")
+	}
+	if runnerSaturation.CPUPercent == 0 && runnerSaturation.MemoryPercent == 0 {
+		return fmt.Errorf("cpuPercent or memoryPercent is required")
+	}
+	return nil
+}
+
+/*
+validateMetricsProviderField validate config.yaml metricsProvider field value.
+
+Check items are below.
+  - type is prometheus
+  - address is set
+  - stepSec is not negative
+  - at least one query is set or targetUsage is true, and each query has unique name and query
+  - targetCPUQuery and targetMemoryQuery are set only with targetUsage
+*/
+func validateMetricsProviderField(metricsProvider MetricsProviderConfig) error {
+	if metricsProvider.Type != MetricsProviderPrometheus {
+		return fmt.Errorf("type must be %v", MetricsProviderPrometheus)
+	}
+	if metricsProvider.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if metricsProvider.StepSec < 0 {
+		return fmt.Errorf("stepSec must not be negative")
+	}
+	if len(metricsProvider.Queries) == 0 && !metricsProvider.TargetUsage {
+		return fmt.Errorf("queries or targetUsage is required")
+	}
+	if !metricsProvider.TargetUsage && (metricsProvider.TargetCPUQuery != "" || metricsProvider.TargetMemoryQuery != "") {
+		return fmt.Errorf("targetCPUQuery and targetMemoryQuery require targetUsage")
+	}
+	queryNames := make([]string, 0, len(metricsProvider.Queries))
+	for _, query := range metricsProvider.Queries {
+		if query.Name == "" || query.Query == "" {
+			return fmt.Errorf("queries field name and query are required")
+		}
+		queryNames = append(queryNames, query.Name)
+	}
+	if err := util.CheckDuplicate(queryNames); err != nil {
+		return fmt.Errorf("%v, queries name duplicated", err)
+	}
+	return nil
+}
+
+/*
+validateTargetsField validate config.yaml targetPodConfig and targets field value of service.
+
+Check items are below.
+  - targetPodConfig and targets are not specified together
+  - each target has unique name when targets is specified
+  - each target is valid
+*/
+func validateTargetsField(service Service) error {
+	if len(service.Targets) > 0 && service.TargetPodConfig != (TargetPodConfig{}) {
+		return fmt.Errorf("targetPodConfig and targets can not be specified together")
+	}
+	targetNames := make([]string, 0, len(service.Targets))
+	for _, podConfig := range service.Targets {
+		if podConfig.Name == "" {
+			return fmt.Errorf("targets field name is required")
+		}
+		targetNames = append(targetNames, podConfig.Name)
+	}
+	if err := util.CheckDuplicate(targetNames); err != nil {
+		return fmt.Errorf("%v, targets name duplicated", err)
+	}
+	for _, podConfig := range service.TargetPodConfigs() {
+		if err := validateGetTargetPodRequiredField(podConfig); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -141,6 +588,8 @@ validateGetTargetPodRequiredField validate config.yaml targetPodConfig field val
 
 Check items are below.
   - each of podConfig field value is set
+  - labelKey and labelValue can be omitted when labelSelector is specified
+  - labelSelector can be converted to selector
 */
 func validateGetTargetPodRequiredField(podConfig TargetPodConfig) error {
 	if podConfig.ContextName == "" {
@@ -149,12 +598,19 @@ func validateGetTargetPodRequiredField(podConfig TargetPodConfig) error {
 	if podConfig.Namespace == "" {
 		return fmt.Errorf("targetPod field namespace is required")
 	}
-	if podConfig.LabelKey == "" {
+	if podConfig.LabelKey == "" && podConfig.LabelSelector == nil {
 		return fmt.Errorf("targetPod field podLabelKey is required")
 	}
-	if podConfig.LabelValue == "" {
+	if podConfig.LabelKey != "" && podConfig.LabelValue == "" {
 		return fmt.Errorf("targetPod field podLabelValue is required")
 	}
+	selector, err := podConfig.Selector()
+	if err != nil {
+		return fmt.Errorf("targetPod field labelSelector is invalid, %v", err)
+	}
+	if selector.Empty() {
+		return fmt.Errorf("targetPod field labelSelector must not be empty")
+	}
 	if podConfig.ContainerName == "" {
 		return fmt.Errorf("targetPod field containerName is required")
 	}
@@ -25,15 +25,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jinzhu/copier"
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type targetLatencyField struct {
-	percentile uint32
+	percentile float64
 	latency    float64
 }
 
@@ -56,6 +61,8 @@ func TestValidateFieldValue(t *testing.T) {
 		noServiceNameField        Config
 		noSpreadsheetIdField      Config
 		scenarioSpecNameDuplicate Config
+		sanitizedNameDuplicate    Config
+		negativeWarmUpSec         Config
 	)
 	err := copier.CopyWithOption(&noServiceNameField, validConfig, copier.Option{
 		IgnoreEmpty: false,
@@ -72,6 +79,16 @@ func TestValidateFieldValue(t *testing.T) {
 		DeepCopy:    true,
 	})
 	assert.NoError(t, err)
+	err = copier.CopyWithOption(&sanitizedNameDuplicate, validConfig, copier.Option{
+		IgnoreEmpty: false,
+		DeepCopy:    true,
+	})
+	assert.NoError(t, err)
+	err = copier.CopyWithOption(&negativeWarmUpSec, validConfig, copier.Option{
+		IgnoreEmpty: false,
+		DeepCopy:    true,
+	})
+	assert.NoError(t, err)
 
 	noContextNameField.GatlingContextName = ""
 	noImgRepoField.ImageRepository = ""
@@ -82,6 +99,7 @@ func TestValidateFieldValue(t *testing.T) {
 	noExecTimeoutSecField.ExecTimeoutSec = 0
 	noServiceNameField.Services[0].Name = ""
 	noSpreadsheetIdField.Services[0].SpreadsheetId = ""
+	negativeWarmUpSec.Services[0].SimulationLog = &SimulationLogConfig{WarmUpSec: -1}
 	serviceNameDuplicate.Services = append(serviceNameDuplicate.Services, serviceNameDuplicate.Services[0])
 	duplicateServiceName := serviceNameDuplicate.Services[0].Name
 	serviceNameDuplicateErr := fmt.Errorf("duplicated value found %v\n", []string{duplicateServiceName})
@@ -94,9 +112,16 @@ func TestValidateFieldValue(t *testing.T) {
 	scenarioSpecNameDuplicateErr := fmt.Errorf(
 		"duplicated value found %v\n",
 		[]string{
-			scenarioSpecNameDuplicate.Services[0].ScenarioSpecs[0].Name + scenarioSpecNameDuplicate.Services[0].ScenarioSpecs[0].SubName, // nolint:lll
+			gatling.SanitizeScenarioName(
+				scenarioSpecNameDuplicate.Services[0].ScenarioSpecs[0].Name,
+				scenarioSpecNameDuplicate.Services[0].ScenarioSpecs[0].SubName,
+			),
 		},
 	)
+	// sample-test-scenario -5rps and Sample_Test_Scenario 5rps are the same Gatling object name.
+	sanitizedNameDuplicate.Services[0].ScenarioSpecs[1].Name = "Sample_Test_Scenario"
+	sanitizedNameDuplicate.Services[0].ScenarioSpecs[1].SubName = "5rps"
+	sanitizedNameDuplicateErr := fmt.Errorf("duplicated value found %v\n", []string{"sample-test-scenario-5rps"})
 
 	tests := []struct {
 		name     string
@@ -153,6 +178,11 @@ func TestValidateFieldValue(t *testing.T) {
 			config:   noSpreadsheetIdField,
 			expected: fmt.Errorf("config param service[].spreadsheetID is required"),
 		},
+		{
+			name:     "negative config service simulationLog warmUpSec field value",
+			config:   negativeWarmUpSec,
+			expected: fmt.Errorf("config param simulationLog field value is invalid warmUpSec must not be negative"),
+		},
 		{
 			name:     "config services[].name field value duplicate",
 			config:   serviceNameDuplicate,
@@ -167,6 +197,15 @@ func TestValidateFieldValue(t *testing.T) {
 				duplicateServiceName,
 			),
 		},
+		{
+			name:   "config services[].scenarioSpecs[].name field value duplicate after sanitized",
+			config: sanitizedNameDuplicate,
+			expected: fmt.Errorf(
+				"%v, config.yaml scenarioSpec name duplicated in service %v",
+				sanitizedNameDuplicateErr,
+				duplicateServiceName,
+			),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -246,6 +285,13 @@ func TestCheckTargetLatencyRequiredField_Success(t *testing.T) {
 				latency:    100,
 			},
 		},
+		{
+			name: "percentile which is not in default gatling report is specified",
+			input: targetLatencyField{
+				percentile: 99.9,
+				latency:    100,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -278,12 +324,20 @@ func TestCheckTargetLatencyRequiredField_Failed(t *testing.T) {
 			expected: fmt.Errorf("percentile must be set with latency, one of these is empty"),
 		},
 		{
-			name: "invalid percentile specified",
+			name: "percentile over 100 specified",
+			input: targetLatencyField{
+				percentile: 100.1,
+				latency:    100,
+			},
+			expected: fmt.Errorf("percentile must be more than 0 and not more than 100"),
+		},
+		{
+			name: "negative percentile specified",
 			input: targetLatencyField{
-				percentile: 80,
+				percentile: -1,
 				latency:    100,
 			},
-			expected: fmt.Errorf("specified percentile value is not matched to GatlingReport field"),
+			expected: fmt.Errorf("percentile must be more than 0 and not more than 100"),
 		},
 	}
 	for _, tt := range tests {
@@ -309,6 +363,19 @@ func TestValidateGetTargetPodRequiredField(t *testing.T) {
 				ContainerName: "sample-api",
 			},
 		},
+		{
+			name: "validate get target pod required field with only labelSelector success",
+			input: TargetPodConfig{
+				ContextName: "gke_sample_asia-east1_gke_sample_asia",
+				Namespace:   "sample",
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "run", Operator: metav1.LabelSelectorOpIn, Values: []string{"sample-api"}},
+					},
+				},
+				ContainerName: "sample-api",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -379,6 +446,28 @@ func TestValidateGetTargetPodRequiredField_Failed(t *testing.T) {
 			},
 			expected: fmt.Errorf("targetPod field containerName is required"),
 		},
+		{
+			name: "empty labelSelector",
+			input: TargetPodConfig{
+				ContextName:   "gke_sample_asia-east1_gke_sample_asia",
+				Namespace:     "sample",
+				LabelSelector: &metav1.LabelSelector{},
+				ContainerName: "sample-api",
+			},
+			expected: fmt.Errorf("targetPod field labelSelector must not be empty"),
+		},
+		{
+			name: "invalid labelSelector operator",
+			input: TargetPodConfig{
+				ContextName: "gke_sample_asia-east1_gke_sample_asia",
+				Namespace:   "sample",
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "run", Operator: "Equals"}},
+				},
+				ContainerName: "sample-api",
+			},
+			expected: fmt.Errorf(`targetPod field labelSelector is invalid, "Equals" is not a valid label selector operator`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -387,3 +476,673 @@ func TestValidateGetTargetPodRequiredField_Failed(t *testing.T) {
 		})
 	}
 }
+
+func TestTargetPodConfigSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    TargetPodConfig
+		expected string
+	}{
+		{
+			name:     "labelKey and labelValue",
+			input:    TargetPodConfig{LabelKey: "run", LabelValue: "sample-api"},
+			expected: "run=sample-api",
+		},
+		{
+			name: "labelSelector with multiple labels and matchExpressions",
+			input: TargetPodConfig{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "sample", "component": "cache"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"canary"}},
+					},
+				},
+			},
+			expected: "app=sample,component=cache,tier notin (canary)",
+		},
+		{
+			name: "labelKey and labelSelector are combined",
+			input: TargetPodConfig{
+				LabelKey:      "run",
+				LabelValue:    "sample-api",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sample"}},
+			},
+			expected: "app=sample,run=sample-api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := tt.input.Selector()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, selector.String())
+		})
+	}
+}
+
+func TestTargetPodConfigs(t *testing.T) {
+	podConfig := TargetPodConfig{
+		ContextName:   "gke_sample_asia-east1_gke_sample_asia",
+		Namespace:     "sample",
+		LabelKey:      "run",
+		LabelValue:    "sample-api",
+		ContainerName: "sample-api",
+	}
+	namedPodConfig := func(name, containerName string) TargetPodConfig {
+		c := podConfig
+		c.Name = name
+		c.ContainerName = containerName
+		return c
+	}
+	tests := []struct {
+		name     string
+		input    Service
+		expected []TargetPodConfig
+	}{
+		{
+			name:     "targetPodConfig is named with containerName",
+			input:    Service{TargetPodConfig: podConfig},
+			expected: []TargetPodConfig{namedPodConfig("sample-api", "sample-api")},
+		},
+		{
+			name: "targets",
+			input: Service{Targets: []TargetPodConfig{
+				namedPodConfig("api", "sample-api"), namedPodConfig("proxy", "envoy"),
+			}},
+			expected: []TargetPodConfig{namedPodConfig("api", "sample-api"), namedPodConfig("proxy", "envoy")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.TargetPodConfigs())
+		})
+	}
+}
+
+func TestValidateTargetsField(t *testing.T) {
+	podConfig := TargetPodConfig{
+		Name:          "api",
+		ContextName:   "gke_sample_asia-east1_gke_sample_asia",
+		Namespace:     "sample",
+		LabelKey:      "run",
+		LabelValue:    "sample-api",
+		ContainerName: "sample-api",
+	}
+	invalidPodConfig := podConfig
+	invalidPodConfig.Name = "cache"
+	invalidPodConfig.Namespace = ""
+	noNamePodConfig := podConfig
+	noNamePodConfig.Name = ""
+	tests := []struct {
+		name     string
+		input    Service
+		expected error
+	}{
+		{
+			name:     "valid targetPodConfig",
+			input:    Service{TargetPodConfig: podConfig},
+			expected: nil,
+		},
+		{
+			name:     "valid targets",
+			input:    Service{Targets: []TargetPodConfig{podConfig}},
+			expected: nil,
+		},
+		{
+			name:     "targetPodConfig and targets specified together",
+			input:    Service{TargetPodConfig: podConfig, Targets: []TargetPodConfig{podConfig}},
+			expected: fmt.Errorf("targetPodConfig and targets can not be specified together"),
+		},
+		{
+			name:     "target without name",
+			input:    Service{Targets: []TargetPodConfig{podConfig, noNamePodConfig}},
+			expected: fmt.Errorf("targets field name is required"),
+		},
+		{
+			name:     "target name duplicated",
+			input:    Service{Targets: []TargetPodConfig{podConfig, podConfig}},
+			expected: fmt.Errorf("duplicated value found [api]\n, targets name duplicated"),
+		},
+		{
+			name:     "invalid target",
+			input:    Service{Targets: []TargetPodConfig{podConfig, invalidPodConfig}},
+			expected: fmt.Errorf("targetPod field namespace is required"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateTargetsField(tt.input))
+		})
+	}
+}
+
+func TestValidateStatic(t *testing.T) {
+	var (
+		validStaticConfig     Config
+		invalidManifestConfig Config
+		invalidScenarioConfig Config
+	)
+	for _, c := range []*Config{&validStaticConfig, &invalidManifestConfig, &invalidScenarioConfig} {
+		err := copier.CopyWithOption(c, validConfig, copier.Option{
+			IgnoreEmpty: false,
+			DeepCopy:    true,
+		})
+		assert.NoError(t, err)
+		c.BaseManifest = "testdata/base_manifest.yaml"
+		c.ImageURL = "example/gatling-scenario:sample"
+		// yaml.v3 does not map json tagged camel case field of TestScenarioSpec, so set it here.
+		for i := range c.Services[0].ScenarioSpecs {
+			c.Services[0].ScenarioSpecs[i].TestScenarioSpec.SimulationClass = "SampleSimulation"
+		}
+	}
+	invalidManifestConfig.BaseManifest = "testdata/not_exists.yaml"
+	invalidManifestConfig.ImageURL = ""
+	invalidManifestConfig.GatlingDockerfileDir = "testdata"
+	invalidScenarioConfig.ExecTimeoutSec = 5
+	invalidScenarioConfig.Services[0].ScenarioSpecs[1].TestScenarioSpec.SimulationClass = ""
+	invalidScenarioConfig.Services[0].ScenarioSpecs[1].TestScenarioSpec.Env[1].Value = "ten"
+
+	tests := []struct {
+		name        string
+		config      Config
+		expectedNum int
+	}{
+		{
+			name:        "valid config has no problem",
+			config:      validStaticConfig,
+			expectedNum: 0,
+		},
+		{
+			name:        "base manifest not exists and no Dockerfile",
+			config:      invalidManifestConfig,
+			expectedNum: 2,
+		},
+		{
+			name:   "invalid scenario specs are all reported",
+			config: invalidScenarioConfig,
+			// two DURATION over execTimeoutSec, no simulationClass and invalid CONCURRENCY.
+			expectedNum: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := tt.config.ValidateStatic()
+			assert.Len(t, problems, tt.expectedNum, "%v", problems)
+		})
+	}
+}
+
+func TestRunDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		runsDir  string
+		expected string
+	}{
+		{
+			name:     "runsDir not specified",
+			runsDir:  "",
+			expected: ".gatling-commander/runs/202308021850-abcd",
+		},
+		{
+			name:     "runsDir specified",
+			runsDir:  "/tmp/loadtest-runs",
+			expected: "/tmp/loadtest-runs/202308021850-abcd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{RunsDir: tt.runsDir}
+			assert.Equal(t, tt.expected, config.RunDir("202308021850-abcd"))
+		})
+	}
+}
+
+func TestValidateSearchField(t *testing.T) {
+	validSearch := SearchConfig{
+		Strategy:           "bisect",
+		StartConcurrency:   10,
+		MaxConcurrency:     100,
+		Step:               5,
+		ErrorBudgetPercent: 1,
+		CPUCeilingPercent:  80,
+	}
+	templateScenarioSpecs := []ScenarioSpec{
+		{
+			Name: "search",
+			TestScenarioSpec: gatlingv1alpha1.TestScenarioSpec{
+				Env: []corev1.EnvVar{{Name: "CONCURRENCY", Value: "10"}},
+			},
+		},
+	}
+	invalidStrategySearch := validSearch
+	invalidStrategySearch.Strategy = "random"
+	invalidBudgetSearch := validSearch
+	invalidBudgetSearch.ErrorBudgetPercent = 101
+	invalidCPUCeilingSearch := validSearch
+	invalidCPUCeilingSearch.CPUCeilingPercent = -1
+
+	tests := []struct {
+		name          string
+		search        SearchConfig
+		scenarioSpecs []ScenarioSpec
+		expected      error
+	}{
+		{
+			name:          "valid search field",
+			search:        validSearch,
+			scenarioSpecs: templateScenarioSpecs,
+			expected:      nil,
+		},
+		{
+			name:          "invalid strategy",
+			search:        invalidStrategySearch,
+			scenarioSpecs: templateScenarioSpecs,
+			expected:      fmt.Errorf("strategy must be step or bisect, got random"),
+		},
+		{
+			name:          "invalid errorBudgetPercent",
+			search:        invalidBudgetSearch,
+			scenarioSpecs: templateScenarioSpecs,
+			expected:      fmt.Errorf("errorBudgetPercent must be between 0 and 100"),
+		},
+		{
+			name:          "invalid cpuCeilingPercent",
+			search:        invalidCPUCeilingSearch,
+			scenarioSpecs: templateScenarioSpecs,
+			expected:      fmt.Errorf("cpuCeilingPercent must be between 0 and 100"),
+		},
+		{
+			name:          "more than one scenarioSpec",
+			search:        validSearch,
+			scenarioSpecs: append(append([]ScenarioSpec{}, templateScenarioSpecs...), templateScenarioSpecs...),
+			expected:      fmt.Errorf("search requires exactly one scenarioSpec as template, got 2"),
+		},
+		{
+			name:          "template has no CONCURRENCY env",
+			search:        validSearch,
+			scenarioSpecs: []ScenarioSpec{{Name: "search"}},
+			expected:      fmt.Errorf("scenarioSpec used as search template has no CONCURRENCY env"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateSearchField(tt.search, tt.scenarioSpecs))
+		})
+	}
+}
+
+func TestValidateBaselineField(t *testing.T) {
+	assert.NoError(t, validateBaselineField(BaselineConfig{P99IncreasePercent: 10}))
+	assert.Equal(
+		t,
+		fmt.Errorf("throughputDecreasePercent must not be negative"),
+		validateBaselineField(BaselineConfig{ThroughputDecreasePercent: -1}),
+	)
+}
+
+func TestValidateAbortRulesField(t *testing.T) {
+	maxRestarts := int32(0)
+	negativeMaxRunnerErrors := int64(-1)
+	tests := []struct {
+		name       string
+		abortRules AbortRulesConfig
+		expected   error
+	}{
+		{
+			name:       "valid abortRules field",
+			abortRules: AbortRulesConfig{CPUPercent: 95, SustainedSec: 60, MaxRestarts: &maxRestarts},
+			expected:   nil,
+		},
+		{
+			name:       "invalid memoryPercent",
+			abortRules: AbortRulesConfig{MemoryPercent: 120},
+			expected:   fmt.Errorf("memoryPercent must be between 0 and 100"),
+		},
+		{
+			name:       "invalid maxRunnerErrors",
+			abortRules: AbortRulesConfig{MaxRunnerErrors: &negativeMaxRunnerErrors},
+			expected:   fmt.Errorf("maxRunnerErrors must not be negative"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateAbortRulesField(tt.abortRules))
+		})
+	}
+}
+
+func TestValidateMetricsProviderField(t *testing.T) {
+	queries := []MetricsQueryConfig{
+		{Name: "cpu-throttling", Query: "sum(rate(container_cpu_cfs_throttled_periods_total[1m]))"},
+	}
+	tests := []struct {
+		name            string
+		metricsProvider MetricsProviderConfig
+		expected        error
+	}{
+		{
+			name:            "valid metricsProvider field",
+			metricsProvider: MetricsProviderConfig{Type: "prometheus", Address: "http://localhost:9090", Queries: queries},
+			expected:        nil,
+		},
+		{
+			name:            "unsupported type",
+			metricsProvider: MetricsProviderConfig{Type: "datadog", Address: "http://localhost:9090", Queries: queries},
+			expected:        fmt.Errorf("type must be prometheus"),
+		},
+		{
+			name:            "no address",
+			metricsProvider: MetricsProviderConfig{Type: "prometheus", Queries: queries},
+			expected:        fmt.Errorf("address is required"),
+		},
+		{
+			name:            "no queries",
+			metricsProvider: MetricsProviderConfig{Type: "prometheus", Address: "http://localhost:9090"},
+			expected:        fmt.Errorf("queries or targetUsage is required"),
+		},
+		{
+			name:            "target usage without queries",
+			metricsProvider: MetricsProviderConfig{Type: "prometheus", Address: "http://localhost:9090", TargetUsage: true},
+			expected:        nil,
+		},
+		{
+			name: "target usage query without targetUsage",
+			metricsProvider: MetricsProviderConfig{
+				Type: "prometheus", Address: "http://localhost:9090", Queries: queries, TargetCPUQuery: "cpu",
+			},
+			expected: fmt.Errorf("targetCPUQuery and targetMemoryQuery require targetUsage"),
+		},
+		{
+			name: "query without name",
+			metricsProvider: MetricsProviderConfig{
+				Type: "prometheus", Address: "http://localhost:9090", Queries: []MetricsQueryConfig{{Query: "up"}},
+			},
+			expected: fmt.Errorf("queries field name and query are required"),
+		},
+		{
+			name: "query name duplicated",
+			metricsProvider: MetricsProviderConfig{
+				Type: "prometheus", Address: "http://localhost:9090", Queries: append(queries, queries...),
+			},
+			expected: fmt.Errorf("duplicated value found [cpu-throttling]\n, queries name duplicated"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateMetricsProviderField(tt.metricsProvider))
+		})
+	}
+}
+
+func TestValidateRunnerSaturationField(t *testing.T) {
+	tests := []struct {
+		name             string
+		runnerSaturation RunnerSaturationConfig
+		expected         error
+	}{
+		{
+			name:             "valid runnerSaturation field",
+			runnerSaturation: RunnerSaturationConfig{CPUPercent: 90},
+			expected:         nil,
+		},
+		{
+			name:             "cpuPercent over 100",
+			runnerSaturation: RunnerSaturationConfig{CPUPercent: 120},
+			expected:         fmt.Errorf("cpuPercent must be between 0 and 100"),
+		},
+		{
+			name:             "negative memoryPercent",
+			runnerSaturation: RunnerSaturationConfig{CPUPercent: 90, MemoryPercent: -1},
+			expected:         fmt.Errorf("memoryPercent must be between 0 and 100"),
+		},
+		{
+			name:             "no threshold",
+			runnerSaturation: RunnerSaturationConfig{},
+			expected:         fmt.Errorf("cpuPercent or memoryPercent is required"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateRunnerSaturationField(tt.runnerSaturation))
+		})
+	}
+}
+
+func TestValidateRequestsField(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []RequestTargetConfig
+		expected error
+	}{
+		{
+			name: "valid requests field",
+			requests: []RequestTargetConfig{
+				{Name: "home", FailFast: true},
+				{Name: "checkout / pay", TargetPercentile: 99, TargetLatency: 500},
+			},
+			expected: nil,
+		},
+		{
+			name:     "no requests",
+			requests: nil,
+			expected: nil,
+		},
+		{
+			name:     "no name",
+			requests: []RequestTargetConfig{{FailFast: true}},
+			expected: fmt.Errorf("requests field name is required"),
+		},
+		{
+			name:     "targetLatency without targetPercentile",
+			requests: []RequestTargetConfig{{Name: "home", TargetLatency: 500}},
+			expected: fmt.Errorf("request home percentile must be set with latency, one of these is empty"),
+		},
+		{
+			name:     "duplicated name",
+			requests: []RequestTargetConfig{{Name: "home"}, {Name: "home"}},
+			expected: fmt.Errorf("duplicated value found [home]\n, requests name duplicated"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateRequestsField(tt.requests))
+		})
+	}
+}
+
+func TestValidatePercentilesField(t *testing.T) {
+	p999Assertion := &SLOConfig{Assertions: []SLOAssertionConfig{{Assert: "p99.9 < 300ms", Severity: "warn"}}}
+	defaultPercentiles := gatling.DefaultReportPercentiles
+	tests := []struct {
+		name              string
+		service           Service
+		reportPercentiles []float64
+		expected          error
+	}{
+		{
+			name: "percentiles of gatling report",
+			service: Service{
+				TargetPercentile: 99,
+				Requests:         []RequestTargetConfig{{Name: "home", TargetPercentile: 95, TargetLatency: 500}},
+				SLO:              &SLOConfig{Assertions: []SLOAssertionConfig{{Assert: "p50 < 100ms", Severity: "warn"}}},
+				ScenarioSpecs:    []ScenarioSpec{{Name: "light"}},
+			},
+			reportPercentiles: defaultPercentiles,
+			expected:          nil,
+		},
+		{
+			name: "percentiles customized in gatling.conf",
+			service: Service{
+				TargetPercentile: 99.9,
+				Requests:         []RequestTargetConfig{{Name: "home", TargetPercentile: 99.9, TargetLatency: 500}},
+				ScenarioSpecs:    []ScenarioSpec{{Name: "light", SLO: p999Assertion}},
+			},
+			reportPercentiles: []float64{50, 90, 99, 99.9},
+			expected:          nil,
+		},
+		{
+			name: "percentiles of simulation.log",
+			service: Service{
+				TargetPercentile: 99.9,
+				SimulationLog:    &SimulationLogConfig{},
+				ScenarioSpecs:    []ScenarioSpec{{Name: "light", SLO: p999Assertion}},
+			},
+			reportPercentiles: defaultPercentiles,
+			expected:          nil,
+		},
+		{
+			name: "gatling.conf can not be read",
+			service: Service{
+				TargetPercentile: 99.9,
+				Requests:         []RequestTargetConfig{{Name: "home", TargetPercentile: 99.9, TargetLatency: 500}},
+				ScenarioSpecs:    []ScenarioSpec{{Name: "light", SLO: p999Assertion}},
+			},
+			reportPercentiles: nil,
+			expected:          nil,
+		},
+		{
+			name:              "targetPercentile without simulationLog",
+			service:           Service{TargetPercentile: 99.9},
+			reportPercentiles: defaultPercentiles,
+			expected: fmt.Errorf(
+				"targetPercentile 99.9 requires simulationLog, percentiles of gatling report are [50 75 95 99]",
+			),
+		},
+		{
+			name: "targetPercentile of request is not in gatling report",
+			service: Service{
+				SimulationLog: &SimulationLogConfig{},
+				Requests:      []RequestTargetConfig{{Name: "home", TargetPercentile: 99.9, TargetLatency: 500}},
+			},
+			reportPercentiles: defaultPercentiles,
+			expected: fmt.Errorf(
+				"request home targetPercentile 99.9 must be one of percentiles of gatling report [50 75 95 99]",
+			),
+		},
+		{
+			name: "slo percentile without simulationLog",
+			service: Service{
+				ScenarioSpecs: []ScenarioSpec{{Name: "light"}, {Name: "heavy", SLO: p999Assertion}},
+			},
+			reportPercentiles: defaultPercentiles,
+			expected: fmt.Errorf(
+				"scenarioSpec heavy slo percentile 99.9 requires simulationLog, percentiles of gatling report are [50 75 95 99]",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validatePercentilesField(tt.service, tt.reportPercentiles))
+		})
+	}
+}
+
+func TestConfigReportPercentiles(t *testing.T) {
+	dockerfileDir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dockerfileDir, "conf"), 0o755))
+	conf := "gatling {\n  charting {\n    indicators {\n      percentile4 = 99.9\n    }\n  }\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dockerfileDir, "conf", "gatling.conf"), []byte(conf), 0o644))
+
+	config := Config{GatlingDockerfileDir: dockerfileDir}
+	assert.Equal(t, []float64{50, 75, 95, 99.9}, config.reportPercentiles())
+
+	// percentiles are unknown without gatling.conf.
+	config = Config{GatlingDockerfileDir: t.TempDir()}
+	assert.Nil(t, config.reportPercentiles())
+}
+
+func TestValidateSLOField(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  Service
+		expected error
+	}{
+		{
+			name: "valid slo field",
+			service: Service{
+				SLO: &SLOConfig{
+					Assertions: []SLOAssertionConfig{
+						{Assert: "p99 < 300ms", Severity: "stop"},
+						{Assert: "rps >= 95% of target", Severity: "fail-run"},
+					},
+				},
+				ScenarioSpecs: []ScenarioSpec{
+					{Name: "light", SLO: &SLOConfig{TargetRPS: 100}},
+					{Name: "heavy", SLO: &SLOConfig{TargetRPS: 500}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "no slo",
+			service: Service{
+				ScenarioSpecs: []ScenarioSpec{{Name: "light"}},
+			},
+			expected: nil,
+		},
+		{
+			name: "invalid severity",
+			service: Service{
+				SLO: &SLOConfig{Assertions: []SLOAssertionConfig{{Assert: "mean < 80ms"}}},
+			},
+			expected: fmt.Errorf(`severity of "mean < 80ms" must be one of stop, fail-run and warn`),
+		},
+		{
+			name: "invalid assertion of scenarioSpec",
+			service: Service{
+				ScenarioSpecs: []ScenarioSpec{
+					{
+						Name: "light",
+						SLO:  &SLOConfig{Assertions: []SLOAssertionConfig{{Assert: "p99 > 0 < 1", Severity: "warn"}}},
+					},
+				},
+			},
+			expected: fmt.Errorf(`scenarioSpec light assertion "p99 > 0 < 1" must be formatted as <metric> <operator> <value>`),
+		},
+		{
+			name: "negative targetRPS",
+			service: Service{
+				SLO:           &SLOConfig{TargetRPS: -1},
+				ScenarioSpecs: []ScenarioSpec{{Name: "light"}},
+			},
+			expected: fmt.Errorf("scenarioSpec light targetRPS must not be negative"),
+		},
+		{
+			name: "targetRPS is not set to scenarioSpec",
+			service: Service{
+				SLO: &SLOConfig{Assertions: []SLOAssertionConfig{{Assert: "rps >= 95% of target", Severity: "warn"}}},
+				ScenarioSpecs: []ScenarioSpec{
+					{Name: "light", SLO: &SLOConfig{TargetRPS: 100}},
+					{Name: "heavy"},
+				},
+			},
+			expected: fmt.Errorf("scenarioSpec heavy assertion %q requires targetRPS", "rps >= 95% of target"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateSLOField(tt.service))
+		})
+	}
+}
+
+func TestMergeSLO(t *testing.T) {
+	serviceSLO := &SLOConfig{
+		TargetRPS:  100,
+		Assertions: []SLOAssertionConfig{{Assert: "p99 < 300ms", Severity: "stop"}},
+	}
+	scenarioSLO := &SLOConfig{
+		TargetRPS:  500,
+		Assertions: []SLOAssertionConfig{{Assert: "rps >= 95% of target", Severity: "warn"}},
+	}
+	assert.Nil(t, MergeSLO(nil, nil))
+	assert.Equal(t, serviceSLO, MergeSLO(serviceSLO, nil))
+	assert.Equal(t, &SLOConfig{
+		TargetRPS: 500,
+		Assertions: []SLOAssertionConfig{
+			{Assert: "p99 < 300ms", Severity: "stop"},
+			{Assert: "rps >= 95% of target", Severity: "warn"},
+		},
+	}, MergeSLO(serviceSLO, scenarioSLO))
+	// targetRPS of service is used when scenarioSpec doesn't set it.
+	assert.Equal(t, float64(100), MergeSLO(serviceSLO, &SLOConfig{}).TargetRPS)
+}
@@ -22,17 +22,53 @@ SOFTWARE.
 
 package config
 
-import gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+import (
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
 
 // Service has common field among each loadtests per target service, and has several its ScenarioSpecs.
 type Service struct {
-	Name             string          `yaml:"name"`
-	SpreadsheetId    string          `yaml:"spreadsheetID"`
-	FailFast         bool            `yaml:"failFast"`
-	TargetPodConfig  TargetPodConfig `yaml:"targetPodConfig"`
-	TargetPercentile uint32          `yaml:"targetPercentile"`
-	TargetLatency    float64         `yaml:"targetLatency"`
-	ScenarioSpecs    []ScenarioSpec  `yaml:"scenarioSpecs"`
+	Name             string                 `yaml:"name"`
+	SpreadsheetId    string                 `yaml:"spreadsheetID"`
+	FailFast         bool                   `yaml:"failFast"`
+	TargetPodConfig  TargetPodConfig        `yaml:"targetPodConfig"`
+	Targets          []TargetPodConfig      `yaml:"targets"`
+	TargetPercentile float64                `yaml:"targetPercentile"`
+	TargetLatency    float64                `yaml:"targetLatency"`
+	Requests         []RequestTargetConfig  `yaml:"requests"`
+	ScenarioSpecs    []ScenarioSpec         `yaml:"scenarioSpecs"`
+	Search           *SearchConfig          `yaml:"search"`
+	Baseline         *BaselineConfig        `yaml:"baseline"`
+	AbortRules       *AbortRulesConfig      `yaml:"abortRules"`
+	MetricsProvider  *MetricsProviderConfig `yaml:"metricsProvider"`
+	SimulationLog    *SimulationLogConfig   `yaml:"simulationLog"`
+	SLO              *SLOConfig             `yaml:"slo"`
+}
+
+/*
+CloudStorageConfig has settings used when fetching Gatling report from cloud storage.
+
+Storage backend is chosen from the report storage path of Gatling object, so every field is optional.
+*/
+type CloudStorageConfig struct {
+	S3Endpoint     string `yaml:"s3Endpoint"`
+	S3UsePathStyle bool   `yaml:"s3UsePathStyle"`
+	AzureAccount   string `yaml:"azureAccount"`
+	AzureEndpoint  string `yaml:"azureEndpoint"`
+}
+
+/*
+RunnerSaturationConfig has thresholds for detecting saturation of Gatling runner pods, which generate load.
+
+Peak usage of runner container of each runner pod is compared with podSpec.resources of Gatling object,
+and loadtest is flagged as load generator saturated when it exceeds threshold. 0 means the resource is not checked.
+*/
+type RunnerSaturationConfig struct {
+	CPUPercent    float64 `yaml:"cpuPercent"`
+	MemoryPercent float64 `yaml:"memoryPercent"`
 }
 
 // SlackConfig has field which used for slack alert.
@@ -46,13 +82,206 @@ type ScenarioSpec struct {
 	Name             string                           `yaml:"name"`
 	SubName          string                           `yaml:"subName"`
 	TestScenarioSpec gatlingv1alpha1.TestScenarioSpec `yaml:"testScenarioSpec"`
+	SLO              *SLOConfig                       `yaml:"slo"`
+}
+
+/*
+SearchConfig has field for capacity search mode.
+
+In capacity search mode, loadtests are generated from the only ScenarioSpec of service by rewriting CONCURRENCY env
+value, and the highest CONCURRENCY value which meets the target is searched.
+*/
+type SearchConfig struct {
+	Strategy           string  `yaml:"strategy"`
+	StartConcurrency   int     `yaml:"startConcurrency"`
+	MaxConcurrency     int     `yaml:"maxConcurrency"`
+	Step               int     `yaml:"step"`
+	ErrorBudgetPercent float64 `yaml:"errorBudgetPercent"`
+	CPUCeilingPercent  float64 `yaml:"cpuCeilingPercent"`
+}
+
+/*
+BaselineConfig has field for comparing each loadtest result with the one of baseline run.
+
+Baseline run is the run which has runID, or the last run in which no loadtest failed or regressed.
+Each threshold is allowed change from baseline, and 0 means the metric is not compared.
+*/
+type BaselineConfig struct {
+	RunID                        string  `yaml:"runID"`
+	P95IncreasePercent           float64 `yaml:"p95IncreasePercent"`
+	P99IncreasePercent           float64 `yaml:"p99IncreasePercent"`
+	FailedIncreasePoint          float64 `yaml:"failedIncreasePoint"`
+	ThroughputDecreasePercent    float64 `yaml:"throughputDecreasePercent"`
+	CPUPerRequestIncreasePercent float64 `yaml:"cpuPerRequestIncreasePercent"`
+}
+
+/*
+AbortRulesConfig has rules for aborting loadtest while it is running.
+
+Rules are checked every time Gatling Job status is polled. Once one of the rules is met, Gatling object is deleted
+and the loadtest is recorded as aborted. 0 of percent thresholds means the rule is disabled,
+and nil of maxRestarts and maxRunnerErrors means the rule is disabled.
+*/
+type AbortRulesConfig struct {
+	CPUPercent    float64 `yaml:"cpuPercent"`
+	MemoryPercent float64 `yaml:"memoryPercent"`
+	// SustainedSec is how long cpuPercent or memoryPercent must be continuously exceeded before abort.
+	SustainedSec     int32  `yaml:"sustainedSec"`
+	MaxRestarts      *int32 `yaml:"maxRestarts"`
+	AbortOnOOMKilled bool   `yaml:"abortOnOOMKilled"`
+	MaxRunnerErrors  *int64 `yaml:"maxRunnerErrors"`
+}
+
+/*
+MetricsProviderConfig has settings of metrics provider, which is queried for metrics over the loadtest window.
+
+Only prometheus type is supported. StepSec is resolution of range query, and 15 is used when it is 0.
+When TargetUsage is true, cpu and memory usage of target containers is fetched from metrics provider instead of
+metrics-server, with TargetCPUQuery and TargetMemoryQuery, or the default queries of cAdvisor metrics when they are
+empty. $namespace, $container and $pods in them are replaced with namespace, container name and regex of running
+pod names of each target, and they must return mCPU and bytes by pod label.
+*/
+type MetricsProviderConfig struct {
+	Type              string               `yaml:"type"`
+	Address           string               `yaml:"address"`
+	StepSec           int32                `yaml:"stepSec"`
+	Queries           []MetricsQueryConfig `yaml:"queries"`
+	TargetUsage       bool                 `yaml:"targetUsage"`
+	TargetCPUQuery    string               `yaml:"targetCPUQuery"`
+	TargetMemoryQuery string               `yaml:"targetMemoryQuery"`
+}
+
+/*
+MetricsQueryConfig has a query run over the loadtest window, and its result is recorded under name.
+
+$__range in query is replaced with duration of the window, e.g. increase(http_requests_total[$__range]).
+*/
+type MetricsQueryConfig struct {
+	Name  string `yaml:"name"`
+	Query string `yaml:"query"`
 }
 
-// TargetPodConfig field value is used to fetch target container metrics value.
+/*
+SimulationLogConfig has settings of parsing simulation.log of Gatling runners, which is fetched only when it is set.
+
+Requests started in WarmUpSec seconds from the start of the simulation are excluded from summary stats, which are
+compared with baseline.
+*/
+type SimulationLogConfig struct {
+	WarmUpSec int32 `yaml:"warmUpSec"`
+}
+
+/*
+SLOConfig has assertions on result of each loadtest, such as "p99 < 300ms".
+
+TargetRPS is compared with rps assertion of which value is percentage of target, such as "rps >= 95% of target".
+*/
+type SLOConfig struct {
+	TargetRPS  float64              `yaml:"targetRPS"`
+	Assertions []SLOAssertionConfig `yaml:"assertions"`
+}
+
+// SLOAssertionConfig has an assertion and its severity, which is one of stop, fail-run and warn.
+type SLOAssertionConfig struct {
+	Assert   string `yaml:"assert"`
+	Severity string `yaml:"severity"`
+}
+
+/*
+MergeSLO returns SLO of loadtest from SLO of service and scenarioSpec, returns nil when neither of them is set.
+
+Assertions of scenarioSpec follow the ones of service, and targetRPS of scenarioSpec overrides the one of service
+when it is set.
+*/
+func MergeSLO(serviceSLO, scenarioSLO *SLOConfig) *SLOConfig {
+	if serviceSLO == nil && scenarioSLO == nil {
+		return nil
+	}
+	merged := &SLOConfig{}
+	for _, sloConfig := range []*SLOConfig{serviceSLO, scenarioSLO} {
+		if sloConfig == nil {
+			continue
+		}
+		if sloConfig.TargetRPS != 0 {
+			merged.TargetRPS = sloConfig.TargetRPS
+		}
+		merged.Assertions = append(merged.Assertions, sloConfig.Assertions...)
+	}
+	return merged
+}
+
+// ParseAssertions returns parsed assertions of SLO, returns nil when SLO is nil.
+func (c *SLOConfig) ParseAssertions() ([]slo.Assertion, error) {
+	if c == nil {
+		return nil, nil
+	}
+	assertions := make([]slo.Assertion, 0, len(c.Assertions))
+	for _, assertionConfig := range c.Assertions {
+		assertion, err := slo.ParseAssertion(assertionConfig.Assert, slo.Severity(assertionConfig.Severity))
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, assertion)
+	}
+	return assertions, nil
+}
+
+/*
+RequestTargetConfig has failFast and target latency of a request or group in Gatling stats.
+
+Name is matched with path of request in js/stats.json of Gatling report first, e.g. "checkout / pay" for request
+pay in group checkout, and then with its name.
+*/
+type RequestTargetConfig struct {
+	Name             string  `yaml:"name"`
+	FailFast         bool    `yaml:"failFast"`
+	TargetPercentile float64 `yaml:"targetPercentile"`
+	TargetLatency    float64 `yaml:"targetLatency"`
+}
+
+/*
+TargetPodConfigs returns targets of service, or targetPodConfig as the only target when targets is not specified.
+
+The first target is the primary target, which metrics are written to spreadsheet and used by baseline and
+capacity search. Name of targetPodConfig defaults to its containerName.
+*/
+func (s Service) TargetPodConfigs() []TargetPodConfig {
+	if len(s.Targets) > 0 {
+		return s.Targets
+	}
+	podConfig := s.TargetPodConfig
+	if podConfig.Name == "" {
+		podConfig.Name = podConfig.ContainerName
+	}
+	return []TargetPodConfig{podConfig}
+}
+
+/*
+TargetPodConfig field value is used to fetch target container metrics value.
+
+Pods are selected by labelKey and labelValue, labelSelector or both of them.
+*/
 type TargetPodConfig struct {
-	ContextName   string `yaml:"contextName"`
-	Namespace     string `yaml:"namespace"`
-	LabelKey      string `yaml:"labelKey"`
-	LabelValue    string `yaml:"labelValue"`
-	ContainerName string `yaml:"containerName"`
+	Name          string                `yaml:"name"`
+	ContextName   string                `yaml:"contextName"`
+	Namespace     string                `yaml:"namespace"`
+	LabelKey      string                `yaml:"labelKey"`
+	LabelValue    string                `yaml:"labelValue"`
+	LabelSelector *metav1.LabelSelector `yaml:"labelSelector"`
+	ContainerName string                `yaml:"containerName"`
+}
+
+// Selector returns label selector of target pods, which requires both labelKey=labelValue and labelSelector.
+func (c TargetPodConfig) Selector() (labels.Selector, error) {
+	selector := &metav1.LabelSelector{}
+	if c.LabelSelector != nil {
+		selector = c.LabelSelector.DeepCopy()
+	}
+	if c.LabelKey != "" {
+		if selector.MatchLabels == nil {
+			selector.MatchLabels = map[string]string{}
+		}
+		selector.MatchLabels[c.LabelKey] = c.LabelValue
+	}
+	return metav1.LabelSelectorAsSelector(selector)
 }
@@ -0,0 +1,134 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3StorageOperator implements exec.cloudStorageOperator interface.
+type S3StorageOperator struct {
+	client *s3.Client
+}
+
+/*
+NewS3StorageOperator returns initialized S3StorageOperator value.
+
+Credentials are loaded from the default credential chain of AWS SDK.
+When endpoint is specified, requests are sent to it instead of AWS, e.g. MinIO.
+S3 compatible storages like MinIO usually require usePathStyle.
+*/
+func NewS3StorageOperator(
+	ctx context.Context,
+	region string,
+	endpoint string,
+	usePathStyle bool,
+) (*S3StorageOperator, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return &S3StorageOperator{}, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = usePathStyle
+	})
+	return &S3StorageOperator{
+		client: client,
+	}, nil
+}
+
+// Fetch returns bytes of object in S3.
+func (op *S3StorageOperator) Fetch(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	bucket, object, err := validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := op.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObject(%q): %w", object, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll from s3 object: %w", err)
+	}
+	return data, nil
+}
+
+// List returns names of objects directly under dirPath in S3.
+func (op *S3StorageOperator) List(ctx context.Context, dirPath string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	bucket, object, err := validatePath(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix := dirPrefix(object)
+	paginator := s3.NewListObjectsV2Paginator(op.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	var names []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListObjectsV2(%q): %w", prefix, err)
+		}
+		for _, content := range page.Contents {
+			names = append(names, strings.TrimPrefix(aws.ToString(content.Key), prefix))
+		}
+	}
+	return names, nil
+}
+
+// Open returns reader of object in S3, which is closed by caller.
+func (op *S3StorageOperator) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	bucket, object, err := validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := op.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetObject(%q): %w", object, err)
+	}
+	return out.Body, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3StorageOperatorFetch(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	// stand-in of MinIO which serves object with path style request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/report-bucket/loadtest-name/999999/js/stats.json" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code></Error>`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"type":"GROUP"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		expected     []byte
+		expectErr    bool
+		errorMessage string
+	}{
+		{
+			name:     "fetch object from s3 compatible endpoint",
+			path:     "s3:report-bucket/loadtest-name/999999/js/stats.json",
+			expected: []byte(`{"type":"GROUP"}`),
+		},
+		{
+			name:     "fetch object with s3:// url",
+			path:     "s3://report-bucket/loadtest-name/999999/js/stats.json",
+			expected: []byte(`{"type":"GROUP"}`),
+		},
+		{
+			name:      "object not found",
+			path:      "s3:report-bucket/loadtest-name/000000/js/stats.json",
+			expectErr: true,
+		},
+		{
+			name:         "object is not specified",
+			path:         "s3:report-bucket",
+			expectErr:    true,
+			errorMessage: "invalid storage path s3:report-bucket, bucket and object are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			op, err := NewS3StorageOperator(ctx, "ap-northeast-1", server.URL, true)
+			assert.NoError(t, err)
+			data, err := op.Fetch(ctx, tt.path)
+			if tt.expectErr {
+				assert.Error(t, err)
+				if tt.errorMessage != "" {
+					assert.EqualError(t, err, tt.errorMessage)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestS3StorageOperatorListAndOpen(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	// stand-in of MinIO which lists objects under the report directory and serves runner log
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/report-bucket" || r.URL.Path == "/report-bucket/":
+			if r.URL.Query().Get("prefix") != "loadtest-name/999999/" || r.URL.Query().Get("delimiter") != "/" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<Name>report-bucket</Name><Prefix>loadtest-name/999999/</Prefix><IsTruncated>false</IsTruncated>
+<Contents><Key>loadtest-name/999999/index.html</Key></Contents>
+<Contents><Key>loadtest-name/999999/runner-1.log</Key></Contents>
+<CommonPrefixes><Prefix>loadtest-name/999999/js/</Prefix></CommonPrefixes>
+</ListBucketResult>`))
+		case r.URL.Path == "/report-bucket/loadtest-name/999999/runner-1.log":
+			_, _ = w.Write([]byte("RUN\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code></Error>`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	op, err := NewS3StorageOperator(ctx, "ap-northeast-1", server.URL, true)
+	assert.NoError(t, err)
+	names, err := op.List(ctx, "s3:report-bucket/loadtest-name/999999")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"index.html", "runner-1.log"}, names)
+
+	rc, err := op.Open(ctx, "s3:report-bucket/loadtest-name/999999/runner-1.log")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, []byte("RUN\n"), data)
+
+	_, err = op.Open(ctx, "s3:report-bucket/loadtest-name/000000/runner-1.log")
+	assert.Error(t, err)
+}
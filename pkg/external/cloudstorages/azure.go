@@ -0,0 +1,141 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+// AzureBlobKeyEnv is the environment variable name of storage account key, same as gatling-operator uses.
+const AzureBlobKeyEnv = "AZUREBLOB_KEY"
+
+// AzureBlobStorageOperator implements exec.cloudStorageOperator interface.
+type AzureBlobStorageOperator struct {
+	client *azblob.Client
+}
+
+/*
+NewAzureBlobStorageOperator returns initialized AzureBlobStorageOperator value.
+
+When AZUREBLOB_KEY environment variable is set, shared key credential of the account is used.
+Otherwise credential is loaded with azidentity.DefaultAzureCredential.
+When endpoint is specified, requests are sent to it instead of https://<account>.blob.core.windows.net/,
+e.g. Azurite.
+*/
+func NewAzureBlobStorageOperator(account string, endpoint string) (*AzureBlobStorageOperator, error) {
+	serviceURL := endpoint
+	if serviceURL == "" {
+		if account == "" {
+			return &AzureBlobStorageOperator{}, fmt.Errorf("azure storage account is required")
+		}
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	}
+	if key := os.Getenv(AzureBlobKeyEnv); key != "" {
+		cred, err := azblob.NewSharedKeyCredential(account, key)
+		if err != nil {
+			return &AzureBlobStorageOperator{}, fmt.Errorf("azblob.NewSharedKeyCredential: %w", err)
+		}
+		client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+		if err != nil {
+			return &AzureBlobStorageOperator{}, fmt.Errorf("azblob.NewClientWithSharedKeyCredential: %w", err)
+		}
+		return &AzureBlobStorageOperator{client: client}, nil
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return &AzureBlobStorageOperator{}, fmt.Errorf("azidentity.NewDefaultAzureCredential: %w", err)
+	}
+	client, err := azblob.NewClient(serviceURL, cred, nil)
+	if err != nil {
+		return &AzureBlobStorageOperator{}, fmt.Errorf("azblob.NewClient: %w", err)
+	}
+	return &AzureBlobStorageOperator{client: client}, nil
+}
+
+// Fetch returns bytes of blob in Azure Blob Storage.
+func (op *AzureBlobStorageOperator) Fetch(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	container, blob, err := validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := op.client.DownloadStream(ctx, container, blob, nil)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadStream(%q): %w", blob, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll from azure blob: %w", err)
+	}
+	return data, nil
+}
+
+// List returns names of blobs directly under dirPath in Azure Blob Storage.
+func (op *AzureBlobStorageOperator) List(ctx context.Context, dirPath string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	container, blob, err := validatePath(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix := dirPrefix(blob)
+	pager := op.client.NewListBlobsFlatPager(container, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+	var names []string
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListBlobsFlat(%q): %w", prefix, err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			name := strings.TrimPrefix(*item.Name, prefix)
+			if strings.Contains(name, "/") { // blob in sub directory
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Open returns reader of blob in Azure Blob Storage, which is closed by caller.
+func (op *AzureBlobStorageOperator) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	container, blob, err := validatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := op.client.DownloadStream(ctx, container, blob, nil)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadStream(%q): %w", blob, err)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAzureBlobStorageOperatorFetch(t *testing.T) {
+	t.Setenv(AzureBlobKeyEnv, base64.StdEncoding.EncodeToString([]byte("test-account-key")))
+	// stand-in of Azurite which serves blob under the account path
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet ||
+			r.URL.Path != "/devstoreaccount1/report-container/loadtest-name/999999/js/stats.json" {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type":"GROUP"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		expected     []byte
+		expectErr    bool
+		errorMessage string
+	}{
+		{
+			name:     "fetch blob from azure compatible endpoint",
+			path:     "az:report-container/loadtest-name/999999/js/stats.json",
+			expected: []byte(`{"type":"GROUP"}`),
+		},
+		{
+			name:      "blob not found",
+			path:      "az:report-container/loadtest-name/000000/js/stats.json",
+			expectErr: true,
+		},
+		{
+			name:         "blob is not specified",
+			path:         "az:report-container",
+			expectErr:    true,
+			errorMessage: "invalid storage path az:report-container, bucket and object are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			op, err := NewAzureBlobStorageOperator("devstoreaccount1", server.URL+"/devstoreaccount1")
+			assert.NoError(t, err)
+			data, err := op.Fetch(ctx, tt.path)
+			if tt.expectErr {
+				assert.Error(t, err)
+				if tt.errorMessage != "" {
+					assert.EqualError(t, err, tt.errorMessage)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestAzureBlobStorageOperatorListAndOpen(t *testing.T) {
+	t.Setenv(AzureBlobKeyEnv, base64.StdEncoding.EncodeToString([]byte("test-account-key")))
+	// stand-in of Azurite which lists blobs under the report directory and serves runner log
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/devstoreaccount1/report-container" && r.URL.Query().Get("comp") == "list":
+			if r.URL.Query().Get("restype") != "container" || r.URL.Query().Get("prefix") != "loadtest-name/999999/" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults ContainerName="report-container"><Prefix>loadtest-name/999999/</Prefix><Blobs>
+<Blob><Name>loadtest-name/999999/index.html</Name><Properties /></Blob>
+<Blob><Name>loadtest-name/999999/js/stats.json</Name><Properties /></Blob>
+<Blob><Name>loadtest-name/999999/runner-1.log</Name><Properties /></Blob>
+</Blobs><NextMarker /></EnumerationResults>`))
+		case r.Method == http.MethodGet &&
+			r.URL.Path == "/devstoreaccount1/report-container/loadtest-name/999999/runner-1.log":
+			_, _ = w.Write([]byte("RUN\n"))
+		default:
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	op, err := NewAzureBlobStorageOperator("devstoreaccount1", server.URL+"/devstoreaccount1")
+	assert.NoError(t, err)
+	names, err := op.List(ctx, "az:report-container/loadtest-name/999999")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"index.html", "runner-1.log"}, names)
+
+	rc, err := op.Open(ctx, "az:report-container/loadtest-name/999999/runner-1.log")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, []byte("RUN\n"), data)
+
+	_, err = op.Open(ctx, "az:report-container/loadtest-name/000000/runner-1.log")
+	assert.Error(t, err)
+}
+
+func TestNewAzureBlobStorageOperatorWithoutAccount(t *testing.T) {
+	_, err := NewAzureBlobStorageOperator("", "")
+	assert.EqualError(t, err, "azure storage account is required")
+}
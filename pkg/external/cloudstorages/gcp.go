@@ -26,9 +26,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
 )
 
 // GoogleCloudStorageOperator implements exec.cloudStorageOperator interface.
@@ -50,7 +52,6 @@ func NewGoogleCloudStorageOperator(ctx context.Context) (*GoogleCloudStorageOper
 // Fetch returns bytes of object in GCS.
 func (op *GoogleCloudStorageOperator) Fetch(ctx context.Context, path string) ([]byte, error) {
 	client := op.client
-	defer client.Close()
 	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
 	defer cancel()
 	bucket, object := parsePath(path)
@@ -65,3 +66,37 @@ func (op *GoogleCloudStorageOperator) Fetch(ctx context.Context, path string) ([
 	}
 	return data, nil
 }
+
+// List returns names of objects directly under dirPath in GCS.
+func (op *GoogleCloudStorageOperator) List(ctx context.Context, dirPath string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	bucket, object := parsePath(dirPath)
+	prefix := dirPrefix(object)
+	it := op.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	var names []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Bucket(%q).Objects: %w", bucket, err)
+		}
+		if attrs.Name == "" { // sub directory
+			continue
+		}
+		names = append(names, strings.TrimPrefix(attrs.Name, prefix))
+	}
+	return names, nil
+}
+
+// Open returns reader of object in GCS, which is closed by caller.
+func (op *GoogleCloudStorageOperator) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	bucket, object := parsePath(path)
+	rc, err := op.client.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Object(%q).NewReader: %w", object, err)
+	}
+	return rc, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LocalStorageOperator implements exec.cloudStorageOperator interface for local filesystem.
+type LocalStorageOperator struct{}
+
+// NewLocalStorageOperator returns initialized LocalStorageOperator value.
+func NewLocalStorageOperator() *LocalStorageOperator {
+	return &LocalStorageOperator{}
+}
+
+// Fetch returns bytes of file specified with file:// path.
+func (op *LocalStorageOperator) Fetch(ctx context.Context, path string) ([]byte, error) {
+	if !strings.HasPrefix(path, "file://") {
+		return nil, fmt.Errorf("invalid local storage path %v, file:// scheme is required", path)
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(path, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return data, nil
+}
+
+// List returns names of files directly under directory specified with file:// path.
+func (op *LocalStorageOperator) List(ctx context.Context, dirPath string) ([]string, error) {
+	if !strings.HasPrefix(dirPath, "file://") {
+		return nil, fmt.Errorf("invalid local storage path %v, file:// scheme is required", dirPath)
+	}
+	entries, err := os.ReadDir(strings.TrimPrefix(dirPath, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Open returns reader of file specified with file:// path.
+func (op *LocalStorageOperator) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "file://") {
+		return nil, fmt.Errorf("invalid local storage path %v, file:// scheme is required", path)
+	}
+	f, err := os.Open(strings.TrimPrefix(path, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	return f, nil
+}
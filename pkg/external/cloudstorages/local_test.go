@@ -0,0 +1,104 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorageOperatorFetch(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "loadtest-name", "999999", "js", "stats.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(reportPath), 0o755))
+	assert.NoError(t, os.WriteFile(reportPath, []byte(`{"type":"GROUP"}`), 0o644))
+
+	tests := []struct {
+		name         string
+		path         string
+		expected     []byte
+		expectErr    bool
+		errorMessage string
+	}{
+		{
+			name:     "fetch local file",
+			path:     "file://" + reportPath,
+			expected: []byte(`{"type":"GROUP"}`),
+		},
+		{
+			name:      "file not found",
+			path:      "file://" + filepath.Join(dir, "not-found.json"),
+			expectErr: true,
+		},
+		{
+			name:         "path without file scheme",
+			path:         reportPath,
+			expectErr:    true,
+			errorMessage: "invalid local storage path " + reportPath + ", file:// scheme is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewLocalStorageOperator().Fetch(context.Background(), tt.path)
+			if tt.expectErr {
+				assert.Error(t, err)
+				if tt.errorMessage != "" {
+					assert.EqualError(t, err, tt.errorMessage)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestLocalStorageOperatorListAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	reportDir := filepath.Join(dir, "loadtest-name", "999999")
+	assert.NoError(t, os.MkdirAll(filepath.Join(reportDir, "js"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(reportDir, "runner-1.log"), []byte("RUN\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(reportDir, "index.html"), []byte("<html>"), 0o644))
+
+	op := NewLocalStorageOperator()
+	names, err := op.List(context.Background(), "file://"+reportDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"index.html", "runner-1.log"}, names)
+
+	rc, err := op.Open(context.Background(), "file://"+filepath.Join(reportDir, "runner-1.log"))
+	assert.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, []byte("RUN\n"), data)
+
+	_, err = op.List(context.Background(), reportDir)
+	assert.EqualError(t, err, "invalid local storage path "+reportDir+", file:// scheme is required")
+	_, err = op.Open(context.Background(), "file://"+filepath.Join(reportDir, "not-found.log"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+/*
+Operator fetches object from cloud storage.
+
+List returns names of objects directly under dirPath, and Open returns reader of object to read large object such as
+simulation.log without loading it on memory.
+*/
+type Operator interface {
+	Fetch(ctx context.Context, path string) ([]byte, error)
+	List(ctx context.Context, dirPath string) ([]string, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// Options has settings of each backend which can not be derived from storage path.
+type Options struct {
+	// Provider is used when the scheme of storage path is unknown.
+	Provider       string
+	Region         string
+	S3Endpoint     string
+	S3UsePathStyle bool
+	AzureAccount   string
+	AzureEndpoint  string
+}
+
+/*
+NewOperator returns Operator of the backend which stores object of path.
+
+Backend is chosen from the scheme of path, and from opts.Provider when the scheme is unknown.
+*/
+func NewOperator(ctx context.Context, path string, opts Options) (Operator, error) {
+	provider := DetectProvider(path)
+	if provider == "" {
+		provider = opts.Provider
+	}
+	switch provider {
+	case ProviderGCP:
+		return NewGoogleCloudStorageOperator(ctx)
+	case ProviderAWS:
+		return NewS3StorageOperator(ctx, opts.Region, opts.S3Endpoint, opts.S3UsePathStyle)
+	case ProviderAzure:
+		return NewAzureBlobStorageOperator(opts.AzureAccount, opts.AzureEndpoint)
+	case ProviderLocal:
+		return NewLocalStorageOperator(), nil
+	}
+	return nil, fmt.Errorf("unsupported cloud storage provider %q for path %v", provider, path)
+}
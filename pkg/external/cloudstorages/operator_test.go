@@ -0,0 +1,86 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cloudstorages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOperator(t *testing.T) {
+	t.Setenv(AzureBlobKeyEnv, "dGVzdC1hY2NvdW50LWtleQ==")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	tests := []struct {
+		name         string
+		path         string
+		opts         Options
+		expected     Operator
+		expectErr    bool
+		errorMessage string
+	}{
+		{
+			name:     "choose s3 from scheme",
+			path:     "s3:report-bucket/loadtest-name/999999",
+			opts:     Options{Provider: ProviderGCP, Region: "ap-northeast-1"},
+			expected: &S3StorageOperator{},
+		},
+		{
+			name:     "choose azure from scheme",
+			path:     "az:report-container/loadtest-name/999999",
+			opts:     Options{AzureAccount: "account"},
+			expected: &AzureBlobStorageOperator{},
+		},
+		{
+			name:     "choose local from scheme",
+			path:     "file:///tmp/loadtest-name/999999",
+			expected: &LocalStorageOperator{},
+		},
+		{
+			name:     "choose provider field when scheme is unknown",
+			path:     "report-bucket/loadtest-name/999999",
+			opts:     Options{Provider: ProviderAWS, Region: "ap-northeast-1"},
+			expected: &S3StorageOperator{},
+		},
+		{
+			name:         "unsupported provider",
+			path:         "report-bucket/loadtest-name/999999",
+			opts:         Options{Provider: "unknown"},
+			expectErr:    true,
+			errorMessage: "unsupported cloud storage provider \"unknown\" for path report-bucket/loadtest-name/999999",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewOperator(context.Background(), tt.path, tt.opts)
+			if tt.expectErr {
+				assert.EqualError(t, err, tt.errorMessage)
+				return
+			}
+			assert.NoError(t, err)
+			assert.IsType(t, tt.expected, op)
+		})
+	}
+}
@@ -22,11 +22,66 @@ SOFTWARE.
 
 package cloudstorages
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider names, same as the value of cloudStorageSpec.provider field in Gatling manifest.
+const (
+	ProviderGCP   = "gcp"
+	ProviderAWS   = "aws"
+	ProviderAzure = "azure"
+	ProviderLocal = "local"
+)
+
+/*
+DetectProvider returns provider name from the scheme of storage path.
+
+gatling-operator sets path like "gs://bucket/...", "s3:bucket/..." or "az:container/...".
+"s3://bucket/..." and "file:///path/..." are also accepted.
+Empty string is returned when the scheme is unknown.
+*/
+func DetectProvider(path string) string {
+	switch {
+	case strings.HasPrefix(path, "gs://"):
+		return ProviderGCP
+	case strings.HasPrefix(path, "s3:"):
+		return ProviderAWS
+	case strings.HasPrefix(path, "az:"):
+		return ProviderAzure
+	case strings.HasPrefix(path, "file://"):
+		return ProviderLocal
+	}
+	return ""
+}
 
 // parsePath returns splitted url value of cloud storage to bucket and object path.
 func parsePath(path string) (bucket string, object string) {
-	host := strings.SplitN(path, "://", 2)[1]
-	bucket, object = strings.SplitN(host, "/", 2)[0], strings.SplitN(host, "/", 2)[1]
+	host := path
+	if i := strings.Index(path, "://"); i >= 0 {
+		host = path[i+len("://"):]
+	} else if i := strings.Index(path, ":"); i >= 0 {
+		host = path[i+1:]
+	}
+	parts := strings.SplitN(host, "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		object = parts[1]
+	}
 	return bucket, object
 }
+
+// dirPrefix returns object prefix of objects directly under directory object.
+func dirPrefix(object string) string {
+	return strings.TrimSuffix(object, "/") + "/"
+}
+
+// validatePath returns error when bucket or object can not be parsed from path.
+func validatePath(path string) (bucket string, object string, err error) {
+	bucket, object = parsePath(path)
+	if bucket == "" || object == "" {
+		return "", "", fmt.Errorf("invalid storage path %v, bucket and object are required", path)
+	}
+	return bucket, object, nil
+}
@@ -41,6 +41,24 @@ func TestParsePath(t *testing.T) {
 			expectedBucket: "report-bucket",
 			expectedObject: "loadtest-name/999999/js/global_stats.json",
 		},
+		{
+			name:           "got expected bucket and object from s3 path set by gatling-operator",
+			path:           "s3:report-bucket/loadtest-name/999999/js/global_stats.json",
+			expectedBucket: "report-bucket",
+			expectedObject: "loadtest-name/999999/js/global_stats.json",
+		},
+		{
+			name:           "got expected container and object from azure path",
+			path:           "az:report-container/loadtest-name/999999/js/global_stats.json",
+			expectedBucket: "report-container",
+			expectedObject: "loadtest-name/999999/js/global_stats.json",
+		},
+		{
+			name:           "got empty object when path has only bucket",
+			path:           "gs://report-bucket",
+			expectedBucket: "report-bucket",
+			expectedObject: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,3 +68,47 @@ func TestParsePath(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "gcs path",
+			path:     "gs://report-bucket/loadtest-name/999999",
+			expected: ProviderGCP,
+		},
+		{
+			name:     "s3 path set by gatling-operator",
+			path:     "s3:report-bucket/loadtest-name/999999",
+			expected: ProviderAWS,
+		},
+		{
+			name:     "s3 url",
+			path:     "s3://report-bucket/loadtest-name/999999",
+			expected: ProviderAWS,
+		},
+		{
+			name:     "azure blob path",
+			path:     "az:report-container/loadtest-name/999999",
+			expected: ProviderAzure,
+		},
+		{
+			name:     "local file path",
+			path:     "file:///tmp/reports/loadtest-name/999999",
+			expected: ProviderLocal,
+		},
+		{
+			name:     "unknown scheme",
+			path:     "report-bucket/loadtest-name/999999",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectProvider(tt.path))
+		})
+	}
+}
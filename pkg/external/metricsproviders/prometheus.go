@@ -0,0 +1,288 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metricsproviders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PrometheusBearerTokenEnv is env name of bearer token sent to Prometheus, no token is sent when it is not set.
+	PrometheusBearerTokenEnv = "PROMETHEUS_BEARER_TOKEN"
+	// prometheusMaxPoints is the maximum number of points per series which Prometheus returns for range query.
+	prometheusMaxPoints = 11000
+	// rangePlaceholder in query is replaced with duration of the loadtest window.
+	rangePlaceholder = "$__range"
+	// DefaultTargetCPUQuery is query of cpu usage of target container in mCPU by pod.
+	DefaultTargetCPUQuery = `sum by (pod) (rate(container_cpu_usage_seconds_total{` +
+		`namespace="$namespace",container="$container",pod=~"$pods"}[1m])) * 1000`
+	// DefaultTargetMemoryQuery is query of memory usage of target container in bytes by pod.
+	DefaultTargetMemoryQuery = `sum by (pod) (container_memory_working_set_bytes{` +
+		`namespace="$namespace",container="$container",pod=~"$pods"})`
+)
+
+/*
+PrometheusProvider implements Provider with range queries of Prometheus HTTP API, and UsageProvider with instant
+queries of it.
+*/
+type PrometheusProvider struct {
+	address           string
+	step              time.Duration
+	queries           []Query
+	targetCPUQuery    string
+	targetMemoryQuery string
+	httpClient        *http.Client
+}
+
+// prometheusResponse is response body of Prometheus HTTP API.
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			// each value is a pair of unix timestamp in seconds and value in string.
+			Values [][2]interface{} `json:"values"`
+			// Value is set instead of Values in the result of instant query.
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusProvider creates PrometheusProvider which queries Prometheus server at address.
+func NewPrometheusProvider(address string, step time.Duration, queries []Query) *PrometheusProvider {
+	return &PrometheusProvider{
+		address:           strings.TrimSuffix(address, "/"),
+		step:              step,
+		queries:           queries,
+		targetCPUQuery:    DefaultTargetCPUQuery,
+		targetMemoryQuery: DefaultTargetMemoryQuery,
+		httpClient:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Collect runs each query as range query from start to end.
+func (p *PrometheusProvider) Collect(ctx context.Context, start, end time.Time) ([]QueryResult, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid query window, start %v is not before end %v", start, end)
+	}
+	window := fmt.Sprintf("%vs", int64(math.Ceil(end.Sub(start).Seconds())))
+	results := make([]QueryResult, 0, len(p.queries))
+	for _, query := range p.queries {
+		result := QueryResult{
+			Name:  query.Name,
+			Query: strings.ReplaceAll(query.Query, rangePlaceholder, window),
+		}
+		series, err := p.queryRange(ctx, result.Query, start, end)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Series = series
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+/*
+queryRange runs range query and returns series of matrix result.
+
+Step is widened not to exceed the maximum number of points per series. NaN and Inf samples are skipped,
+because they can not be recorded as JSON.
+*/
+func (p *PrometheusProvider) queryRange(ctx context.Context, query string, start, end time.Time) ([]Series, error) {
+	step := p.step
+	if minStep := end.Sub(start) / prometheusMaxPoints; step < minStep {
+		step = minStep.Truncate(time.Second) + time.Second
+	}
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	response, err := p.query(ctx, "/api/v1/query_range", params, "matrix")
+	if err != nil {
+		return nil, err
+	}
+	seriesList := make([]Series, 0, len(response.Data.Result))
+	for _, result := range response.Data.Result {
+		samples := make([]Sample, 0, len(result.Values))
+		for _, value := range result.Values {
+			sample, ok := parsePrometheusValue(value)
+			if !ok {
+				continue
+			}
+			samples = append(samples, sample)
+		}
+		seriesList = append(seriesList, newSeries(result.Metric, samples))
+	}
+	return seriesList, nil
+}
+
+/*
+ContainerUsage runs target cpu and memory queries as instant queries, and returns usage of each pod.
+
+$namespace, $container and $pods in the queries are replaced with namespace, container and regex which matches
+podNames. Usage is read from pod label of each series.
+*/
+func (p *PrometheusProvider) ContainerUsage(
+	ctx context.Context,
+	namespace, container string,
+	podNames []string,
+) ([]ContainerUsage, error) {
+	if len(podNames) == 0 {
+		return nil, fmt.Errorf("no running target pod to query usage")
+	}
+	podPatterns := make([]string, 0, len(podNames))
+	for _, podName := range podNames {
+		// escape regex meta characters, and backslash of them in PromQL string.
+		podPatterns = append(podPatterns, strings.ReplaceAll(regexp.QuoteMeta(podName), `\`, `\\`))
+	}
+	replacer := strings.NewReplacer(
+		"$namespace", namespace, "$container", container, "$pods", strings.Join(podPatterns, "|"),
+	)
+	at := time.Now()
+	cpuSeries, err := p.queryInstant(ctx, replacer.Replace(p.targetCPUQuery), at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query target cpu usage, %v", err)
+	}
+	memorySeries, err := p.queryInstant(ctx, replacer.Replace(p.targetMemoryQuery), at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query target memory usage, %v", err)
+	}
+	usages := make(map[string]*ContainerUsage, len(podNames))
+	for _, podName := range podNames {
+		usages[podName] = nil
+	}
+	setUsage := func(seriesList []Series, set func(usage *ContainerUsage, value float64)) {
+		for _, series := range seriesList {
+			podName := series.Labels["pod"]
+			if _, ok := usages[podName]; !ok || len(series.Samples) == 0 {
+				continue
+			}
+			if usages[podName] == nil {
+				usages[podName] = &ContainerUsage{PodName: podName, Timestamp: series.Samples[0].Timestamp}
+			}
+			set(usages[podName], series.Last)
+		}
+	}
+	setUsage(cpuSeries, func(usage *ContainerUsage, value float64) { usage.Cpu = value })
+	setUsage(memorySeries, func(usage *ContainerUsage, value float64) { usage.Memory = value })
+	var result []ContainerUsage
+	for _, podName := range podNames {
+		if usage := usages[podName]; usage != nil {
+			result = append(result, *usage)
+		}
+	}
+	return result, nil
+}
+
+// queryInstant runs instant query at the time and returns series of vector result, each of which has one sample.
+func (p *PrometheusProvider) queryInstant(ctx context.Context, query string, at time.Time) ([]Series, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", strconv.FormatInt(at.Unix(), 10))
+	response, err := p.query(ctx, "/api/v1/query", params, "vector")
+	if err != nil {
+		return nil, err
+	}
+	seriesList := make([]Series, 0, len(response.Data.Result))
+	for _, result := range response.Data.Result {
+		sample, ok := parsePrometheusValue(result.Value)
+		if !ok {
+			continue
+		}
+		seriesList = append(seriesList, newSeries(result.Metric, []Sample{sample}))
+	}
+	return seriesList, nil
+}
+
+// query requests Prometheus HTTP API of path, and returns the response which result type is resultType.
+func (p *PrometheusProvider) query(
+	ctx context.Context,
+	path string,
+	params url.Values,
+	resultType string,
+) (*prometheusResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus request, %v", err)
+	}
+	if token := os.Getenv(PrometheusBearerTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus, %v", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prometheus response, %v", err)
+	}
+	var response prometheusResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse prometheus response status %v, %v", res.StatusCode, err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed, %v: %v", response.ErrorType, response.Error)
+	}
+	if response.Data.ResultType != resultType {
+		return nil, fmt.Errorf("unexpected prometheus result type %v", response.Data.ResultType)
+	}
+	return &response, nil
+}
+
+// parsePrometheusValue returns Sample of a pair of unix timestamp and value, returns false when it can't be recorded.
+func parsePrometheusValue(value [2]interface{}) (Sample, bool) {
+	timestamp, ok := value[0].(float64)
+	if !ok {
+		return Sample{}, false
+	}
+	valueStr, ok := value[1].(string)
+	if !ok {
+		return Sample{}, false
+	}
+	v, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Sample{}, false
+	}
+	sec, frac := math.Modf(timestamp)
+	return Sample{
+		// Prometheus timestamp has millisecond precision.
+		Timestamp: time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC(),
+		Value:     v,
+	}, true
+}
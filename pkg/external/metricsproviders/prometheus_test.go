@@ -0,0 +1,179 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metricsproviders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusProviderCollect(t *testing.T) {
+	t.Setenv(PrometheusBearerTokenEnv, "test-token")
+	start := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	end := start.Add(5 * time.Minute)
+	// stand-in of Prometheus server which serves range query API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v1/query_range" || r.Header.Get("Authorization") != "Bearer test-token" ||
+			query.Get("start") != "1699872600" || query.Get("end") != "1699872900" || query.Get("step") != "30" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected request"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch query.Get("query") {
+		case `sum(rate(container_cpu_cfs_throttled_periods_total{namespace="sample"}[1m])) by (pod)`:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"pod":"sample-pod-0"},"values":[[1699872600,"0.5"],[1699872630.5,"1.5"],[1699872660,"NaN"]]},
+				{"metric":{"pod":"sample-pod-1"},"values":[[1699872600,"2"]]}
+			]}}`))
+		case `increase(http_requests_total[300s])`:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{},"values":[[1699872900,"1200"]]}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewPrometheusProvider(server.URL+"/", 30*time.Second, []Query{
+		{
+			Name:  "cpu-throttling",
+			Query: `sum(rate(container_cpu_cfs_throttled_periods_total{namespace="sample"}[1m])) by (pod)`,
+		},
+		{Name: "requests", Query: `increase(http_requests_total[$__range])`},
+		{Name: "invalid", Query: `sum(`},
+	})
+	results, err := provider.Collect(context.Background(), start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, []QueryResult{
+		{
+			Name:  "cpu-throttling",
+			Query: `sum(rate(container_cpu_cfs_throttled_periods_total{namespace="sample"}[1m])) by (pod)`,
+			Series: []Series{
+				{
+					Labels: map[string]string{"pod": "sample-pod-0"},
+					Samples: []Sample{
+						{Timestamp: start, Value: 0.5},
+						{Timestamp: start.Add(30*time.Second + 500*time.Millisecond), Value: 1.5},
+					},
+					Min: 0.5, Max: 1.5, Mean: 1, Last: 1.5,
+				},
+				{
+					Labels:  map[string]string{"pod": "sample-pod-1"},
+					Samples: []Sample{{Timestamp: start, Value: 2}},
+					Min:     2, Max: 2, Mean: 2, Last: 2,
+				},
+			},
+		},
+		{
+			Name:  "requests",
+			Query: `increase(http_requests_total[300s])`,
+			Series: []Series{
+				{
+					Labels:  map[string]string{},
+					Samples: []Sample{{Timestamp: end, Value: 1200}},
+					Min:     1200, Max: 1200, Mean: 1200, Last: 1200,
+				},
+			},
+		},
+		{
+			Name:  "invalid",
+			Query: `sum(`,
+			Error: "prometheus query failed, bad_data: parse error",
+		},
+	}, results)
+}
+
+func TestPrometheusProviderCollect_Failed(t *testing.T) {
+	start := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	provider := NewPrometheusProvider("http://127.0.0.1:0", 30*time.Second, []Query{{Name: "up", Query: "up"}})
+	_, err := provider.Collect(context.Background(), start, start)
+	assert.Error(t, err)
+
+	results, err := provider.Collect(context.Background(), start, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Error, "failed to query prometheus")
+}
+
+func TestPrometheusProviderContainerUsage(t *testing.T) {
+	t.Setenv(PrometheusBearerTokenEnv, "test-token")
+	// stand-in of Prometheus server which serves instant query API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v1/query" || r.Header.Get("Authorization") != "Bearer test-token" ||
+			query.Get("time") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected request"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch query.Get("query") {
+		case `cpu{namespace="sample-namespace",container="sample-container",pod=~"sample-pod-0|sample\\.pod-1"}`:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"sample-pod-0"},"value":[1699872600,"250.5"]},
+				{"metric":{"pod":"sample.pod-1"},"value":[1699872600,"NaN"]},
+				{"metric":{"pod":"other-pod"},"value":[1699872600,"900"]}
+			]}}`))
+		case `memory{namespace="sample-namespace",container="sample-container",pod=~"sample-pod-0|sample\\.pod-1"}`:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"sample-pod-0"},"value":[1699872600,"1048576"]},
+				{"metric":{"pod":"sample.pod-1"},"value":[1699872600,"2097152"]}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewUsageProvider(Options{
+		Type:              ProviderPrometheus,
+		Address:           server.URL,
+		TargetCPUQuery:    `cpu{namespace="$namespace",container="$container",pod=~"$pods"}`,
+		TargetMemoryQuery: `memory{namespace="$namespace",container="$container",pod=~"$pods"}`,
+	})
+	assert.NoError(t, err)
+	usages, err := provider.ContainerUsage(
+		context.Background(), "sample-namespace", "sample-container", []string{"sample-pod-0", "sample.pod-1"},
+	)
+	assert.NoError(t, err)
+	at := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	assert.Equal(t, []ContainerUsage{
+		{PodName: "sample-pod-0", Timestamp: at, Cpu: 250.5, Memory: 1048576},
+		{PodName: "sample.pod-1", Timestamp: at, Memory: 2097152},
+	}, usages)
+
+	_, err = provider.ContainerUsage(context.Background(), "sample-namespace", "sample-container", nil)
+	assert.Error(t, err)
+	_, err = provider.ContainerUsage(context.Background(), "other-namespace", "sample-container", []string{"pod"})
+	assert.ErrorContains(t, err, "failed to query target cpu usage, prometheus query failed, bad_data: parse error")
+}
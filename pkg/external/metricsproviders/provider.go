@@ -0,0 +1,165 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metricsproviders
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Provider runs queries over the loadtest window.
+type Provider interface {
+	/*
+		Collect returns result of each query from start to end.
+
+		Failure of a query is recorded to Error of its QueryResult, not to lose the results of the other queries.
+	*/
+	Collect(ctx context.Context, start, end time.Time) ([]QueryResult, error)
+}
+
+// UsageProvider supplies resources usage of target container, instead of metrics-server.
+type UsageProvider interface {
+	/*
+		ContainerUsage returns current cpu and memory usage of container of each pod in podNames.
+
+		Pods of which usage is not found are not returned.
+	*/
+	ContainerUsage(ctx context.Context, namespace, container string, podNames []string) ([]ContainerUsage, error)
+}
+
+// ContainerUsage has cpu and memory usage of a container of a pod at a time.
+type ContainerUsage struct {
+	PodName   string
+	Timestamp time.Time
+	Cpu       float64 // milli vCPU
+	Memory    float64 // bytes
+}
+
+// Query has query which result is recorded under Name.
+type Query struct {
+	Name  string
+	Query string
+}
+
+// QueryResult has result of a query over the loadtest window.
+type QueryResult struct {
+	Name   string   `json:"name"`
+	Query  string   `json:"query"`
+	Series []Series `json:"series"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Series has samples and their statistics of a time series, which is identified by Labels.
+type Series struct {
+	Labels  map[string]string `json:"labels"`
+	Samples []Sample          `json:"samples"`
+	Min     float64           `json:"min"`
+	Max     float64           `json:"max"`
+	Mean    float64           `json:"mean"`
+	Last    float64           `json:"last"`
+}
+
+// Sample has value of a time series at a time.
+type Sample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Options has settings of metrics provider.
+type Options struct {
+	Type    string
+	Address string
+	// Step is resolution of range query, and DefaultStep is used when it is 0.
+	Step    time.Duration
+	Queries []Query
+	/*
+		TargetCPUQuery and TargetMemoryQuery are queries of usage of target container used by UsageProvider.
+		DefaultTargetCPUQuery and DefaultTargetMemoryQuery are used when they are empty.
+	*/
+	TargetCPUQuery    string
+	TargetMemoryQuery string
+}
+
+const (
+	// ProviderPrometheus is type of provider which queries Prometheus HTTP API.
+	ProviderPrometheus = "prometheus"
+	// DefaultStep is resolution of range query used when step is not specified.
+	DefaultStep = 15 * time.Second
+)
+
+// NewProvider returns Provider of opts.Type.
+func NewProvider(opts Options) (Provider, error) {
+	provider, err := newPrometheusProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// NewUsageProvider returns UsageProvider of opts.Type.
+func NewUsageProvider(opts Options) (UsageProvider, error) {
+	provider, err := newPrometheusProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// newPrometheusProvider returns PrometheusProvider of opts, prometheus is the only supported type.
+func newPrometheusProvider(opts Options) (*PrometheusProvider, error) {
+	if opts.Type != ProviderPrometheus {
+		return nil, fmt.Errorf("unsupported metrics provider %q", opts.Type)
+	}
+	step := opts.Step
+	if step == 0 {
+		step = DefaultStep
+	}
+	provider := NewPrometheusProvider(opts.Address, step, opts.Queries)
+	if opts.TargetCPUQuery != "" {
+		provider.targetCPUQuery = opts.TargetCPUQuery
+	}
+	if opts.TargetMemoryQuery != "" {
+		provider.targetMemoryQuery = opts.TargetMemoryQuery
+	}
+	return provider, nil
+}
+
+// newSeries returns Series which has statistics of samples.
+func newSeries(labels map[string]string, samples []Sample) Series {
+	series := Series{Labels: labels, Samples: samples}
+	if len(samples) == 0 {
+		return series
+	}
+	series.Min = samples[0].Value
+	series.Max = samples[0].Value
+	var sum float64
+	for _, sample := range samples {
+		series.Min = min(series.Min, sample.Value)
+		series.Max = max(series.Max, sample.Value)
+		sum += sample.Value
+	}
+	series.Mean = sum / float64(len(samples))
+	series.Last = samples[len(samples)-1].Value
+	return series
+}
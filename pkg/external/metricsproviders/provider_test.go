@@ -0,0 +1,88 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package metricsproviders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		expected  Provider
+		expectErr bool
+	}{
+		{
+			name:     "prometheus with default step",
+			opts:     Options{Type: ProviderPrometheus, Address: "http://localhost:9090"},
+			expected: NewPrometheusProvider("http://localhost:9090", DefaultStep, nil),
+		},
+		{
+			name: "prometheus with target usage queries",
+			opts: Options{Type: ProviderPrometheus, Address: "http://localhost:9090", TargetCPUQuery: "cpu"},
+			expected: &PrometheusProvider{
+				address:           "http://localhost:9090",
+				step:              DefaultStep,
+				targetCPUQuery:    "cpu",
+				targetMemoryQuery: DefaultTargetMemoryQuery,
+				httpClient:        NewPrometheusProvider("http://localhost:9090", DefaultStep, nil).httpClient,
+			},
+		},
+		{
+			name:      "unsupported provider",
+			opts:      Options{Type: "datadog"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.opts)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, provider)
+		})
+	}
+}
+
+func TestNewSeries(t *testing.T) {
+	at := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	series := newSeries(map[string]string{"pod": "sample-pod"}, []Sample{
+		{Timestamp: at, Value: 3},
+		{Timestamp: at.Add(15 * time.Second), Value: 1},
+		{Timestamp: at.Add(30 * time.Second), Value: 2},
+	})
+	assert.Equal(t, 1.0, series.Min)
+	assert.Equal(t, 3.0, series.Max)
+	assert.Equal(t, 2.0, series.Mean)
+	assert.Equal(t, 2.0, series.Last)
+
+	empty := newSeries(map[string]string{}, nil)
+	assert.Equal(t, Series{Labels: map[string]string{}}, empty)
+}
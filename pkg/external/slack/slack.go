@@ -25,8 +25,10 @@ package slack
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type slackOperator struct {
@@ -52,12 +54,13 @@ func (op *slackOperator) Notify(msg string) error {
 }
 
 /*
-GenerateSlackPayloadData generates slack payload data with arguments mention and isSuccess.
+GenerateSlackPayloadData generates slack payload data with arguments mention, isSuccess and details.
 
 The argument mention specifies the target of the mentions. The format of string is <@memberID>.
 The argument isSuccess is condition which decide message color and its fields value.
+The argument details is lines added as loadtest details field, the field is omitted when details is empty.
 */
-func GenerateSlackPayloadData(mention string, isSuccess bool) string {
+func GenerateSlackPayloadData(mention string, isSuccess bool, details []string) string {
 	var color string
 	var msg string
 
@@ -80,10 +83,25 @@ func GenerateSlackPayloadData(mention string, isSuccess bool) string {
 						"title": "loadtest result",
 						"value": "%v",
 						"short": false,
-					}
+					}%v
 				]
 			}
 		]
-	}`, mention, color, msg)
+	}`, mention, color, msg, generateDetailsField(details))
 	return data
 }
+
+// generateDetailsField generates loadtest details field of slack payload, empty string when details is empty.
+func generateDetailsField(details []string) string {
+	if len(details) == 0 {
+		return ""
+	}
+	// Marshal escapes the value to be embedded in json string.
+	value, _ := json.Marshal(strings.Join(details, "\n"))
+	return fmt.Sprintf(`,
+					{
+						"title": "loadtest details",
+						"value": %s,
+						"short": false,
+					}`, value)
+}
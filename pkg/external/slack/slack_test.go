@@ -79,7 +79,7 @@ func TestGenerateSlackPayloadDataColor(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data := GenerateSlackPayloadData("", tt.isSuccess)
+			data := GenerateSlackPayloadData("", tt.isSuccess, nil)
 			assert.Equal(t, cleaner.ReplaceAllString(tt.expected, ""), cleaner.ReplaceAllString(data, ""))
 		})
 	}
@@ -135,8 +135,35 @@ func TestGenerateSlackPayloadDataMention(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data := GenerateSlackPayloadData(tt.mention, true)
+			data := GenerateSlackPayloadData(tt.mention, true, nil)
 			assert.Equal(t, cleaner.ReplaceAllString(tt.expected, ""), cleaner.ReplaceAllString(data, ""))
 		})
 	}
 }
+
+func TestGenerateSlackPayloadDataDetails(t *testing.T) {
+	cleaner := regexp.MustCompile(`[\n\t]`)
+	expected := `{
+		"text": "",
+		"attachments": [
+			{
+				"color": "good",
+				"text": "monitor loadtest status",
+				"fields": [
+					{
+						"title": "loadtest result",
+						"value": "loadtest execution succeeded",
+						"short": false,
+					},
+					{
+						"title": "loadtest details",
+						"value": "service a \"saturated\"\nservice b",
+						"short": false,
+					}
+				]
+			}
+		]
+	}`
+	data := GenerateSlackPayloadData("", true, []string{`service a "saturated"`, "service b"})
+	assert.Equal(t, cleaner.ReplaceAllString(expected, ""), cleaner.ReplaceAllString(data, ""))
+}
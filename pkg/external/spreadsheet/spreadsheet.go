@@ -77,6 +77,10 @@ The report example table is below.
 | 99%ile latency (ms) | failed | t < 800 | 800 < t <= 1200 | 1200 < t | cpu usage mean (%) | memory usage mean (%) |
 
 |          674        |    0   |   100   |         0       |     0    |         15.5       |         22.3          |
+
+|        baseline comparison         | load generator | target pod health |
+
+| no regression vs 202311131050-1a2b |       ok       |      healthy      |
 */
 type loadtestReportRow struct {
 	subName                                               string
@@ -95,6 +99,43 @@ type loadtestReportRow struct {
 	overOneThousandTwoHundredMilliSecPercentage           float64
 	cpuUsePercentage                                      float64
 	memoryUsePercentage                                   float64
+	baselineComparison                                    string
+	loadGenerator                                         string
+	targetHealth                                          string
+}
+
+/*
+ColumnLabels has labels of report columns which depend on gatling.conf of the simulation.
+
+Percentiles are percentile of 4 percentile latency columns such as "99.9", and ResponseTimeGroups are names of
+response time group columns such as "t < 800 ms". Empty label is replaced with the label of default gatling.conf.
+*/
+type ColumnLabels struct {
+	Percentiles        [4]string
+	ResponseTimeGroups [3]string
+}
+
+// defaultColumnLabels is ColumnLabels of default gatling.conf.
+var defaultColumnLabels = ColumnLabels{
+	Percentiles:        [4]string{"50", "75", "95", "99"},
+	ResponseTimeGroups: [3]string{"t < 800", "800 < t <= 1200", "1200 < t"},
+}
+
+// columnNames returns column names of percentile latency and response time group filled with default labels.
+func (l ColumnLabels) columnNames() (percentiles [4]string, responseTimeGroups [3]string) {
+	for i, label := range l.Percentiles {
+		if label == "" {
+			label = defaultColumnLabels.Percentiles[i]
+		}
+		percentiles[i] = fmt.Sprintf("%v%%ile latency (ms)", label)
+	}
+	for i, label := range l.ResponseTimeGroups {
+		if label == "" {
+			label = defaultColumnLabels.ResponseTimeGroups[i]
+		}
+		responseTimeGroups[i] = label
+	}
+	return percentiles, responseTimeGroups
 }
 
 // NewLoadtestCommonSettingRow creates loadtestCommonSettingRow objects.
@@ -113,6 +154,7 @@ func NewLoadtestReportRow(
 	nintyFifthPercentilesLatency, nintyNinthPercentilesLatency, failedPercentage,
 	underEightHundredMilliSecPercentage, eightHundredToOneThousandTwoHundredMilliSecPercentage,
 	overOneThousandTwoHundredMilliSecPercentage, cpuUsePercentage, memoryUsePercentage float64,
+	baselineComparison, loadGenerator, targetHealth string,
 ) loadtestReportRow {
 	return loadtestReportRow{
 		subName:                             subName,
@@ -131,6 +173,9 @@ func NewLoadtestReportRow(
 		overOneThousandTwoHundredMilliSecPercentage:           overOneThousandTwoHundredMilliSecPercentage,
 		cpuUsePercentage:    cpuUsePercentage,
 		memoryUsePercentage: memoryUsePercentage,
+		baselineComparison:  baselineComparison,
+		loadGenerator:       loadGenerator,
+		targetHealth:        targetHealth,
 	}
 }
 
@@ -191,8 +236,12 @@ func (op *spreadsheetOperator) FindSheet(sheetTitle string) (*sheets.Sheet, erro
 	return foundSheet, nil
 }
 
-// SetCellName method set column header to sheet and returns updated sheet.
-func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet) (*sheets.Sheet, error) {
+/*
+SetColumnHeader method set column header to sheet and returns updated sheet.
+
+Labels of percentile latency and response time group columns are taken from labels.
+*/
+func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet, labels ColumnLabels) (*sheets.Sheet, error) {
 	// sheets.ExtendedValue StringValue field need pointer,
 	// so assign string value to var and give its pointer to map value.
 	commonSettingHeader := struct {
@@ -205,6 +254,7 @@ func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet) (*shee
 		targetLatencyColumnName: "targetLatency",
 	}
 	commonSettingHeaderColumnNum := reflect.TypeOf(commonSettingHeader).NumField()
+	percentileColumnNames, responseTimeGroupColumnNames := labels.columnNames()
 
 	gatlingReportHeader := struct {
 		subNameColumnName                                                     string
@@ -223,6 +273,9 @@ func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet) (*shee
 		overOneThousandTwoHundredMilliSecCountColumnName                      string
 		cpuUsePercentageColumnName                                            string
 		memoryUsePercentageColumnName                                         string
+		baselineComparisonColumnName                                          string
+		loadGeneratorColumnName                                               string
+		targetHealthColumnName                                                string
 	}{
 		subNameColumnName:                        "subName",
 		conditionColumnName:                      "condition",
@@ -230,16 +283,19 @@ func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet) (*shee
 		concurrencyColumnName:                    "concurrency (req/s)",
 		maxLatencyColumnName:                     "max (ms)",
 		meanLatencyColumnName:                    "mean (ms)",
-		fiftiethPercentileColumnName:             "50%ile latency (ms)",
-		seventyfifthPercentileColumnName:         "75%ile latency (ms)",
-		ninetyfifthPercentileColumnName:          "95%ile latency (ms)",
-		ninetyninthPercentileColumnName:          "99%ile latency (ms)",
+		fiftiethPercentileColumnName:             percentileColumnNames[0],
+		seventyfifthPercentileColumnName:         percentileColumnNames[1],
+		ninetyfifthPercentileColumnName:          percentileColumnNames[2],
+		ninetyninthPercentileColumnName:          percentileColumnNames[3],
 		failedPercentageColumnName:               "failed",
-		underEightHundredMilliSecCountColumnName: "t < 800",
-		betweenFromEightHundredToOneThousandTwoHundredMilliSecCountColumnName: "800 < t <= 1200",
-		overOneThousandTwoHundredMilliSecCountColumnName:                      "1200 < t",
+		underEightHundredMilliSecCountColumnName: responseTimeGroupColumnNames[0],
+		betweenFromEightHundredToOneThousandTwoHundredMilliSecCountColumnName: responseTimeGroupColumnNames[1],
+		overOneThousandTwoHundredMilliSecCountColumnName:                      responseTimeGroupColumnNames[2],
 		cpuUsePercentageColumnName:                                            "cpu usage mean (%)",
 		memoryUsePercentageColumnName:                                         "memory usage mean (%)",
+		baselineComparisonColumnName:                                          "baseline comparison",
+		loadGeneratorColumnName:                                               "load generator",
+		targetHealthColumnName:                                                "target pod health",
 	}
 	gatlingReportHeaderColumnNum := reflect.TypeOf(gatlingReportHeader).NumField()
 
@@ -380,6 +436,21 @@ func (op *spreadsheetOperator) SetColumnHeader(targetSheet *sheets.Sheet) (*shee
 										StringValue: &gatlingReportHeader.memoryUsePercentageColumnName,
 									},
 								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &gatlingReportHeader.baselineComparisonColumnName,
+									},
+								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &gatlingReportHeader.loadGeneratorColumnName,
+									},
+								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &gatlingReportHeader.targetHealthColumnName,
+									},
+								},
 							},
 						},
 					},
@@ -560,6 +631,21 @@ func (op *spreadsheetOperator) AppendLoadtestReportRow(
 										NumberValue: &row.memoryUsePercentage,
 									},
 								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &row.baselineComparison,
+									},
+								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &row.loadGenerator,
+									},
+								},
+								{
+									UserEnteredValue: &sheets.ExtendedValue{
+										StringValue: &row.targetHealth,
+									},
+								},
 							},
 						},
 					},
@@ -139,3 +139,39 @@ func TestDoBatchUpdateFail(t *testing.T) {
 		})
 	}
 }
+
+func TestColumnLabelsColumnNames(t *testing.T) {
+	tests := []struct {
+		name                       string
+		labels                     ColumnLabels
+		expectedPercentiles        [4]string
+		expectedResponseTimeGroups [3]string
+	}{
+		{
+			name:   "default labels",
+			labels: ColumnLabels{},
+			expectedPercentiles: [4]string{
+				"50%ile latency (ms)", "75%ile latency (ms)", "95%ile latency (ms)", "99%ile latency (ms)",
+			},
+			expectedResponseTimeGroups: [3]string{"t < 800", "800 < t <= 1200", "1200 < t"},
+		},
+		{
+			name: "labels of customized gatling.conf",
+			labels: ColumnLabels{
+				Percentiles:        [4]string{"50", "90", "99", "99.9"},
+				ResponseTimeGroups: [3]string{"t < 200 ms", "200 ms <= t < 500 ms", "t >= 500 ms"},
+			},
+			expectedPercentiles: [4]string{
+				"50%ile latency (ms)", "90%ile latency (ms)", "99%ile latency (ms)", "99.9%ile latency (ms)",
+			},
+			expectedResponseTimeGroups: [3]string{"t < 200 ms", "200 ms <= t < 500 ms", "t >= 500 ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percentiles, responseTimeGroups := tt.labels.columnNames()
+			assert.Equal(t, tt.expectedPercentiles, percentiles)
+			assert.Equal(t, tt.expectedResponseTimeGroups, responseTimeGroups)
+		})
+	}
+}
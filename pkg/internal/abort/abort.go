@@ -0,0 +1,228 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package abort implements rules for aborting running loadtest by live signals of target and runner.
+package abort
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"k8s.io/client-go/kubernetes"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	gatlingTools "github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+)
+
+// Target has target pods selector and clients used by Monitor.
+type Target struct {
+	PodConfig cfg.TargetPodConfig
+	// ResourcesLimit is resources limit of target container, used for usage rules.
+	ResourcesLimit kubeapi.MetricsField
+	PodCl          ctrlClient.Client
+	// Metrics is source of resources usage of target container, metrics-server or metrics provider.
+	Metrics kubeapi.MetricsSource
+}
+
+// Monitor implements gatlingTools.AbortChecker interface with abortRules of service config.
+type Monitor struct {
+	rules     cfg.AbortRulesConfig
+	targets   []Target
+	runnerCs  kubernetes.Interface
+	gatling   *gatlingv1alpha1.Gatling
+	startedAt time.Time
+	// initialRestarts has restart count of target container of each pod of each target when monitor started.
+	initialRestarts []map[string]int32
+	// exceededSince has time from which each usage threshold of each target is continuously exceeded.
+	exceededSince map[string]time.Time
+	now           func() time.Time
+}
+
+/*
+NewMonitor returns initialized Monitor value.
+
+Restart count of target container of each target is recorded at this time, so restarts before loadtest are not
+counted. runnerCs is used for reading runner pod logs, and maxRunnerErrors rule is skipped when it is nil.
+*/
+func NewMonitor(
+	ctx context.Context,
+	rules cfg.AbortRulesConfig,
+	targets []Target,
+	runnerCs kubernetes.Interface,
+	gatling *gatlingv1alpha1.Gatling,
+) (*Monitor, error) {
+	m := &Monitor{
+		rules:           rules,
+		targets:         targets,
+		runnerCs:        runnerCs,
+		gatling:         gatling,
+		initialRestarts: make([]map[string]int32, len(targets)),
+		exceededSince:   map[string]time.Time{},
+		now:             time.Now,
+	}
+	m.startedAt = m.now()
+	for i, target := range targets {
+		statuses, err := kubeapi.FetchContainerStatuses(ctx, target.PodCl, target.PodConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch target %v container statuses, %v", target.PodConfig.Name, err)
+		}
+		m.initialRestarts[i] = map[string]int32{}
+		for _, status := range statuses {
+			m.initialRestarts[i][status.PodName] = status.RestartCount
+		}
+	}
+	return m, nil
+}
+
+/*
+CheckAbort returns abort reason when one of abort rules is met.
+
+Rules are checked in order of OOMKilled, restarts, cpu and memory usage of each target, runner errors.
+Even if some rule can not be checked, the other rules are checked and the first error is returned with reason.
+*/
+func (m *Monitor) CheckAbort(ctx context.Context) (string, error) {
+	var rules []func(context.Context) (string, error)
+	for i := range m.targets {
+		rules = append(rules,
+			func(ctx context.Context) (string, error) { return m.checkRestarts(ctx, i) },
+			func(ctx context.Context) (string, error) { return m.checkUsage(ctx, i) },
+		)
+	}
+	rules = append(rules, m.checkRunnerErrors)
+
+	var firstErr error
+	for _, rule := range rules {
+		reason, err := rule(ctx)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if reason != "" {
+			return reason, firstErr
+		}
+	}
+	return "", firstErr
+}
+
+// checkRestarts checks OOMKilled and restart count of target container of i-th target after monitor started.
+func (m *Monitor) checkRestarts(ctx context.Context, i int) (string, error) {
+	if !m.rules.AbortOnOOMKilled && m.rules.MaxRestarts == nil {
+		return "", nil
+	}
+	target := m.targets[i]
+	statuses, err := kubeapi.FetchContainerStatuses(ctx, target.PodCl, target.PodConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch target %v container statuses, %v", target.PodConfig.Name, err)
+	}
+	var restarts int32
+	for _, status := range statuses {
+		if m.rules.AbortOnOOMKilled && status.LastTerminationReason == "OOMKilled" &&
+			status.LastTerminatedAt.After(m.startedAt) {
+			return fmt.Sprintf(
+				"target %v container in pod %v was OOMKilled", target.PodConfig.Name, status.PodName,
+			), nil
+		}
+		// pods created after monitor started are counted from 0.
+		restarts += status.RestartCount - m.initialRestarts[i][status.PodName]
+	}
+	if m.rules.MaxRestarts != nil && restarts > *m.rules.MaxRestarts {
+		return fmt.Sprintf(
+			"target %v container restarted %v times, exceeded maxRestarts %v",
+			target.PodConfig.Name, restarts, *m.rules.MaxRestarts,
+		), nil
+	}
+	return "", nil
+}
+
+/*
+checkUsage checks cpu and memory usage of target container of i-th target against resources limit.
+
+Usage of the most loaded pod is used, and abort when it exceeds threshold continuously for sustainedSec.
+*/
+func (m *Monitor) checkUsage(ctx context.Context, i int) (string, error) {
+	if m.rules.CPUPercent == 0 && m.rules.MemoryPercent == 0 {
+		return "", nil
+	}
+	target := m.targets[i]
+	metricses, err := target.Metrics.FetchContainerMetrics(ctx, target.PodConfig)
+	if err != nil {
+		return "", err
+	}
+	var maxUsage kubeapi.MetricsField
+	for _, metrics := range metricses {
+		maxUsage.Cpu = max(maxUsage.Cpu, metrics.Cpu)
+		maxUsage.Memory = max(maxUsage.Memory, metrics.Memory)
+	}
+	limit := target.ResourcesLimit
+	usages := []struct {
+		name      string
+		threshold float64
+		usage     int64
+		limit     int64
+	}{
+		{name: "cpu", threshold: m.rules.CPUPercent, usage: maxUsage.Cpu, limit: limit.Cpu},
+		{name: "memory", threshold: m.rules.MemoryPercent, usage: maxUsage.Memory, limit: limit.Memory},
+	}
+	now := m.now()
+	for _, u := range usages {
+		if u.threshold == 0 || u.limit == 0 {
+			continue
+		}
+		key := fmt.Sprintf("%v/%v", target.PodConfig.Name, u.name)
+		percent := kubeapi.CalcAndRoundMetricsRatio(u.usage, u.limit) * 100
+		if percent <= u.threshold {
+			delete(m.exceededSince, key)
+			continue
+		}
+		since, ok := m.exceededSince[key]
+		if !ok {
+			since = now
+			m.exceededSince[key] = since
+		}
+		sustained := now.Sub(since)
+		if sustained >= time.Duration(m.rules.SustainedSec)*time.Second {
+			return fmt.Sprintf(
+				"target %v container %v usage %.1f%% exceeded %v%% for %v",
+				target.PodConfig.Name, u.name, percent, u.threshold, sustained.Round(time.Second),
+			), nil
+		}
+	}
+	return "", nil
+}
+
+// checkRunnerErrors checks KO request count printed in runner pod logs.
+func (m *Monitor) checkRunnerErrors(ctx context.Context) (string, error) {
+	if m.rules.MaxRunnerErrors == nil || m.runnerCs == nil {
+		return "", nil
+	}
+	ko, err := gatlingTools.CountRunnerKO(ctx, m.runnerCs, m.gatling)
+	if err != nil {
+		return "", err
+	}
+	if ko > *m.rules.MaxRunnerErrors {
+		return fmt.Sprintf("runner KO requests %v exceeded maxRunnerErrors %v", ko, *m.rules.MaxRunnerErrors), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package abort
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsFake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+)
+
+var samplePodConfig = cfg.TargetPodConfig{
+	Name:          "sample-target",
+	Namespace:     "sample-namespace",
+	LabelKey:      "app",
+	LabelValue:    "sample-app",
+	ContainerName: "sample-container",
+}
+
+func newSamplePod(name string, restartCount int32, lastTerminated *corev1.ContainerStateTerminated) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: samplePodConfig.Namespace,
+			Labels:    map[string]string{samplePodConfig.LabelKey: samplePodConfig.LabelValue},
+		},
+		Status: corev1.PodStatus{
+			Phase: "Running",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 samplePodConfig.ContainerName,
+					RestartCount:         restartCount,
+					LastTerminationState: corev1.ContainerState{Terminated: lastTerminated},
+				},
+			},
+		},
+	}
+}
+
+func newSamplePodMetrics(cpu int64, memory int64) *v1beta1.PodMetrics {
+	return &v1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sample-pod",
+			Namespace: samplePodConfig.Namespace,
+			Labels:    map[string]string{samplePodConfig.LabelKey: samplePodConfig.LabelValue},
+		},
+		Containers: []v1beta1.ContainerMetrics{
+			{
+				Name: samplePodConfig.ContainerName,
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%vm", cpu)),
+					corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%v", memory)),
+				},
+			},
+		},
+	}
+}
+
+func TestCheckAbort_Restarts(t *testing.T) {
+	maxRestarts := int32(1)
+	tests := []struct {
+		name           string
+		rules          cfg.AbortRulesConfig
+		updatedPod     *corev1.Pod
+		expectedReason string
+	}{
+		{
+			name:           "restarted within maxRestarts",
+			rules:          cfg.AbortRulesConfig{MaxRestarts: &maxRestarts},
+			updatedPod:     newSamplePod("sample-pod", 4, nil),
+			expectedReason: "",
+		},
+		{
+			name:           "restarted more than maxRestarts",
+			rules:          cfg.AbortRulesConfig{MaxRestarts: &maxRestarts},
+			updatedPod:     newSamplePod("sample-pod", 5, nil),
+			expectedReason: "target sample-target container restarted 2 times, exceeded maxRestarts 1",
+		},
+		{
+			name:  "OOMKilled after monitor started",
+			rules: cfg.AbortRulesConfig{AbortOnOOMKilled: true},
+			updatedPod: newSamplePod("sample-pod", 4, &corev1.ContainerStateTerminated{
+				Reason:     "OOMKilled",
+				FinishedAt: metav1.NewTime(time.Now().Add(time.Minute)),
+			}),
+			expectedReason: "target sample-target container in pod sample-pod was OOMKilled",
+		},
+		{
+			name:  "OOMKilled before monitor started is ignored",
+			rules: cfg.AbortRulesConfig{AbortOnOOMKilled: true},
+			updatedPod: newSamplePod("sample-pod", 3, &corev1.ContainerStateTerminated{
+				Reason:     "OOMKilled",
+				FinishedAt: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}),
+			expectedReason: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			cl := kubeutil.InitFakeClient()
+			// the pod has restarted 3 times before loadtest
+			assert.NoError(t, cl.Create(ctx, newSamplePod("sample-pod", 3, nil)))
+			m, err := NewMonitor(ctx, tt.rules, []Target{
+				{
+					PodConfig: samplePodConfig,
+					PodCl:     cl,
+					Metrics:   kubeapi.MetricsServerSource{Client: metricsFake.NewSimpleClientset()},
+				},
+			}, nil, nil)
+			assert.NoError(t, err)
+			assert.NoError(t, cl.Status().Update(ctx, tt.updatedPod))
+			reason, err := m.CheckAbort(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestCheckAbort_Usage(t *testing.T) {
+	limit := kubeapi.MetricsField{Cpu: 1000, Memory: 1000}
+	tests := []struct {
+		name           string
+		rules          cfg.AbortRulesConfig
+		usage          kubeapi.MetricsField
+		elapsed        []time.Duration
+		expectedReason string
+	}{
+		{
+			name:           "cpu usage within threshold",
+			rules:          cfg.AbortRulesConfig{CPUPercent: 90},
+			usage:          kubeapi.MetricsField{Cpu: 900, Memory: 100},
+			elapsed:        []time.Duration{0},
+			expectedReason: "",
+		},
+		{
+			name:           "cpu usage exceeded threshold without sustainedSec",
+			rules:          cfg.AbortRulesConfig{CPUPercent: 90},
+			usage:          kubeapi.MetricsField{Cpu: 950, Memory: 100},
+			elapsed:        []time.Duration{0},
+			expectedReason: "target sample-target container cpu usage 95.0% exceeded 90% for 0s",
+		},
+		{
+			name:           "memory usage exceeded threshold shorter than sustainedSec",
+			rules:          cfg.AbortRulesConfig{MemoryPercent: 90, SustainedSec: 60},
+			usage:          kubeapi.MetricsField{Cpu: 100, Memory: 950},
+			elapsed:        []time.Duration{0, 30 * time.Second},
+			expectedReason: "",
+		},
+		{
+			name:           "memory usage exceeded threshold for sustainedSec",
+			rules:          cfg.AbortRulesConfig{MemoryPercent: 90, SustainedSec: 60},
+			usage:          kubeapi.MetricsField{Cpu: 100, Memory: 950},
+			elapsed:        []time.Duration{0, 30 * time.Second, 60 * time.Second},
+			expectedReason: "target sample-target container memory usage 95.0% exceeded 90% for 1m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			podMetrics := newSamplePodMetrics(tt.usage.Cpu, tt.usage.Memory)
+			metricsCl := metricsFake.NewSimpleClientset(podMetrics)
+			gvr := schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+			_ = metricsCl.Tracker().Create(gvr, podMetrics, podMetrics.ObjectMeta.Namespace)
+			m, err := NewMonitor(ctx, tt.rules, []Target{
+				{
+					PodConfig:      samplePodConfig,
+					ResourcesLimit: limit,
+					PodCl:          kubeutil.InitFakeClient(),
+					Metrics:        kubeapi.MetricsServerSource{Client: metricsCl},
+				},
+			}, nil, nil)
+			assert.NoError(t, err)
+			startedAt := time.Now()
+			var reason string
+			for _, elapsed := range tt.elapsed {
+				m.now = func() time.Time { return startedAt.Add(elapsed) }
+				reason, err = m.CheckAbort(ctx)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestCheckAbort_MultipleTargets(t *testing.T) {
+	ctx := context.TODO()
+	cl := kubeutil.InitFakeClient()
+	cachePodConfig := cfg.TargetPodConfig{
+		Name:      "sample-cache",
+		Namespace: samplePodConfig.Namespace,
+		LabelSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "component", Operator: metav1.LabelSelectorOpIn, Values: []string{"cache"}},
+			},
+		},
+		ContainerName: samplePodConfig.ContainerName,
+	}
+	cachePod := newSamplePod("sample-cache-pod", 0, nil)
+	cachePod.ObjectMeta.Labels = map[string]string{"component": "cache"}
+	assert.NoError(t, cl.Create(ctx, newSamplePod("sample-pod", 0, nil)))
+	assert.NoError(t, cl.Create(ctx, cachePod))
+	metricsCl := metricsFake.NewSimpleClientset()
+	m, err := NewMonitor(ctx, cfg.AbortRulesConfig{AbortOnOOMKilled: true}, []Target{
+		{PodConfig: samplePodConfig, PodCl: cl, Metrics: kubeapi.MetricsServerSource{Client: metricsCl}},
+		{PodConfig: cachePodConfig, PodCl: cl, Metrics: kubeapi.MetricsServerSource{Client: metricsCl}},
+	}, nil, nil)
+	assert.NoError(t, err)
+
+	reason, err := m.CheckAbort(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "", reason)
+
+	oomKilledPod := newSamplePod("sample-cache-pod", 1, &corev1.ContainerStateTerminated{
+		Reason:     "OOMKilled",
+		FinishedAt: metav1.NewTime(time.Now().Add(time.Minute)),
+	})
+	oomKilledPod.ObjectMeta.Labels = cachePod.ObjectMeta.Labels
+	assert.NoError(t, cl.Status().Update(ctx, oomKilledPod))
+	reason, err = m.CheckAbort(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "target sample-cache container in pod sample-cache-pod was OOMKilled", reason)
+}
+
+func TestCheckAbort_NoRules(t *testing.T) {
+	ctx := context.TODO()
+	m, err := NewMonitor(
+		ctx,
+		cfg.AbortRulesConfig{},
+		[]Target{
+			{
+				PodConfig: samplePodConfig,
+				PodCl:     kubeutil.InitFakeClient(),
+				Metrics:   kubeapi.MetricsServerSource{Client: metricsFake.NewSimpleClientset()},
+			},
+		},
+		nil,
+		nil,
+	)
+	assert.NoError(t, err)
+	reason, err := m.CheckAbort(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "", reason)
+}
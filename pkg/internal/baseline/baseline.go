@@ -0,0 +1,239 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package baseline implements comparison of loadtest result with the one of baseline run to detect regression.
+package baseline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+)
+
+// Metric names compared with baseline.
+const (
+	MetricP95Latency       = "p95"
+	MetricP99Latency       = "p99"
+	MetricFailedPercentage = "failed"
+	MetricThroughput       = "throughput"
+	MetricCPUPerRequest    = "cpuPerRequest"
+)
+
+// Sources of latency, failed and throughput of Metrics.
+const (
+	SourceReport        = "report"
+	SourceSimulationLog = "simulation.log"
+)
+
+/*
+Metrics has values of loadtest result which are compared with baseline.
+
+Source is where latency, failed and throughput are read from, and WarmUpSec is the seconds excluded from the start of
+simulation.log. Metrics are only compared with the ones of the same Source and WarmUpSec.
+LatencyUnknown is true when p95 or p99 latency is not in percentiles of the report, then metrics are not compared.
+*/
+type Metrics struct {
+	P95Latency       float64 `json:"p95Latency"`       // ms
+	P99Latency       float64 `json:"p99Latency"`       // ms
+	FailedPercentage float64 `json:"failedPercentage"` // %
+	Throughput       float64 `json:"throughput"`       // req/s
+	CPUPerRequest    float64 `json:"cpuPerRequest"`    // mCPU per req/s, 0 when throughput is 0
+	Source           string  `json:"source,omitempty"`
+	WarmUpSec        int32   `json:"warmUpSec,omitempty"`
+	LatencyUnknown   bool    `json:"latencyUnknown,omitempty"`
+}
+
+/*
+Comparable returns whether metrics is read in the same way as other, so that they can be compared.
+
+Metrics recorded without Source are read from the report, and metrics of unknown latency are not comparable.
+*/
+func (m Metrics) Comparable(other Metrics) bool {
+	return m.source() == other.source() && m.WarmUpSec == other.WarmUpSec && !m.LatencyUnknown && !other.LatencyUnknown
+}
+
+// source returns Source of metrics, or SourceReport when it is not recorded.
+func (m Metrics) source() string {
+	if m.Source == "" {
+		return SourceReport
+	}
+	return m.Source
+}
+
+/*
+Thresholds has allowed change of each metric from baseline.
+
+Threshold value 0 means the metric is not compared.
+FailedIncreasePoint is compared by difference of percentage, and the others are compared by change rate.
+*/
+type Thresholds struct {
+	P95IncreasePercent           float64
+	P99IncreasePercent           float64
+	FailedIncreasePoint          float64
+	ThroughputDecreasePercent    float64
+	CPUPerRequestIncreasePercent float64
+}
+
+// Regression has metric which exceeded threshold.
+type Regression struct {
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	// Change is change rate (%) from baseline, or difference of percentage in failed metric.
+	Change float64 `json:"change"`
+}
+
+// Comparison is result of comparing loadtest result with baseline.
+type Comparison struct {
+	BaselineRunID string       `json:"baselineRunID"`
+	Baseline      Metrics      `json:"baseline"`
+	Regressions   []Regression `json:"regressions,omitempty"`
+}
+
+/*
+NewMetrics returns Metrics from gatling report and target container cpu usage mean (mCPU).
+
+Percentiles of the report depend on gatling.conf, so p95 and p99 latency are looked up by percentile. When either of
+them is not in the report nor derived from simulation.log, LatencyUnknown is set not to compare other percentile.
+*/
+func NewMetrics(report *gatling.GatlingReport, cpuUsageMean int64) Metrics {
+	metrics := Metrics{
+		FailedPercentage: report.Failed.Percentage,
+		Throughput:       report.MeanNumberOfRequestsPerSecond.Total,
+		Source:           SourceReport,
+	}
+	p95Latency, p95Err := report.GetPercentileLatency(95)
+	p99Latency, p99Err := report.GetPercentileLatency(99)
+	if p95Err != nil || p99Err != nil {
+		metrics.LatencyUnknown = true
+	} else {
+		metrics.P95Latency = p95Latency
+		metrics.P99Latency = p99Latency
+	}
+	if metrics.Throughput > 0 {
+		metrics.CPUPerRequest = float64(cpuUsageMean) / metrics.Throughput
+	}
+	return metrics
+}
+
+/*
+NewMetricsFromSimulationLog returns Metrics from summary of simulation.log and target container cpu usage mean (mCPU).
+
+Warm-up window of warmUpSec is excluded from summary, so that steady state of loadtest is compared with baseline.
+*/
+func NewMetricsFromSimulationLog(summary gatling.SimulationLogSummary, warmUpSec int32, cpuUsageMean int64) Metrics {
+	metrics := Metrics{
+		P95Latency:       summary.P95Latency,
+		P99Latency:       summary.P99Latency,
+		FailedPercentage: summary.FailedPercentage,
+		Throughput:       summary.Throughput,
+		Source:           SourceSimulationLog,
+		WarmUpSec:        warmUpSec,
+	}
+	if metrics.Throughput > 0 {
+		metrics.CPUPerRequest = float64(cpuUsageMean) / metrics.Throughput
+	}
+	return metrics
+}
+
+// Compare compares current metrics with baseline metrics and returns Comparison which has regressions.
+func Compare(baselineRunID string, baseline, current Metrics, thresholds Thresholds) Comparison {
+	comparison := Comparison{
+		BaselineRunID: baselineRunID,
+		Baseline:      baseline,
+	}
+	increased := func(metric string, baselineValue, currentValue, thresholdPercent float64) {
+		if thresholdPercent == 0 || baselineValue <= 0 {
+			return
+		}
+		change := (currentValue - baselineValue) / baselineValue * 100
+		if change > thresholdPercent {
+			comparison.Regressions = append(comparison.Regressions, Regression{
+				Metric:   metric,
+				Baseline: baselineValue,
+				Current:  currentValue,
+				Change:   change,
+			})
+		}
+	}
+	increased(MetricP95Latency, baseline.P95Latency, current.P95Latency, thresholds.P95IncreasePercent)
+	increased(MetricP99Latency, baseline.P99Latency, current.P99Latency, thresholds.P99IncreasePercent)
+	if thresholds.FailedIncreasePoint != 0 {
+		if change := current.FailedPercentage - baseline.FailedPercentage; change > thresholds.FailedIncreasePoint {
+			comparison.Regressions = append(comparison.Regressions, Regression{
+				Metric:   MetricFailedPercentage,
+				Baseline: baseline.FailedPercentage,
+				Current:  current.FailedPercentage,
+				Change:   change,
+			})
+		}
+	}
+	// Throughput regression is decrease, so compare with inverted sign.
+	if thresholds.ThroughputDecreasePercent != 0 && baseline.Throughput > 0 {
+		change := (current.Throughput - baseline.Throughput) / baseline.Throughput * 100
+		if -change > thresholds.ThroughputDecreasePercent {
+			comparison.Regressions = append(comparison.Regressions, Regression{
+				Metric:   MetricThroughput,
+				Baseline: baseline.Throughput,
+				Current:  current.Throughput,
+				Change:   change,
+			})
+		}
+	}
+	if current.CPUPerRequest > 0 {
+		increased(
+			MetricCPUPerRequest, baseline.CPUPerRequest, current.CPUPerRequest, thresholds.CPUPerRequestIncreasePercent,
+		)
+	}
+	return comparison
+}
+
+// Regressed returns whether any metric exceeded threshold.
+func (c *Comparison) Regressed() bool {
+	return c != nil && len(c.Regressions) > 0
+}
+
+/*
+Summary returns one line summary of comparison, it is written to spreadsheet and so on.
+
+Summary of nil Comparison means there is no baseline to compare.
+*/
+func (c *Comparison) Summary() string {
+	if c == nil {
+		return "no baseline"
+	}
+	if !c.Regressed() {
+		return fmt.Sprintf("no regression vs %v", c.BaselineRunID)
+	}
+	regressions := make([]string, 0, len(c.Regressions))
+	for _, r := range c.Regressions {
+		unit := "%"
+		if r.Metric == MetricFailedPercentage {
+			unit = "pt"
+		}
+		regressions = append(regressions, fmt.Sprintf(
+			"%v %+.1f%v (%.4g -> %.4g)", r.Metric, r.Change, unit, r.Baseline, r.Current,
+		))
+	}
+	return fmt.Sprintf("regression vs %v: %v", c.BaselineRunID, strings.Join(regressions, ", "))
+}
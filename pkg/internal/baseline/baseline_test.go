@@ -0,0 +1,207 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package baseline
+
+import (
+	"testing"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetrics(t *testing.T) {
+	report := &gatling.GatlingReport{
+		NintyFifthPercentiles:         gatling.GatlingReportStats{Ok: 90},
+		NintyNinthPercentiles:         gatling.GatlingReportStats{Ok: 120},
+		MeanNumberOfRequestsPerSecond: gatling.GatlingReportStats{Total: 50},
+		Failed:                        gatling.GatlingReportGroup{Percentage: 1},
+	}
+	assert.Equal(t, Metrics{
+		P95Latency:       90,
+		P99Latency:       120,
+		FailedPercentage: 1,
+		Throughput:       50,
+		CPUPerRequest:    10,
+		Source:           SourceReport,
+	}, NewMetrics(report, 500))
+
+	// cpu per request is not calculated without throughput.
+	assert.Equal(t, float64(0), NewMetrics(&gatling.GatlingReport{}, 500).CPUPerRequest)
+
+	// p99 is percentiles3 and p95 is not in the report customized by gatling.conf.
+	customized := *report
+	customized.Percentiles = []float64{50, 75, 99, 99.9}
+	assert.Equal(t, Metrics{
+		FailedPercentage: 1,
+		Throughput:       50,
+		CPUPerRequest:    10,
+		Source:           SourceReport,
+		LatencyUnknown:   true,
+	}, NewMetrics(&customized, 500))
+
+	// p95 derived from simulation.log is used.
+	customized.ExactPercentiles = map[string]float64{"95": 80}
+	assert.Equal(t, Metrics{
+		P95Latency:       80,
+		P99Latency:       90,
+		FailedPercentage: 1,
+		Throughput:       50,
+		CPUPerRequest:    10,
+		Source:           SourceReport,
+	}, NewMetrics(&customized, 500))
+}
+
+func TestNewMetricsFromSimulationLog(t *testing.T) {
+	summary := gatling.SimulationLogSummary{
+		P95Latency:       80,
+		P99Latency:       100,
+		FailedPercentage: 0.5,
+		Throughput:       40,
+	}
+	assert.Equal(t, Metrics{
+		P95Latency:       80,
+		P99Latency:       100,
+		FailedPercentage: 0.5,
+		Throughput:       40,
+		CPUPerRequest:    12.5,
+		Source:           SourceSimulationLog,
+		WarmUpSec:        60,
+	}, NewMetricsFromSimulationLog(summary, 60, 500))
+}
+
+func TestMetricsComparable(t *testing.T) {
+	report := Metrics{Source: SourceReport}
+	simulationLog := Metrics{Source: SourceSimulationLog, WarmUpSec: 60}
+	tests := []struct {
+		name     string
+		metrics  Metrics
+		other    Metrics
+		expected bool
+	}{
+		{name: "both from report", metrics: report, other: report, expected: true},
+		{name: "source not recorded is report", metrics: Metrics{}, other: report, expected: true},
+		{name: "same warm-up", metrics: simulationLog, other: simulationLog, expected: true},
+		{name: "different source", metrics: report, other: simulationLog, expected: false},
+		{
+			name:     "latency unknown",
+			metrics:  report,
+			other:    Metrics{Source: SourceReport, LatencyUnknown: true},
+			expected: false,
+		},
+		{
+			name:     "different warm-up",
+			metrics:  simulationLog,
+			other:    Metrics{Source: SourceSimulationLog, WarmUpSec: 30},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.metrics.Comparable(tt.other))
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baselineMetrics := Metrics{
+		P95Latency:       100,
+		P99Latency:       200,
+		FailedPercentage: 0.5,
+		Throughput:       100,
+		CPUPerRequest:    4,
+	}
+	thresholds := Thresholds{
+		P95IncreasePercent:           10,
+		P99IncreasePercent:           10,
+		FailedIncreasePoint:          1,
+		ThroughputDecreasePercent:    10,
+		CPUPerRequestIncreasePercent: 20,
+	}
+
+	tests := []struct {
+		name                string
+		current             Metrics
+		thresholds          Thresholds
+		expectedRegressions []Regression
+		expectedSummary     string
+	}{
+		{
+			name: "no regression within thresholds",
+			current: Metrics{
+				P95Latency:       110,
+				P99Latency:       210,
+				FailedPercentage: 1.5,
+				Throughput:       90,
+				CPUPerRequest:    4.8,
+			},
+			thresholds:          thresholds,
+			expectedRegressions: nil,
+			expectedSummary:     "no regression vs 202311131050-1a2b",
+		},
+		{
+			name: "every metric regressed",
+			current: Metrics{
+				P95Latency:       120,
+				P99Latency:       300,
+				FailedPercentage: 2,
+				Throughput:       80,
+				CPUPerRequest:    6,
+			},
+			thresholds: thresholds,
+			expectedRegressions: []Regression{
+				{Metric: MetricP95Latency, Baseline: 100, Current: 120, Change: 20},
+				{Metric: MetricP99Latency, Baseline: 200, Current: 300, Change: 50},
+				{Metric: MetricFailedPercentage, Baseline: 0.5, Current: 2, Change: 1.5},
+				{Metric: MetricThroughput, Baseline: 100, Current: 80, Change: -20},
+				{Metric: MetricCPUPerRequest, Baseline: 4, Current: 6, Change: 50},
+			},
+			expectedSummary: "regression vs 202311131050-1a2b: p95 +20.0% (100 -> 120), p99 +50.0% (200 -> 300), " +
+				"failed +1.5pt (0.5 -> 2), throughput -20.0% (100 -> 80), cpuPerRequest +50.0% (4 -> 6)",
+		},
+		{
+			name: "metrics which threshold is 0 are not compared",
+			current: Metrics{
+				P95Latency: 1000,
+				P99Latency: 1000,
+			},
+			thresholds:          Thresholds{},
+			expectedRegressions: nil,
+			expectedSummary:     "no regression vs 202311131050-1a2b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := Compare("202311131050-1a2b", baselineMetrics, tt.current, tt.thresholds)
+			assert.Equal(t, tt.expectedRegressions, comparison.Regressions)
+			assert.Equal(t, tt.expectedRegressions != nil, comparison.Regressed())
+			assert.Equal(t, tt.expectedSummary, comparison.Summary())
+		})
+	}
+}
+
+func TestComparison_Nil(t *testing.T) {
+	var comparison *Comparison
+	assert.False(t, comparison.Regressed())
+	assert.Equal(t, "no baseline", comparison.Summary())
+}
@@ -0,0 +1,163 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package capacity implements searcher which finds the highest load that meets the target of service.
+package capacity
+
+import "fmt"
+
+// Strategy decides which load is tried next in capacity search.
+type Strategy string
+
+// Strategies of capacity search.
+const (
+	// StrategyStep increases load by step from start load until a trial fails or max load passes.
+	StrategyStep Strategy = "step"
+	// StrategyBisect tries start and max load, and bisects between highest passed and lowest failed load.
+	StrategyBisect Strategy = "bisect"
+)
+
+// Trial is result of one loadtest run in capacity search.
+type Trial struct {
+	Concurrency int    `json:"concurrency"`
+	Passed      bool   `json:"passed"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Result is outcome of capacity search.
+type Result struct {
+	Strategy Strategy `json:"strategy"`
+	// Found is false when even start load did not meet the target.
+	Found bool `json:"found"`
+	// Capacity is the highest load which met the target.
+	Capacity int     `json:"capacity"`
+	Trials   []Trial `json:"trials"`
+}
+
+/*
+Searcher decides load of each trial from results of previous trials.
+
+Searcher has no side effects, so caller runs loadtest with load returned by Next and records its result by Record
+until Next returns false.
+*/
+type Searcher struct {
+	strategy Strategy
+	start    int
+	max      int
+	step     int
+	passed   int // highest passed load, 0 when no trial passed
+	failed   int // lowest failed load, 0 when no trial failed
+	trials   []Trial
+}
+
+// NewSearcher returns Searcher, returns error when parameters are invalid.
+func NewSearcher(strategy Strategy, start, max, step int) (*Searcher, error) {
+	if err := ValidateParams(strategy, start, max, step); err != nil {
+		return nil, err
+	}
+	return &Searcher{
+		strategy: strategy,
+		start:    start,
+		max:      max,
+		step:     step,
+	}, nil
+}
+
+// ValidateParams validate capacity search parameters.
+func ValidateParams(strategy Strategy, start, max, step int) error {
+	if strategy != StrategyStep && strategy != StrategyBisect {
+		return fmt.Errorf("strategy must be %v or %v, got %v", StrategyStep, StrategyBisect, strategy)
+	}
+	if start <= 0 {
+		return fmt.Errorf("start load must be more than 0")
+	}
+	if max < start {
+		return fmt.Errorf("max load must be more than or equal to start load")
+	}
+	if step <= 0 {
+		return fmt.Errorf("step must be more than 0")
+	}
+	return nil
+}
+
+/*
+Next returns load of next trial, and returns false when search is finished.
+
+In bisect strategy, step is used as resolution, search finishes when the gap between highest passed load and
+lowest failed load is less than or equal to step.
+*/
+func (s *Searcher) Next() (int, bool) {
+	if len(s.trials) == 0 {
+		return s.start, true
+	}
+	switch s.strategy {
+	case StrategyStep:
+		if s.failed != 0 || s.passed >= s.max {
+			return 0, false
+		}
+		next := s.passed + s.step
+		if next > s.max {
+			next = s.max
+		}
+		return next, true
+	case StrategyBisect:
+		if s.passed == 0 {
+			return 0, false
+		}
+		if s.failed == 0 {
+			if s.passed >= s.max {
+				return 0, false
+			}
+			return s.max, true
+		}
+		if s.failed-s.passed <= s.step {
+			return 0, false
+		}
+		return s.passed + (s.failed-s.passed)/2, true
+	}
+	return 0, false
+}
+
+// Record records result of the trial.
+func (s *Searcher) Record(concurrency int, passed bool, reason string) {
+	s.trials = append(s.trials, Trial{
+		Concurrency: concurrency,
+		Passed:      passed,
+		Reason:      reason,
+	})
+	if passed && concurrency > s.passed {
+		s.passed = concurrency
+	}
+	if !passed && (s.failed == 0 || concurrency < s.failed) {
+		s.failed = concurrency
+	}
+}
+
+// Result returns outcome of the search from recorded trials.
+func (s *Searcher) Result() Result {
+	return Result{
+		Strategy: s.strategy,
+		Found:    s.passed != 0,
+		Capacity: s.passed,
+		Trials:   append([]Trial(nil), s.trials...),
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package capacity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runSearch runs search with trials which pass when load is less than or equal to sustainable.
+func runSearch(t *testing.T, searcher *Searcher, sustainable int) ([]int, Result) {
+	var tried []int
+	for {
+		concurrency, ok := searcher.Next()
+		if !ok {
+			break
+		}
+		if len(tried) > 100 {
+			t.Fatalf("search does not converge, tried %v", tried)
+		}
+		tried = append(tried, concurrency)
+		passed := concurrency <= sustainable
+		searcher.Record(concurrency, passed, fmt.Sprintf("concurrency %v", concurrency))
+	}
+	return tried, searcher.Result()
+}
+
+func TestSearcher(t *testing.T) {
+	tests := []struct {
+		name             string
+		strategy         Strategy
+		start            int
+		max              int
+		step             int
+		sustainable      int
+		expectedTried    []int
+		expectedFound    bool
+		expectedCapacity int
+	}{
+		{
+			name:             "step strategy stops at first failure",
+			strategy:         StrategyStep,
+			start:            10,
+			max:              100,
+			step:             10,
+			sustainable:      35,
+			expectedTried:    []int{10, 20, 30, 40},
+			expectedFound:    true,
+			expectedCapacity: 30,
+		},
+		{
+			name:             "step strategy clamps last trial to max",
+			strategy:         StrategyStep,
+			start:            10,
+			max:              25,
+			step:             10,
+			sustainable:      100,
+			expectedTried:    []int{10, 20, 25},
+			expectedFound:    true,
+			expectedCapacity: 25,
+		},
+		{
+			name:             "step strategy start load failed",
+			strategy:         StrategyStep,
+			start:            10,
+			max:              100,
+			step:             10,
+			sustainable:      5,
+			expectedTried:    []int{10},
+			expectedFound:    false,
+			expectedCapacity: 0,
+		},
+		{
+			name:             "bisect strategy converges within step",
+			strategy:         StrategyBisect,
+			start:            10,
+			max:              100,
+			step:             5,
+			sustainable:      42,
+			expectedTried:    []int{10, 100, 55, 32, 43, 37, 40},
+			expectedFound:    true,
+			expectedCapacity: 40,
+		},
+		{
+			name:             "bisect strategy max load passed",
+			strategy:         StrategyBisect,
+			start:            10,
+			max:              100,
+			step:             5,
+			sustainable:      100,
+			expectedTried:    []int{10, 100},
+			expectedFound:    true,
+			expectedCapacity: 100,
+		},
+		{
+			name:             "bisect strategy start load failed",
+			strategy:         StrategyBisect,
+			start:            10,
+			max:              100,
+			step:             5,
+			sustainable:      0,
+			expectedTried:    []int{10},
+			expectedFound:    false,
+			expectedCapacity: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher, err := NewSearcher(tt.strategy, tt.start, tt.max, tt.step)
+			assert.NoError(t, err)
+			tried, result := runSearch(t, searcher, tt.sustainable)
+			assert.Equal(t, tt.expectedTried, tried)
+			assert.Equal(t, tt.expectedFound, result.Found)
+			assert.Equal(t, tt.expectedCapacity, result.Capacity)
+			assert.Equal(t, len(tt.expectedTried), len(result.Trials))
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		start    int
+		max      int
+		step     int
+		expected error
+	}{
+		{
+			name:     "valid params",
+			strategy: StrategyStep,
+			start:    10,
+			max:      10,
+			step:     1,
+			expected: nil,
+		},
+		{
+			name:     "unknown strategy",
+			strategy: Strategy("random"),
+			start:    10,
+			max:      100,
+			step:     10,
+			expected: fmt.Errorf("strategy must be step or bisect, got random"),
+		},
+		{
+			name:     "start load is 0",
+			strategy: StrategyBisect,
+			start:    0,
+			max:      100,
+			step:     10,
+			expected: fmt.Errorf("start load must be more than 0"),
+		},
+		{
+			name:     "max load is less than start load",
+			strategy: StrategyBisect,
+			start:    10,
+			max:      5,
+			step:     10,
+			expected: fmt.Errorf("max load must be more than or equal to start load"),
+		},
+		{
+			name:     "step is 0",
+			strategy: StrategyStep,
+			start:    10,
+			max:      100,
+			step:     0,
+			expected: fmt.Errorf("step must be more than 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidateParams(tt.strategy, tt.start, tt.max, tt.step))
+		})
+	}
+}
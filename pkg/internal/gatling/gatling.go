@@ -26,6 +26,7 @@ package gatling
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -35,7 +36,9 @@ import (
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
 	"gopkg.in/yaml.v3"
 	kubeapiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+	sigsYaml "sigs.k8s.io/yaml"
 )
 
 /*
@@ -44,7 +47,10 @@ LoadGatlingManifest returns gatling object.
 Load gatling resource manifest file and parse Bytes to Gatling object.
 */
 func LoadGatlingManifest(path string) (*gatlingv1alpha1.Gatling, error) {
-	gatlingYaml, _ := os.ReadFile(path)
+	gatlingYaml, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
 	var (
 		gatling         gatlingv1alpha1.Gatling
@@ -67,71 +73,155 @@ func LoadGatlingManifest(path string) (*gatlingv1alpha1.Gatling, error) {
 /*
 CreateGatling returns error object when success to create gatling object.
 
-If gatling object which has same name already exists in same namespace, delete old one and create new one.
+If gatling object of the same service is still running in same namespace, return error without deleting it
+unless force is true. When force is true, running objects of the same service are deleted before creating new one.
+If gatling object which has same name already exists, it is handled as same as running one.
 */
-func CreateGatling(ctx context.Context, cl ctrlClient.Client, gatling *gatlingv1alpha1.Gatling) error {
-	// check gatling object exists or not
+func CreateGatling(ctx context.Context, cl ctrlClient.Client, gatling *gatlingv1alpha1.Gatling, force bool) error {
+	conflicts, err := findConflictingGatlings(ctx, cl, gatling)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		if !force {
+			return fmt.Errorf(
+				"gatling object %v of the same service is already running in namespace %v, "+
+					"wait for it to finish or specify force flag to delete it",
+				conflict.ObjectMeta.Name,
+				conflict.ObjectMeta.Namespace,
+			)
+		}
+		fmt.Printf("force flag specified, delete running gatling object %v\n", conflict.ObjectMeta.Name)
+		if err := cl.Delete(ctx, &conflict); err != nil && !kubeapiErrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := cl.Create(ctx, gatling); err != nil {
+		return err
+	}
+	return nil
+}
+
+/*
+findConflictingGatlings returns gatling objects which conflict with gatling object to be created.
+
+Conflicting objects are the one which has same name, and running ones which has same service label.
+*/
+func findConflictingGatlings(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	gatling *gatlingv1alpha1.Gatling,
+) ([]gatlingv1alpha1.Gatling, error) {
+	var conflicts []gatlingv1alpha1.Gatling
 	var foundGatling gatlingv1alpha1.Gatling
 	err := cl.Get(ctx, ctrlClient.ObjectKey{
 		Namespace: gatling.ObjectMeta.Namespace,
 		Name:      gatling.ObjectMeta.Name,
 	}, &foundGatling)
 	if err != nil && !kubeapiErrors.IsNotFound(err) {
-		return err
+		return nil, err
 	}
-
-	// skip delete when gatling object is not found
 	if err == nil {
-		if err := cl.Delete(ctx, &foundGatling); err != nil {
-			return err
-		}
+		conflicts = append(conflicts, foundGatling)
 	}
 
-	if err := cl.Create(ctx, gatling); err != nil {
-		return err
+	serviceLabelValue, ok := gatling.ObjectMeta.Labels[LabelService]
+	if !ok {
+		return conflicts, nil
 	}
-	return nil
+	var foundGatlings gatlingv1alpha1.GatlingList
+	if err := cl.List(
+		ctx,
+		&foundGatlings,
+		ctrlClient.InNamespace(gatling.ObjectMeta.Namespace),
+		ctrlClient.MatchingLabels{LabelService: serviceLabelValue},
+	); err != nil {
+		return nil, err
+	}
+	for _, found := range foundGatlings.Items {
+		if found.ObjectMeta.Name == gatling.ObjectMeta.Name || !isGatlingRunning(found) {
+			continue
+		}
+		conflicts = append(conflicts, found)
+	}
+	return conflicts, nil
+}
+
+/*
+isGatlingRunning returns whether gatling object is neither completed nor failed.
+
+Gatling object which does not generate report never completes report, so it is completed when runner completed.
+*/
+func isGatlingRunning(gatling gatlingv1alpha1.Gatling) bool {
+	completed := gatling.Status.RunnerCompleted && (gatling.Status.ReportCompleted || !gatling.Spec.GenerateReport)
+	return !completed && gatling.Status.Error == ""
 }
 
 /*
 WaitGatlingJobStartup wait until gatling job started.
 
-Check every 5 seconds until Status.RunnerStartTime field value is set.
-Except for above case, context Done or over timeout threshold, or something error occured will finish loop.
+Check when Gatling object is changed, which is informed by watcher, and every 5 seconds until
+Status.RunnerStartTime field value is set and runner pods started.
+While waiting, Gatling object, runner Job, runner pods and their events are inspected, and reasons why Gatling Job
+has not started, such as ImagePullBackOff and Unschedulable, are printed once each.
+When the reason never resolves by waiting, such as bad image reference, *StartupFailedError is returned without
+waiting timeout. Error of inspection is only logged not to stop loadtest by inspection failure, and Gatling Job is
+regarded as started once Status.RunnerStartTime is set in that case.
+Except for above case, context Done or over timeout threshold, or something error occured will finish loop, and
+*CancelledError or *TimedOutError is returned for the former two.
 Before finish loop, cleanupGatlingJob is called and delete existing gatling object.
 */
 func WaitGatlingJobStartup(
 	ctx context.Context,
 	cl ctrlClient.Client,
+	watcher *StatusWatcher,
 	gatling *gatlingv1alpha1.Gatling,
 	timeout int32,
 ) error {
+	key := ctrlClient.ObjectKeyFromObject(gatling)
+	changedCh, unsubscribe := watcher.Subscribe(key)
+	defer unsubscribe()
 	var foundGatling gatlingv1alpha1.Gatling
-	startTime := int32(time.Now().Unix())
+	startedAt := time.Now()
+	startTime := int32(startedAt.Unix())
+	printedReasons := map[string]bool{}
 	for {
 		select {
 		case <-ctx.Done():
-			cleanupGatlingJob(cl, &foundGatling)
-			return nil
+			// foundGatling is empty when ctx is done before it is got, so gatling is deleted by its name.
+			cleanupGatlingJob(cl, gatling)
+			return &CancelledError{Cause: ctx.Err()}
 		default:
-			if err := cl.Get(
-				ctx, ctrlClient.ObjectKey{
-					Name:      gatling.ObjectMeta.Name,
-					Namespace: gatling.ObjectMeta.Namespace,
-				}, &foundGatling,
-			); err != nil {
-				return err
+			if err := watcher.Get(ctx, key, gatling.ObjectMeta.UID, &foundGatling); err != nil {
+				return getGatlingFailed(ctx, cl, gatling, err)
+			}
+			state, err := inspectStartup(ctx, cl, foundGatling, startedAt)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to inspect startup of Gatling Job, %v\n", err)
+			}
+			for _, issue := range state.issues {
+				if issue.terminal {
+					fmt.Printf("Gatling Job %v failed to start, %v\n", foundGatling.ObjectMeta.Name, issue.reason)
+					cleanupGatlingJob(cl, &foundGatling)
+					return &StartupFailedError{Reason: issue.reason}
+				}
+				if !printedReasons[issue.reason] {
+					printedReasons[issue.reason] = true
+					fmt.Printf("Gatling Job %v not started yet, %v\n", foundGatling.ObjectMeta.Name, issue.reason)
+				}
 			}
 			duration := int32(time.Now().Unix()) - startTime
 			if err := util.CheckTimeout(timeout, duration); err != nil {
 				cleanupGatlingJob(cl, &foundGatling)
-				return err
+				return &TimedOutError{Reason: err.Error()}
 			}
-			// if RunnerStartTime to be set, finish wait loop.
-			if foundGatling.Status.RunnerStartTime > 0 {
+			// if RunnerStartTime to be set and runner pods started, finish wait loop.
+			if foundGatling.Status.RunnerStartTime > 0 && (state.started || err != nil) {
 				return nil
 			}
-			time.Sleep(5 * time.Second)
+			// runner pods are not watched, so they are checked by interval even if Gatling object is not changed.
+			waitStatusChange(ctx, changedCh, 5*time.Second)
 		}
 	}
 }
@@ -139,51 +229,126 @@ func WaitGatlingJobStartup(
 /*
 WaitGatlingJobRunning wait until gatling Job completed.
 
-Check every 10 seconds until Status.RunnerCompleted and Status.ReportCompleted field value is set.
+Check when Gatling object is changed, which is informed by watcher, and every 10 seconds until outcome of Gatling Job
+is classified by classifyJob from status of Gatling object and conditions of runner Job and reporter Job.
 Check Status.RunnerStartTime field value and if its value not set (0) return error.
-Except for above case, context Done or over timeout threshold, or something error occured will finish loop.
+Failed Gatling Job is returned as *RunnerFailedError or *ReportFailedError without waiting timeout, and context Done
+or over timeout threshold is returned as *CancelledError or *TimedOutError, so callers can match the outcome with
+errors.Is. Failure of getting Gatling object is also classified so when it is caused by context, and is returned
+as it is otherwise.
 Before finish loop except for succeeded case, cleanupGatlingJob is called and delete existing gatling object.
+When abortChecker is not nil, it is called every check and Gatling Job is aborted with *AbortedError
+once it returns abort reason. Error of abortChecker is only logged not to stop loadtest by monitoring failure.
 */
 func WaitGatlingJobRunning(
 	ctx context.Context,
 	cl ctrlClient.Client,
+	watcher *StatusWatcher,
 	gatling *gatlingv1alpha1.Gatling,
 	timeout int32,
 	jobFinishCh chan bool,
+	abortChecker AbortChecker,
 ) error {
 	defer func() { jobFinishCh <- true }()
+	key := ctrlClient.ObjectKeyFromObject(gatling)
+	changedCh, unsubscribe := watcher.Subscribe(key)
+	defer unsubscribe()
 	var foundGatling gatlingv1alpha1.Gatling
+	var printedFailed int32
 	for {
 		select {
 		case <-ctx.Done(): // when interrupt
-			cleanupGatlingJob(cl, &foundGatling)
-			return nil
+			// foundGatling is empty when ctx is done before it is got, so gatling is deleted by its name.
+			cleanupGatlingJob(cl, gatling)
+			return &CancelledError{Cause: ctx.Err()}
 		default:
-			if err := cl.Get(
-				ctx, ctrlClient.ObjectKey{
-					Name:      gatling.ObjectMeta.Name,
-					Namespace: gatling.ObjectMeta.Namespace,
-				}, &foundGatling,
-			); err != nil {
-				return err
+			if err := watcher.Get(ctx, key, gatling.ObjectMeta.UID, &foundGatling); err != nil {
+				return getGatlingFailed(ctx, cl, gatling, err)
 			}
 			if foundGatling.Status.RunnerStartTime == 0 {
 				return fmt.Errorf("waitGatlingJobRunning called, but Gatling Job not started yet")
 			}
+			if foundGatling.Status.Failed > printedFailed {
+				printedFailed = foundGatling.Status.Failed
+				fmt.Printf("Gatling Job %v has %v failed runner pods\n", foundGatling.ObjectMeta.Name, printedFailed)
+			}
+			outcome, reason, err := classifyJob(ctx, cl, foundGatling)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to check jobs of Gatling Job, %v\n", err)
+			}
+			switch outcome {
+			case OutcomeSucceeded:
+				fmt.Printf("Gatling Job %v completed\n", foundGatling.ObjectMeta.Name)
+				if foundGatling.Spec.NotifyReport && !foundGatling.Status.NotificationCompleted {
+					fmt.Printf("Gatling Job %v report notification not completed yet\n", foundGatling.ObjectMeta.Name)
+				}
+				return nil
+			case OutcomeRunnerFailed:
+				fmt.Printf("Gatling Job %v runner failed, %v\n", foundGatling.ObjectMeta.Name, reason)
+				cleanupGatlingJob(cl, &foundGatling)
+				return &RunnerFailedError{Reason: reason}
+			case OutcomeReportFailed:
+				fmt.Printf("Gatling Job %v report failed, %v\n", foundGatling.ObjectMeta.Name, reason)
+				cleanupGatlingJob(cl, &foundGatling)
+				return &ReportFailedError{Reason: reason}
+			case OutcomeTimedOut:
+				fmt.Printf("Gatling Job %v timed out, %v\n", foundGatling.ObjectMeta.Name, reason)
+				cleanupGatlingJob(cl, &foundGatling)
+				return &TimedOutError{Reason: reason}
+			}
 			duration := int32(time.Now().Unix()) - foundGatling.Status.RunnerStartTime
 			if err := util.CheckTimeout(timeout, duration); err != nil {
 				cleanupGatlingJob(cl, &foundGatling)
-				return err
+				return &TimedOutError{Reason: err.Error()}
 			}
-			if foundGatling.Status.RunnerCompleted && foundGatling.Status.ReportCompleted {
-				fmt.Printf("Gatling Job %v completed\n", foundGatling.ObjectMeta.Name)
-				return nil
+			if abortChecker != nil && !foundGatling.Status.RunnerCompleted {
+				reason, err := abortChecker.CheckAbort(ctx)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to check abort rules of Gatling Job, %v\n", err)
+				}
+				if reason != "" {
+					fmt.Printf("Gatling Job %v aborted, %v\n", foundGatling.ObjectMeta.Name, reason)
+					cleanupGatlingJob(cl, &foundGatling)
+					return &AbortedError{Reason: reason}
+				}
 			}
-			time.Sleep(10 * time.Second)
+			// timeout and abort rules are checked by interval even if Gatling object is not changed.
+			waitStatusChange(ctx, changedCh, 10*time.Second)
 		}
 	}
 }
 
+// waitStatusChange blocks until Gatling object is changed, interval passes or ctx is done.
+func waitStatusChange(ctx context.Context, changedCh <-chan struct{}, interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-changedCh:
+	case <-timer.C:
+	}
+}
+
+/*
+getGatlingFailed cleans up Gatling Job which can not be got while waiting it, and returns error of its outcome.
+
+Failure by interruption or deadline of ctx is returned as *CancelledError or *TimedOutError, so that it is classified
+as same as the one found by ctx.Done.
+*/
+func getGatlingFailed(ctx context.Context, cl ctrlClient.Client, gatling *gatlingv1alpha1.Gatling, err error) error {
+	cleanupGatlingJob(cl, gatling)
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &CancelledError{Cause: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &TimedOutError{Reason: err.Error()}
+	}
+	return fmt.Errorf("failed to get gatling object %v, %w", gatling.ObjectMeta.Name, err)
+}
+
 /*
 cleanupGatlingJob Delete specified gatling object.
 
@@ -191,7 +356,76 @@ Called for cleanup existing gatling object when gatling loadtest interrupted.
 */
 func cleanupGatlingJob(cl ctrlClient.Client, foundGatling *gatlingv1alpha1.Gatling) {
 	cleanupCtx := context.Background()
-	if err := cl.Delete(cleanupCtx, foundGatling); err != nil {
+	if err := cl.Delete(cleanupCtx, foundGatling); err != nil && !kubeapiErrors.IsNotFound(err) {
 		fmt.Fprintf(os.Stderr, "failed to delete found Gatling Job for cleanup, %v\n", err)
 	}
 }
+
+/*
+CheckGatlingCRDInstalled returns error when Gatling custom resource is not served in the cluster.
+
+List Gatling objects in namespace with limit 1, so it is only used for checking the cluster.
+*/
+func CheckGatlingCRDInstalled(ctx context.Context, cl ctrlClient.Client, namespace string) error {
+	var gatlingList gatlingv1alpha1.GatlingList
+	err := cl.List(ctx, &gatlingList, ctrlClient.InNamespace(namespace), ctrlClient.Limit(1))
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("Gatling CRD is not installed in the cluster, %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list Gatling objects in namespace %v, %w", namespace, err)
+	}
+	return nil
+}
+
+/*
+MarshalGatlingManifest returns YAML manifest bytes of gatling object.
+
+Fields which are set by cluster (status and metadata.creationTimestamp) are removed,
+so the result can be applied or compared as it is.
+*/
+func MarshalGatlingManifest(gatling *gatlingv1alpha1.Gatling) ([]byte, error) {
+	jsonBytes, err := json.Marshal(gatling)
+	if err != nil {
+		return nil, err
+	}
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &manifest); err != nil {
+		return nil, err
+	}
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	return sigsYaml.Marshal(manifest)
+}
+
+/*
+FindGatling returns gatling object which has specified name in namespace.
+
+If gatling object is not found, returns nil without error.
+*/
+func FindGatling(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	name string,
+	namespace string,
+) (*gatlingv1alpha1.Gatling, error) {
+	var foundGatling gatlingv1alpha1.Gatling
+	err := cl.Get(ctx, ctrlClient.ObjectKey{Name: name, Namespace: namespace}, &foundGatling)
+	if kubeapiErrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &foundGatling, nil
+}
+
+// DeleteGatling delete gatling object, and ignore the error when it is already deleted.
+func DeleteGatling(ctx context.Context, cl ctrlClient.Client, gatling *gatlingv1alpha1.Gatling) error {
+	if err := cl.Delete(ctx, gatling); err != nil && !kubeapiErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -31,6 +31,10 @@ import (
 	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
 
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeapiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -55,30 +59,184 @@ func TestCreateGatling(t *testing.T) {
 	err = cl.Create(context.TODO(), sampleGatling)
 	assert.NoError(t, err)
 
+	// create completed gatling object of the same service, it does not conflict with new one.
+	completedGatling := *sampleGatling
+	completedGatling.ObjectMeta.Name = "completed-gatling"
+	completedGatling.ObjectMeta.ResourceVersion = ""
+	completedGatling.ObjectMeta.Labels = map[string]string{LabelService: "sample-service"}
+	completedGatling.Status = gatlingv1alpha1.GatlingStatus{
+		RunnerCompleted: true,
+		ReportCompleted: true,
+	}
+	err = cl.Create(context.TODO(), &completedGatling)
+	assert.NoError(t, err)
+	sameServiceGatling := *sampleGatling
+	sameServiceGatling.ObjectMeta.Name = "same-service-gatling"
+	sameServiceGatling.ObjectMeta.ResourceVersion = ""
+	sameServiceGatling.ObjectMeta.Labels = map[string]string{LabelService: "sample-service"}
+
 	tests := []struct {
 		name    string
 		gatling *gatlingv1alpha1.Gatling
+		force   bool
 	}{
 		{
 			name:    "create new gatling object",
 			gatling: &notExistsGatling,
+			force:   false,
 		},
 		{
-			name:    "delete existing and create new gatling object",
+			name:    "delete existing and create new gatling object with force",
 			gatling: &existsGatling,
+			force:   true,
+		},
+		{
+			name:    "create new gatling object when completed one of the same service exists",
+			gatling: &sameServiceGatling,
+			force:   false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := testCreateGatling(cl, tt.gatling)
+			err := testCreateGatling(cl, tt.gatling, tt.force)
 			assert.NoError(t, err)
 		})
 	}
 }
 
+func TestCreateGatling_Conflict(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+
+	sampleGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	sampleGatling.ObjectMeta.Labels = map[string]string{LabelService: "sample-service"}
+	runningGatling := *sampleGatling
+	runningGatling.ObjectMeta.Name = "running-gatling"
+	err = cl.Create(context.TODO(), sampleGatling)
+	assert.NoError(t, err)
+	err = cl.Create(context.TODO(), &runningGatling)
+	assert.NoError(t, err)
+
+	sameNameGatling := *sampleGatling
+	sameNameGatling.ObjectMeta.ResourceVersion = ""
+	sameServiceGatling := *sampleGatling
+	sameServiceGatling.ObjectMeta.Name = "new-gatling"
+	sameServiceGatling.ObjectMeta.ResourceVersion = ""
+
+	tests := []struct {
+		name    string
+		gatling *gatlingv1alpha1.Gatling
+	}{
+		{
+			name:    "gatling object which has same name exists",
+			gatling: &sameNameGatling,
+		},
+		{
+			name:    "running gatling object of the same service exists",
+			gatling: &sameServiceGatling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CreateGatling(context.TODO(), cl, tt.gatling, false)
+			assert.Error(t, err)
+		})
+	}
+
+	// running one is deleted when force is true.
+	err = CreateGatling(context.TODO(), cl, &sameServiceGatling, true)
+	assert.NoError(t, err)
+	var foundGatling gatlingv1alpha1.Gatling
+	err = cl.Get(context.TODO(), ctrlClient.ObjectKeyFromObject(&runningGatling), &foundGatling)
+	assert.True(t, kubeapiErrors.IsNotFound(err))
+}
+
+func TestIsGatlingRunning(t *testing.T) {
+	tests := []struct {
+		name           string
+		generateReport bool
+		status         gatlingv1alpha1.GatlingStatus
+		expected       bool
+	}{
+		{
+			name:           "runner is running",
+			generateReport: true,
+			status:         gatlingv1alpha1.GatlingStatus{},
+			expected:       true,
+		},
+		{
+			name:           "report is being generated",
+			generateReport: true,
+			status:         gatlingv1alpha1.GatlingStatus{RunnerCompleted: true},
+			expected:       true,
+		},
+		{
+			name:           "runner and report completed",
+			generateReport: true,
+			status:         gatlingv1alpha1.GatlingStatus{RunnerCompleted: true, ReportCompleted: true},
+			expected:       false,
+		},
+		{
+			name:           "runner completed without generating report",
+			generateReport: false,
+			status:         gatlingv1alpha1.GatlingStatus{RunnerCompleted: true},
+			expected:       false,
+		},
+		{
+			name:           "runner is running without generating report",
+			generateReport: false,
+			status:         gatlingv1alpha1.GatlingStatus{},
+			expected:       true,
+		},
+		{
+			name:           "failed",
+			generateReport: true,
+			status:         gatlingv1alpha1.GatlingStatus{Error: "runner failed"},
+			expected:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gatling := gatlingv1alpha1.Gatling{
+				Spec:   gatlingv1alpha1.GatlingSpec{GenerateReport: tt.generateReport},
+				Status: tt.status,
+			}
+			assert.Equal(t, tt.expected, isGatlingRunning(gatling))
+		})
+	}
+}
+
+func TestFindGatling(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+
+	sampleGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	err = cl.Create(context.TODO(), sampleGatling)
+	assert.NoError(t, err)
+
+	foundGatling, err := FindGatling(
+		context.TODO(), cl, sampleGatling.ObjectMeta.Name, sampleGatling.ObjectMeta.Namespace,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleGatling.ObjectMeta.Name, foundGatling.ObjectMeta.Name)
+
+	err = DeleteGatling(context.TODO(), cl, foundGatling)
+	assert.NoError(t, err)
+	// deleting already deleted object is not error.
+	err = DeleteGatling(context.TODO(), cl, foundGatling)
+	assert.NoError(t, err)
+
+	foundGatling, err = FindGatling(
+		context.TODO(), cl, sampleGatling.ObjectMeta.Name, sampleGatling.ObjectMeta.Namespace,
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, foundGatling)
+}
+
 func TestWaitGatlingJobStartup(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
 	startedGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
 
@@ -87,6 +245,23 @@ func TestWaitGatlingJobStartup(t *testing.T) {
 	}
 	err = cl.Create(context.TODO(), startedGatling)
 	assert.NoError(t, err)
+	// runner pod of which runner container started
+	err = cl.Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RunnerJobName(startedGatling) + "-a",
+			Namespace: startedGatling.ObjectMeta.Namespace,
+			Labels:    map[string]string{RunnerJobNameLabelKey: RunnerJobName(startedGatling)},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  RunnerContainerName,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
 
 	tests := []struct {
 		name    string
@@ -99,7 +274,7 @@ func TestWaitGatlingJobStartup(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := WaitGatlingJobStartup(context.TODO(), cl, tt.gatling, 2)
+			err := WaitGatlingJobStartup(context.TODO(), cl, watcher, tt.gatling, 2)
 			assert.NoError(t, err)
 		})
 	}
@@ -107,6 +282,7 @@ func TestWaitGatlingJobStartup(t *testing.T) {
 
 func TestWaitGatlingJobStartup_ExpectedFail(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
 
 	noRunnerStartTimeGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
@@ -117,6 +293,13 @@ func TestWaitGatlingJobStartup_ExpectedFail(t *testing.T) {
 	err = cl.Create(context.TODO(), noRunnerStartTimeGatling)
 	assert.NoError(t, err)
 
+	// runner job of this gatling was not created because cloud storage bucket was not found
+	failedGatling := noRunnerStartTimeGatling.DeepCopy()
+	failedGatling.ObjectMeta = metav1.ObjectMeta{Name: "failed-gatling", Namespace: "gatling-system"}
+	failedGatling.Status.Error = "Failed to get cloud storage info"
+	err = cl.Create(context.TODO(), failedGatling)
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name     string
 		gatling  *gatlingv1alpha1.Gatling
@@ -127,12 +310,20 @@ func TestWaitGatlingJobStartup_ExpectedFail(t *testing.T) {
 			name:     "wait startup failed with timeout",
 			gatling:  noRunnerStartTimeGatling,
 			timeout:  1,
-			expected: fmt.Errorf("timeout %v execeeded", 1),
+			expected: &TimedOutError{Reason: "timeout 1 execeeded"},
+		},
+		{
+			name:    "wait startup failed without waiting timeout",
+			gatling: failedGatling,
+			timeout: 60,
+			expected: &StartupFailedError{
+				Reason: "gatling failed-gatling error, Failed to get cloud storage info",
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := WaitGatlingJobStartup(context.TODO(), cl, tt.gatling, tt.timeout)
+			err := WaitGatlingJobStartup(context.TODO(), cl, watcher, tt.gatling, tt.timeout)
 			assert.Equal(t, tt.expected, err)
 		})
 	}
@@ -140,6 +331,7 @@ func TestWaitGatlingJobStartup_ExpectedFail(t *testing.T) {
 
 func TestWaitGatlingJobRunning(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
 
 	completedGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
@@ -165,7 +357,7 @@ func TestWaitGatlingJobRunning(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := WaitGatlingJobRunning(context.TODO(), cl, tt.gatling, 2, informJobFinishCh)
+			err := WaitGatlingJobRunning(context.TODO(), cl, watcher, tt.gatling, 2, informJobFinishCh, nil)
 			assert.NoError(t, err)
 		})
 	}
@@ -173,6 +365,7 @@ func TestWaitGatlingJobRunning(t *testing.T) {
 
 func TestWaitGatlingJobRunning_ExpectedFail(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
 
 	sampleGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
@@ -214,7 +407,7 @@ func TestWaitGatlingJobRunning_ExpectedFail(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := WaitGatlingJobRunning(context.TODO(), cl, tt.gatling, tt.timeout, tt.informerCh)
+			err := WaitGatlingJobRunning(context.TODO(), cl, watcher, tt.gatling, tt.timeout, tt.informerCh, nil)
 			assert.Error(t, err)
 		})
 	}
@@ -222,6 +415,7 @@ func TestWaitGatlingJobRunning_ExpectedFail(t *testing.T) {
 
 func TestWaitGatlingJobRunning_CleanupGatlingJobWhenInterrupted(t *testing.T) {
 	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
 
 	notCompletedGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
 	assert.NoError(t, err)
@@ -252,8 +446,9 @@ func TestWaitGatlingJobRunning_CleanupGatlingJobWhenInterrupted(t *testing.T) {
 				time.Sleep(1 * time.Second)
 				cancel()
 			}()
-			err := WaitGatlingJobRunning(ctx, cl, tt.gatling, 2, informJobFinishCh)
-			assert.NoError(t, err)
+			err := WaitGatlingJobRunning(ctx, cl, watcher, tt.gatling, 2, informJobFinishCh, nil)
+			assert.ErrorIs(t, err, ErrCancelled)
+			assert.ErrorIs(t, err, context.Canceled)
 			var foundGatling gatlingv1alpha1.Gatling
 			err = cl.Get(
 				context.TODO(),
@@ -268,8 +463,182 @@ func TestWaitGatlingJobRunning_CleanupGatlingJobWhenInterrupted(t *testing.T) {
 	}
 }
 
-func testCreateGatling(cl client.Client, newGatling *gatlingv1alpha1.Gatling) error {
-	err := CreateGatling(context.TODO(), cl, newGatling)
+// failingGetClient is client which fails to get any object with err.
+type failingGetClient struct {
+	ctrlClient.WithWatch
+	err error
+}
+
+func (c failingGetClient) Get(context.Context, ctrlClient.ObjectKey, ctrlClient.Object, ...ctrlClient.GetOption) error {
+	return c.err
+}
+
+func TestWaitGatlingJob_CleanupGatlingJobWhenGetFailed(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{
+			name:        "interrupted while getting gatling object",
+			err:         fmt.Errorf("Get gatling: %w", context.Canceled),
+			expectedErr: ErrCancelled,
+		},
+		{
+			name:        "deadline exceeded while getting gatling object",
+			err:         fmt.Errorf("Get gatling: %w", context.DeadlineExceeded),
+			expectedErr: ErrTimedOut,
+		},
+		{
+			name:        "failed to get gatling object",
+			err:         fmt.Errorf("connection refused"),
+			expectedErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		for _, wait := range []string{"startup", "running"} {
+			t.Run(tt.name+" "+wait, func(t *testing.T) {
+				cl := kubeutil.InitFakeClient()
+				gatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+				assert.NoError(t, err)
+				gatling.Status = gatlingv1alpha1.GatlingStatus{RunnerStartTime: int32(time.Now().Unix())}
+				assert.NoError(t, cl.Create(context.TODO(), gatling))
+				failingCl := failingGetClient{WithWatch: cl, err: tt.err}
+				// watcher is not started, so gatling object is got through the client.
+				watcher := NewStatusWatcher(failingCl, "", nil)
+
+				if wait == "startup" {
+					err = WaitGatlingJobStartup(context.TODO(), failingCl, watcher, gatling, 60)
+				} else {
+					err = WaitGatlingJobRunning(context.TODO(), failingCl, watcher, gatling, 60, make(chan bool, 1), nil)
+				}
+				assert.Error(t, err)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.Equal(t, Outcome(""), OutcomeOf(err))
+				}
+				found, err := FindGatling(context.TODO(), cl, gatling.ObjectMeta.Name, gatling.ObjectMeta.Namespace)
+				assert.NoError(t, err)
+				assert.Nil(t, found)
+			})
+		}
+	}
+}
+
+func TestWaitGatlingJobRunning_Failed(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	watcher := NewStatusWatcher(cl, "", nil)
+
+	sampleGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	runnerFailedGatling := sampleGatling.DeepCopy()
+	runnerFailedGatling.ObjectMeta.Name = "runner-failed"
+	runnerFailedGatling.Status = gatlingv1alpha1.GatlingStatus{
+		RunnerStartTime: int32(time.Now().Unix()),
+		Failed:          1,
+		Error:           "Failed to complete runner job ( failed 1 / backofflimit 0 ). Please review logs",
+	}
+	reportFailedGatling := sampleGatling.DeepCopy()
+	reportFailedGatling.ObjectMeta.Name = "report-failed"
+	reportFailedGatling.Status = gatlingv1alpha1.GatlingStatus{
+		RunnerStartTime: int32(time.Now().Unix()),
+		RunnerCompleted: true,
+		ReporterJobName: "report-failed-reporter",
+	}
+	for _, gatling := range []*gatlingv1alpha1.Gatling{runnerFailedGatling, reportFailedGatling} {
+		assert.NoError(t, cl.Create(context.TODO(), gatling))
+	}
+	// reporter job failed before gatling-operator updates Gatling status
+	err = cl.Create(context.TODO(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportFailedGatling.Status.ReporterJobName,
+			Namespace: reportFailedGatling.ObjectMeta.Namespace,
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		gatling  *gatlingv1alpha1.Gatling
+		expected error
+	}{
+		{
+			name:     "runner failed",
+			gatling:  runnerFailedGatling,
+			expected: ErrRunnerFailed,
+		},
+		{
+			name:     "report failed",
+			gatling:  reportFailedGatling,
+			expected: ErrReportFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			informJobFinishCh := make(chan bool, 1)
+			// failed Gatling Job is returned without waiting timeout
+			err := WaitGatlingJobRunning(context.TODO(), cl, watcher, tt.gatling, 3600, informJobFinishCh, nil)
+			assert.ErrorIs(t, err, tt.expected)
+
+			// failed gatling object is deleted
+			foundGatling, err := FindGatling(
+				context.TODO(), cl, tt.gatling.ObjectMeta.Name, tt.gatling.ObjectMeta.Namespace,
+			)
+			assert.NoError(t, err)
+			assert.Nil(t, foundGatling)
+		})
+	}
+}
+
+type mockAbortChecker struct {
+	reason string
+}
+
+func (m *mockAbortChecker) CheckAbort(ctx context.Context) (string, error) {
+	return m.reason, nil
+}
+
+func TestWaitGatlingJobRunning_Aborted(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+
+	runningGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	runningGatling.Status = gatlingv1alpha1.GatlingStatus{
+		RunnerStartTime: int32(time.Now().Unix()),
+	}
+	err = cl.Create(context.TODO(), runningGatling)
+	assert.NoError(t, err)
+
+	informJobFinishCh := make(chan bool, 1)
+	err = WaitGatlingJobRunning(
+		context.TODO(),
+		cl,
+		NewStatusWatcher(cl, "", nil),
+		runningGatling,
+		60,
+		informJobFinishCh,
+		&mockAbortChecker{reason: "cpu usage exceeded"},
+	)
+	var abortedErr *AbortedError
+	assert.ErrorAs(t, err, &abortedErr)
+	assert.Equal(t, "cpu usage exceeded", abortedErr.Reason)
+
+	// aborted gatling object is deleted
+	foundGatling, err := FindGatling(
+		context.TODO(), cl, runningGatling.ObjectMeta.Name, runningGatling.ObjectMeta.Namespace,
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, foundGatling)
+}
+
+func testCreateGatling(cl client.Client, newGatling *gatlingv1alpha1.Gatling, force bool) error {
+	err := CreateGatling(context.TODO(), cl, newGatling, force)
 	if err != nil {
 		return err
 	}
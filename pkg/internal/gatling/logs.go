@@ -0,0 +1,224 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// reportGeneratorContainerName is the name of init container which generates Gatling Report in reporter pod.
+	reportGeneratorContainerName = "gatling-report-generator"
+	// logsPollInterval is interval of finding runner and reporter pods of which logs are not followed yet.
+	logsPollInterval = 5 * time.Second
+	// logsStopTimeout is timeout of waiting log streams end after the Gatling Job finished.
+	logsStopTimeout = 30 * time.Second
+)
+
+// ReporterJobName returns the name of reporter Job which gatling-operator creates for gatling.
+func ReporterJobName(gatling *gatlingv1alpha1.Gatling) string {
+	return gatling.ObjectMeta.Name + "-reporter"
+}
+
+// followedContainer is container of pods of Job of which logs are followed.
+type followedContainer struct {
+	jobName       string
+	containerName string
+}
+
+/*
+LogFollower follows logs of runner and reporter pods of a Gatling object.
+
+Each log line is written to terminal with prefix and pod name, and written to file with pod name.
+Pods are found every 5 seconds, because runner pods and reporter pod are created as the Gatling Job proceeds.
+*/
+type LogFollower struct {
+	cs         kubernetes.Interface
+	gatling    *gatlingv1alpha1.Gatling
+	prefix     string
+	terminal   io.Writer
+	file       io.Writer
+	containers []followedContainer
+
+	mu       sync.Mutex      // guards writers and followed
+	followed map[string]bool // key is pod name and container name
+	cancel   context.CancelFunc
+	stopCh   chan struct{}
+	pollWg   sync.WaitGroup
+	streamWg sync.WaitGroup
+}
+
+// NewLogFollower creates LogFollower which writes logs of gatling to terminal and file.
+func NewLogFollower(
+	cs kubernetes.Interface,
+	gatling *gatlingv1alpha1.Gatling,
+	prefix string,
+	terminal, file io.Writer,
+) *LogFollower {
+	return &LogFollower{
+		cs:       cs,
+		gatling:  gatling,
+		prefix:   prefix,
+		terminal: terminal,
+		file:     file,
+		containers: []followedContainer{
+			{jobName: RunnerJobName(gatling), containerName: RunnerContainerName},
+			{jobName: ReporterJobName(gatling), containerName: reportGeneratorContainerName},
+		},
+		followed: map[string]bool{},
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start starts to find runner and reporter pods and follow their logs in background.
+func (f *LogFollower) Start(ctx context.Context) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	f.cancel = cancel
+	f.pollWg.Add(1)
+	go func() {
+		defer f.pollWg.Done()
+		for {
+			f.poll(streamCtx)
+			select {
+			case <-streamCtx.Done():
+				return
+			case <-f.stopCh:
+				return
+			case <-time.After(logsPollInterval):
+			}
+		}
+	}()
+}
+
+/*
+Stop stops finding pods and waits log streams end.
+
+Pods are found once more not to miss logs of containers which finished after the last poll.
+Log streams which don't end within 30 seconds are canceled.
+*/
+func (f *LogFollower) Stop() {
+	close(f.stopCh)
+	f.pollWg.Wait()
+	streamCtx, cancel := context.WithTimeout(context.Background(), logsStopTimeout)
+	defer cancel()
+	f.poll(streamCtx)
+
+	streamsDone := make(chan struct{})
+	go func() {
+		f.streamWg.Wait()
+		close(streamsDone)
+	}()
+	select {
+	case <-streamsDone:
+	case <-streamCtx.Done():
+		fmt.Fprintf(os.Stderr, "%v, log streams did not end, canceled\n", f.prefix)
+	}
+	f.cancel()
+	f.streamWg.Wait()
+}
+
+// poll finds started containers of runner and reporter pods, and starts to follow logs of them.
+func (f *LogFollower) poll(ctx context.Context) {
+	namespace := f.gatling.ObjectMeta.Namespace
+	for _, container := range f.containers {
+		pods, err := f.cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%v=%v", RunnerJobNameLabelKey, container.jobName),
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "%v, failed to list pods of %v, %v\n", f.prefix, container.jobName, err)
+			}
+			continue
+		}
+		for _, pod := range pods.Items {
+			if !containerStarted(pod, container.containerName) {
+				continue
+			}
+			key := pod.ObjectMeta.Name + "/" + container.containerName
+			f.mu.Lock()
+			followed := f.followed[key]
+			f.followed[key] = true
+			f.mu.Unlock()
+			if followed {
+				continue
+			}
+			f.streamWg.Add(1)
+			go f.stream(ctx, pod.ObjectMeta.Name, container.containerName)
+		}
+	}
+}
+
+// stream follows logs of container of pod until the container terminated or context done.
+func (f *LogFollower) stream(ctx context.Context, podName, containerName string) {
+	defer f.streamWg.Done()
+	logs, err := f.cs.CoreV1().Pods(f.gatling.ObjectMeta.Namespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: containerName,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v, failed to follow logs of pod %v, %v\n", f.prefix, podName, err)
+		return
+	}
+	defer logs.Close()
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // stack trace of simulation crash can be long line.
+	for scanner.Scan() {
+		f.writeLine(podName, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "%v, failed to read logs of pod %v, %v\n", f.prefix, podName, err)
+	}
+}
+
+// writeLine writes a log line of pod to terminal and file.
+func (f *LogFollower) writeLine(podName, line string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fmt.Fprintf(f.terminal, "[%v] %v %v\n", f.prefix, podName, line)
+	fmt.Fprintf(f.file, "%v %v\n", podName, line)
+}
+
+// containerStarted returns true when the container or init container of pod is running or terminated.
+func containerStarted(pod corev1.Pod, containerName string) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if status.Name == containerName && (status.State.Running != nil || status.State.Terminated != nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogFollower(t *testing.T) {
+	gatling := &gatlingv1alpha1.Gatling{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-gatling", Namespace: "sample-namespace"},
+	}
+	newPod := func(name, jobName string, status corev1.PodStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "sample-namespace",
+				Labels:    map[string]string{RunnerJobNameLabelKey: jobName},
+			},
+			Status: status,
+		}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	cs := fake.NewSimpleClientset(
+		newPod("sample-gatling-runner-a", "sample-gatling-runner", corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: RunnerContainerName, State: running}},
+		}),
+		// runner pod of which container is waiting to start
+		newPod("sample-gatling-runner-b", "sample-gatling-runner", corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: RunnerContainerName}},
+		}),
+		newPod("sample-gatling-reporter-a", "sample-gatling-reporter", corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  reportGeneratorContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+				},
+			},
+		}),
+		// pod of other Gatling object
+		newPod("other-gatling-runner-a", "other-gatling-runner", corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: RunnerContainerName, State: running}},
+		}),
+	)
+
+	var terminal, file bytes.Buffer
+	follower := NewLogFollower(cs, gatling, "sample-service sample-scenario", &terminal, &file)
+	follower.Start(context.TODO())
+	follower.Stop()
+
+	// fake clientset returns "fake logs" as logs of every pod.
+	assert.ElementsMatch(t, []string{
+		"[sample-service sample-scenario] sample-gatling-runner-a fake logs",
+		"[sample-service sample-scenario] sample-gatling-reporter-a fake logs",
+	}, splitLines(terminal.String()))
+	assert.ElementsMatch(t, []string{
+		"sample-gatling-runner-a fake logs",
+		"sample-gatling-reporter-a fake logs",
+	}, splitLines(file.String()))
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
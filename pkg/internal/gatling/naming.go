@@ -0,0 +1,130 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Labels set to Gatling object created by gatling-commander.
+const (
+	LabelRunID    = "gatling-commander/run-id"
+	LabelService  = "gatling-commander/service"
+	LabelScenario = "gatling-commander/scenario"
+)
+
+/*
+maxGatlingNameLength is max length of Gatling object name.
+
+Gatling Operator creates Jobs named "<name>-runner" and "<name>-reporter", and Job name is used as label value
+(max 63 characters), so leave enough length for these suffixes.
+*/
+const maxGatlingNameLength = 52
+
+// maxLabelValueLength is max length of Kubernetes label value.
+const maxLabelValueLength = 63
+
+var invalidNameCharPattern = regexp.MustCompile(`[^a-z0-9-]+`)
+var continuousHyphenPattern = regexp.MustCompile(`-{2,}`)
+
+/*
+NewRunID returns id which identifies each exec command run.
+
+Format is YYYYMMDDhhmm-xxxx, xxxx is random hex, so runs started in the same minute don't collide.
+*/
+func NewRunID(t time.Time) string {
+	randomBytes := make([]byte, 2)
+	if _, err := rand.Read(randomBytes); err != nil {
+		// crypto/rand never fails on supported platforms, use nanoseconds just in case.
+		return fmt.Sprintf("%s-%04x", t.Format("200601021504"), t.Nanosecond()&0xffff)
+	}
+	return fmt.Sprintf("%s-%s", t.Format("200601021504"), hex.EncodeToString(randomBytes))
+}
+
+/*
+GenerateGatlingName returns Gatling object name unique per service, scenario and run.
+
+Name format is <service>-<scenario>-<subName>-<runID> sanitized to DNS-1123 label.
+If the name is too long, the service and scenario part is truncated and its hash is appended to keep it unique.
+*/
+func GenerateGatlingName(serviceName, scenarioName, scenarioSubName, runID string) string {
+	suffix := "-" + sanitizeName(runID)
+	prefix := sanitizeName(serviceName + "-" + SanitizeScenarioName(scenarioName, scenarioSubName))
+	if len(prefix)+len(suffix) <= maxGatlingNameLength {
+		return prefix + suffix
+	}
+	hash := shortHash(prefix)
+	truncated := strings.TrimRight(prefix[:maxGatlingNameLength-len(suffix)-len(hash)-1], "-")
+	return fmt.Sprintf("%s-%s%s", truncated, hash, suffix)
+}
+
+// GenerateGatlingLabels returns labels which identify run, service and scenario of Gatling object.
+func GenerateGatlingLabels(serviceName, scenarioName, scenarioSubName, runID string) map[string]string {
+	return map[string]string{
+		LabelRunID:    SanitizeLabelValue(runID),
+		LabelService:  SanitizeLabelValue(serviceName),
+		LabelScenario: SanitizeLabelValue(SanitizeScenarioName(scenarioName, scenarioSubName)),
+	}
+}
+
+/*
+SanitizeScenarioName returns scenario name and subName joined with hyphen and sanitized as Gatling object name.
+
+Scenarios whose sanitized names are the same share Gatling object name and labels, so they must be unique in service.
+*/
+func SanitizeScenarioName(scenarioName, scenarioSubName string) string {
+	return sanitizeName(scenarioName + "-" + scenarioSubName)
+}
+
+/*
+SanitizeLabelValue returns value which is valid as Kubernetes label value.
+
+Value is sanitized same as Gatling object name, and truncated with hash when it is too long.
+*/
+func SanitizeLabelValue(value string) string {
+	sanitized := sanitizeName(value)
+	if len(sanitized) <= maxLabelValueLength {
+		return sanitized
+	}
+	hash := shortHash(sanitized)
+	return fmt.Sprintf("%s-%s", strings.TrimRight(sanitized[:maxLabelValueLength-len(hash)-1], "-"), hash)
+}
+
+// sanitizeName lowers value and replaces characters which are not allowed in DNS-1123 label with hyphen.
+func sanitizeName(value string) string {
+	sanitized := invalidNameCharPattern.ReplaceAllString(strings.ToLower(value), "-")
+	sanitized = continuousHyphenPattern.ReplaceAllString(sanitized, "-")
+	return strings.Trim(sanitized, "-")
+}
+
+// shortHash returns first 6 characters of sha256 hex of value.
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:6]
+}
@@ -0,0 +1,136 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestNewRunID(t *testing.T) {
+	execTime := time.Date(2023, 8, 2, 18, 50, 0, 0, time.UTC)
+	runID := NewRunID(execTime)
+	assert.Regexp(t, `^202308021850-[0-9a-f]{4}$`, runID)
+	assert.Empty(t, validation.IsValidLabelValue(runID))
+}
+
+func TestGenerateGatlingName(t *testing.T) {
+	const runID = "202308021850-abcd"
+	longServiceName := strings.Repeat("long-service-name-", 5)
+	tests := []struct {
+		name            string
+		serviceName     string
+		scenarioName    string
+		scenarioSubName string
+		expected        string
+	}{
+		{
+			name:            "service, scenario and run id joined",
+			serviceName:     "sample-service",
+			scenarioName:    "case-1",
+			scenarioSubName: "10rps",
+			expected:        "sample-service-case-1-10rps-202308021850-abcd",
+		},
+		{
+			name:            "invalid characters replaced",
+			serviceName:     "Sample_Service",
+			scenarioName:    "case 1",
+			scenarioSubName: "-5rps",
+			expected:        "sample-service-case-1-5rps-202308021850-abcd",
+		},
+		{
+			name:            "long name truncated with hash",
+			serviceName:     longServiceName,
+			scenarioName:    "case-1",
+			scenarioSubName: "10rps",
+			expected: "long-service-name-long-serv-" +
+				shortHash(sanitizeName(longServiceName+"-case-1-10rps")) + "-202308021850-abcd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := GenerateGatlingName(tt.serviceName, tt.scenarioName, tt.scenarioSubName, runID)
+			assert.Equal(t, tt.expected, name)
+			assert.LessOrEqual(t, len(name), maxGatlingNameLength)
+			assert.Empty(t, validation.IsDNS1123Label(name))
+		})
+	}
+}
+
+func TestGenerateGatlingName_Unique(t *testing.T) {
+	const runID = "202308021850-abcd"
+	longServiceName := strings.Repeat("long-service-name-", 5)
+	assert.NotEqual(
+		t,
+		GenerateGatlingName(longServiceName, "case-1", "10rps", runID),
+		GenerateGatlingName(longServiceName, "case-1", "20rps", runID),
+	)
+	assert.NotEqual(
+		t,
+		GenerateGatlingName("sample-service", "case-1", "10rps", runID),
+		GenerateGatlingName("sample-service", "case-1", "10rps", "202308021850-ef01"),
+	)
+	assert.NotEqual(
+		t,
+		GenerateGatlingName("sample-service", "case-1", "10rps", runID),
+		GenerateGatlingName("sample-service", "case-11", "0rps", runID),
+	)
+}
+
+func TestSanitizeScenarioName(t *testing.T) {
+	tests := []struct {
+		name            string
+		scenarioName    string
+		scenarioSubName string
+		expected        string
+	}{
+		{name: "joined with hyphen", scenarioName: "case-1", scenarioSubName: "10rps", expected: "case-1-10rps"},
+		{name: "subName with hyphen", scenarioName: "case-1", scenarioSubName: "-10rps", expected: "case-1-10rps"},
+		{name: "empty subName", scenarioName: "case-1", scenarioSubName: "", expected: "case-1"},
+		{name: "case and underscore", scenarioName: "Case_1", scenarioSubName: "10RPS", expected: "case-1-10rps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeScenarioName(tt.scenarioName, tt.scenarioSubName))
+		})
+	}
+}
+
+func TestGenerateGatlingLabels(t *testing.T) {
+	labels := GenerateGatlingLabels("sample-service", strings.Repeat("scenario", 10), "10rps", "202308021850-abcd")
+	assert.Equal(t, "sample-service", labels[LabelService])
+	assert.Equal(t, "202308021850-abcd", labels[LabelRunID])
+	assert.NotEqual(
+		t,
+		GenerateGatlingLabels("sample-service", "case-1", "10rps", "202308021850-abcd")[LabelScenario],
+		GenerateGatlingLabels("sample-service", "case-11", "0rps", "202308021850-abcd")[LabelScenario],
+	)
+	for key, value := range labels {
+		assert.Empty(t, validation.IsQualifiedName(key))
+		assert.Empty(t, validation.IsValidLabelValue(value))
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeapiErrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// operatorTimeoutErrorPrefix is prefix of Status.Error which gatling-operator sets when its Job runs out of time.
+const operatorTimeoutErrorPrefix = "Runs out of time"
+
+/*
+classifyJob returns Outcome of Gatling Job and its reason, or empty Outcome while Gatling Job is running.
+
+Status of Gatling object set by gatling-operator is checked first, and conditions of runner Job and reporter Job
+are checked while it is running, because gatling-operator updates status only once in its reconcile interval.
+Error of getting Jobs is returned with Outcome classified by status of Gatling object only.
+*/
+func classifyJob(ctx context.Context, cl ctrlClient.Client, gatling gatlingv1alpha1.Gatling) (Outcome, string, error) {
+	if outcome, reason := classifyStatus(gatling); outcome != "" {
+		return outcome, reason, nil
+	}
+	runnerJob, err := getJob(ctx, cl, gatling.ObjectMeta.Namespace, RunnerJobName(&gatling))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get runner job, %v", err)
+	}
+	var reporterJob *batchv1.Job
+	if gatling.Status.ReporterJobName != "" {
+		reporterJob, err = getJob(ctx, cl, gatling.ObjectMeta.Namespace, gatling.Status.ReporterJobName)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get reporter job, %v", err)
+		}
+	}
+	outcome, reason := classifyJobConditions(gatling, runnerJob, reporterJob)
+	return outcome, reason, nil
+}
+
+/*
+classifyStatus returns Outcome of Gatling Job by status of Gatling object, or empty Outcome while it is running.
+
+gatling-operator sets Status.Error when runner Job or reporter Job failed or ran out of time, and which Job failed
+is decided by Status.RunnerCompleted.
+*/
+func classifyStatus(gatling gatlingv1alpha1.Gatling) (Outcome, string) {
+	if gatling.Status.RunnerCompleted && gatling.Status.ReportCompleted {
+		return OutcomeSucceeded, ""
+	}
+	if gatling.Status.Error == "" {
+		return "", ""
+	}
+	reason := fmt.Sprintf("gatling %v error, %v", gatling.ObjectMeta.Name, gatling.Status.Error)
+	switch {
+	case strings.HasPrefix(gatling.Status.Error, operatorTimeoutErrorPrefix):
+		return OutcomeTimedOut, reason
+	case gatling.Status.RunnerCompleted:
+		return OutcomeReportFailed, reason
+	default:
+		return OutcomeRunnerFailed, reason
+	}
+}
+
+/*
+classifyJobConditions returns Outcome of Gatling Job by runner Job and reporter Job, empty Outcome while running.
+
+Runner Job is failed when it has Failed condition, or Status.Failed count of Gatling object exceeds backoffLimit of
+runner Job. Nil Job means it is not created yet.
+*/
+func classifyJobConditions(gatling gatlingv1alpha1.Gatling, runnerJob, reporterJob *batchv1.Job) (Outcome, string) {
+	if runnerJob != nil {
+		if reason := jobFailedReason(runnerJob); reason != "" {
+			return OutcomeRunnerFailed, fmt.Sprintf("runner job %v failed, %v", runnerJob.ObjectMeta.Name, reason)
+		}
+		if backoffLimit := runnerJob.Spec.BackoffLimit; backoffLimit != nil && gatling.Status.Failed > *backoffLimit {
+			return OutcomeRunnerFailed, fmt.Sprintf(
+				"runner job %v failed %v pods exceeding backoffLimit %v",
+				runnerJob.ObjectMeta.Name,
+				gatling.Status.Failed,
+				*backoffLimit,
+			)
+		}
+	}
+	if reporterJob != nil {
+		if reason := jobFailedReason(reporterJob); reason != "" {
+			return OutcomeReportFailed, fmt.Sprintf("reporter job %v failed, %v", reporterJob.ObjectMeta.Name, reason)
+		}
+	}
+	return "", ""
+}
+
+// jobFailedReason returns reason and message of Failed condition of job, empty when job is not failed.
+func jobFailedReason(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return strings.TrimSpace(fmt.Sprintf("%v %v", condition.Reason, condition.Message))
+		}
+	}
+	return ""
+}
+
+// getJob returns Job which has specified name in namespace, or nil when it is not found.
+func getJob(ctx context.Context, cl ctrlClient.Client, namespace, name string) (*batchv1.Job, error) {
+	var job batchv1.Job
+	err := cl.Get(ctx, ctrlClient.ObjectKey{Name: name, Namespace: namespace}, &job)
+	if kubeapiErrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
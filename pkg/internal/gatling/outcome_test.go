@@ -0,0 +1,194 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         gatlingv1alpha1.GatlingStatus
+		expected       Outcome
+		expectedReason string
+	}{
+		{
+			name:     "running",
+			status:   gatlingv1alpha1.GatlingStatus{RunnerStartTime: 1},
+			expected: "",
+		},
+		{
+			name:     "succeeded",
+			status:   gatlingv1alpha1.GatlingStatus{RunnerStartTime: 1, RunnerCompleted: true, ReportCompleted: true},
+			expected: OutcomeSucceeded,
+		},
+		{
+			name: "runner failed",
+			status: gatlingv1alpha1.GatlingStatus{
+				RunnerStartTime: 1,
+				Error:           "Failed to complete runner job ( failed 3 / backofflimit 2 ). Please review logs",
+			},
+			expected: OutcomeRunnerFailed,
+			expectedReason: "gatling sample error, " +
+				"Failed to complete runner job ( failed 3 / backofflimit 2 ). Please review logs",
+		},
+		{
+			name: "report failed",
+			status: gatlingv1alpha1.GatlingStatus{
+				RunnerStartTime: 1,
+				RunnerCompleted: true,
+				Error:           "Failed to complete reporter job( failed 1 / backofflimit 0 ). Please review logs",
+			},
+			expected: OutcomeReportFailed,
+			expectedReason: "gatling sample error, " +
+				"Failed to complete reporter job( failed 1 / backofflimit 0 ). Please review logs",
+		},
+		{
+			name: "timed out in operator",
+			status: gatlingv1alpha1.GatlingStatus{
+				RunnerStartTime: 1,
+				Error:           "Runs out of time (3600 sec) in running the runner job",
+			},
+			expected:       OutcomeTimedOut,
+			expectedReason: "gatling sample error, Runs out of time (3600 sec) in running the runner job",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gatling := gatlingv1alpha1.Gatling{ObjectMeta: metav1.ObjectMeta{Name: "sample"}, Status: tt.status}
+			outcome, reason := classifyStatus(gatling)
+			assert.Equal(t, tt.expected, outcome)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestClassifyJobConditions(t *testing.T) {
+	backoffLimit := int32(2)
+	newJob := func(name string, conditions ...batchv1.JobCondition) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       batchv1.JobSpec{BackoffLimit: &backoffLimit},
+			Status:     batchv1.JobStatus{Conditions: conditions},
+		}
+	}
+	failedCondition := batchv1.JobCondition{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackoffLimitExceeded",
+		Message: "Job has reached the specified backoff limit",
+	}
+
+	tests := []struct {
+		name           string
+		failed         int32
+		runnerJob      *batchv1.Job
+		reporterJob    *batchv1.Job
+		expected       Outcome
+		expectedReason string
+	}{
+		{
+			name:      "jobs are running",
+			failed:    1,
+			runnerJob: newJob("sample-runner"),
+			expected:  "",
+		},
+		{
+			name:     "jobs are not created",
+			expected: "",
+		},
+		{
+			name:      "runner job failed",
+			runnerJob: newJob("sample-runner", failedCondition),
+			expected:  OutcomeRunnerFailed,
+			expectedReason: "runner job sample-runner failed, " +
+				"BackoffLimitExceeded Job has reached the specified backoff limit",
+		},
+		{
+			name:           "failed runner pods exceeded backoffLimit",
+			failed:         3,
+			runnerJob:      newJob("sample-runner"),
+			expected:       OutcomeRunnerFailed,
+			expectedReason: "runner job sample-runner failed 3 pods exceeding backoffLimit 2",
+		},
+		{
+			name:        "reporter job failed",
+			runnerJob:   newJob("sample-runner", batchv1.JobCondition{Type: batchv1.JobComplete, Status: "True"}),
+			reporterJob: newJob("sample-reporter", failedCondition),
+			expected:    OutcomeReportFailed,
+			expectedReason: "reporter job sample-reporter failed, " +
+				"BackoffLimitExceeded Job has reached the specified backoff limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gatling := gatlingv1alpha1.Gatling{
+				ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+				Status:     gatlingv1alpha1.GatlingStatus{RunnerStartTime: 1, Failed: tt.failed},
+			}
+			outcome, reason := classifyJobConditions(gatling, tt.runnerJob, tt.reporterJob)
+			assert.Equal(t, tt.expected, outcome)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestOutcomeOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Outcome
+	}{
+		{name: "succeeded", err: nil, expected: OutcomeSucceeded},
+		{
+			name:     "runner failed",
+			err:      fmt.Errorf("failed to wait, %w", &RunnerFailedError{Reason: "crashed"}),
+			expected: OutcomeRunnerFailed,
+		},
+		{
+			name:     "startup failed is runner failed",
+			err:      &StartupFailedError{Reason: "bad image"},
+			expected: OutcomeRunnerFailed,
+		},
+		{name: "report failed", err: &ReportFailedError{Reason: "failed"}, expected: OutcomeReportFailed},
+		{name: "timed out", err: &TimedOutError{Reason: "timeout 1 execeeded"}, expected: OutcomeTimedOut},
+		{name: "cancelled", err: &CancelledError{Cause: context.Canceled}, expected: OutcomeCancelled},
+		{name: "not classified", err: fmt.Errorf("failed to get report"), expected: ""},
+		{name: "aborted is not classified", err: &AbortedError{Reason: "cpu"}, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, OutcomeOf(tt.err))
+		})
+	}
+}
@@ -26,7 +26,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
 	"strconv"
+	"strings"
 
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,24 +56,156 @@ type GatlingReport struct {
 	BetweenFromEightHundredToOneThousandTwoHundredMilliSec GatlingReportGroup `json:"group2"`
 	OverOneThousandTwoHundredMilliSec                      GatlingReportGroup `json:"group3"`
 	Failed                                                 GatlingReportGroup `json:"group4"`
+	// Percentiles are percentile of percentiles1 to percentiles4, DefaultReportPercentiles is used when it is empty.
+	Percentiles []float64 `json:"percentiles,omitempty"`
+	// ExactPercentiles has latency of percentile which is not in Percentiles, derived from simulation.log.
+	ExactPercentiles map[string]float64 `json:"exactPercentiles,omitempty"`
 }
 
-// GetPercentileLatency get latency match to specified percentile.
-func (r *GatlingReport) GetPercentileLatency(percentile uint32) (float64, error) {
-	var latency float64
-	switch percentile {
-	case 99:
-		latency = r.NintyNinthPercentiles.Ok
-	case 95:
-		latency = r.NintyFifthPercentiles.Ok
-	case 75:
-		latency = r.SeventyFifthPercentiles.Ok
-	case 50:
-		latency = r.FiftiethPercentiles.Ok
-	default:
-		return 0, fmt.Errorf("specified percentile value is not matched to GatlingReport field")
+// DefaultReportPercentiles is percentile of percentiles1 to percentiles4 of gatling report by default gatling.conf.
+var DefaultReportPercentiles = []float64{50, 75, 95, 99}
+
+// reportPercentilePattern matches header of percentile column in index.html of gatling report, such as "99th pct".
+var reportPercentilePattern = regexp.MustCompile(`(\d+(?:\.\d+)?)(?:st|nd|rd|th) pct`)
+
+/*
+ParseReportPercentiles returns percentile of percentiles1 to percentiles4 from index.html of gatling report.
+
+Percentiles are configured with charting.indicators of gatling.conf, and only shown in headers of the statistics
+table of index.html.
+*/
+func ParseReportPercentiles(html []byte) ([]float64, error) {
+	var percentiles []float64
+	seen := make(map[string]bool)
+	for _, match := range reportPercentilePattern.FindAllSubmatch(html, -1) {
+		value := string(match[1])
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		percentile, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		percentiles = append(percentiles, percentile)
+	}
+	if len(percentiles) != len(DefaultReportPercentiles) {
+		return nil, fmt.Errorf("%v percentiles found in gatling report, expected %v",
+			len(percentiles), len(DefaultReportPercentiles))
+	}
+	return percentiles, nil
+}
+
+// confPercentileKeys are keys of percentile of percentiles1 to percentiles4 in gatling.conf.
+var confPercentileKeys = []string{
+	"charting.indicators.percentile1",
+	"charting.indicators.percentile2",
+	"charting.indicators.percentile3",
+	"charting.indicators.percentile4",
+}
+
+/*
+ParseConfPercentiles returns percentile of percentiles1 to percentiles4 of gatling report configured in gatling.conf.
+
+Keys are read from nested blocks such as "charting { indicators { percentile1 = 99.9 } }" or dotted keys, under root
+"gatling" block. The percentile of DefaultReportPercentiles is used for the key which is not set.
+*/
+func ParseConfPercentiles(conf []byte) ([]float64, error) {
+	percentiles := append([]float64{}, DefaultReportPercentiles...)
+	// braces are split to lines, so that block opened and closed in a line is also read.
+	replacer := strings.NewReplacer("{", "{\n", "}", "\n}\n")
+	var blocks []string
+	for _, line := range strings.Split(replacer.Replace(string(conf)), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case line == "}":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		case strings.HasSuffix(line, "{"):
+			block := strings.TrimSpace(strings.TrimRight(strings.TrimSuffix(line, "{"), " =:"))
+			blocks = append(blocks, strings.Trim(block, `"`))
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			key, value, found = strings.Cut(line, ":")
+		}
+		if !found {
+			continue
+		}
+		fullKey := strings.Join(append(append([]string{}, blocks...), strings.Trim(strings.TrimSpace(key), `"`)), ".")
+		fullKey = strings.TrimPrefix(fullKey, "gatling.")
+		for i, percentileKey := range confPercentileKeys {
+			if fullKey != percentileKey {
+				continue
+			}
+			percentile, err := strconv.ParseFloat(strings.Trim(strings.TrimSpace(value), `"`), 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %v of gatling.conf, %v", percentileKey, err)
+			}
+			percentiles[i] = percentile
+		}
+	}
+	return percentiles, nil
+}
+
+// LoadConfPercentiles returns percentile of percentiles1 to percentiles4 of gatling report configured in confPath.
+func LoadConfPercentiles(confPath string) ([]float64, error) {
+	conf, err := os.ReadFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfPercentiles(conf)
+}
+
+// FormatPercentile returns percentile as string without trailing zeros, such as "99.9", used as key of percentile.
+func FormatPercentile(percentile float64) string {
+	return strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// ReportPercentiles returns percentile of percentiles1 to percentiles4 of the report.
+func (r *GatlingReport) ReportPercentiles() []float64 {
+	if len(r.Percentiles) == len(DefaultReportPercentiles) {
+		return r.Percentiles
+	}
+	return DefaultReportPercentiles
+}
+
+/*
+GetPercentileLatency get latency match to specified percentile.
+
+Percentile is looked up in percentiles of the report first, and then in ExactPercentiles derived from simulation.log.
+*/
+func (r *GatlingReport) GetPercentileLatency(percentile float64) (float64, error) {
+	latencies := []float64{
+		r.FiftiethPercentiles.Ok,
+		r.SeventyFifthPercentiles.Ok,
+		r.NintyFifthPercentiles.Ok,
+		r.NintyNinthPercentiles.Ok,
+	}
+	for i, reportPercentile := range r.ReportPercentiles() {
+		if reportPercentile == percentile {
+			return latencies[i], nil
+		}
+	}
+	if latency, ok := r.ExactPercentiles[FormatPercentile(percentile)]; ok {
+		return latency, nil
 	}
-	return latency, nil
+	return 0, fmt.Errorf(
+		"specified percentile %v is not matched to GatlingReport percentiles %v, and not derived from simulation.log",
+		FormatPercentile(percentile),
+		r.ReportPercentiles(),
+	)
 }
 
 // BytesToGatlingReport parse jsonBytes to GatlingReport object.
@@ -51,7 +51,7 @@ func TestGetPercentileLatency_Success(t *testing.T) {
 	}
 	tests := []struct {
 		name     string
-		input    uint32
+		input    float64
 		expected float64
 	}{
 		{
@@ -84,6 +84,45 @@ func TestGetPercentileLatency_Success(t *testing.T) {
 	}
 }
 
+func TestGetPercentileLatency_CustomizedPercentiles(t *testing.T) {
+	sampleReport := &GatlingReport{
+		FiftiethPercentiles:     GatlingReportStats{Ok: 70},
+		SeventyFifthPercentiles: GatlingReportStats{Ok: 85},
+		NintyFifthPercentiles:   GatlingReportStats{Ok: 100},
+		NintyNinthPercentiles:   GatlingReportStats{Ok: 150},
+		Percentiles:             []float64{50, 90, 99, 99.9},
+		ExactPercentiles:        map[string]float64{"99.99": 300},
+	}
+	tests := []struct {
+		name     string
+		input    float64
+		expected float64
+	}{
+		{
+			name:     "specify 90 percentile of report",
+			input:    90,
+			expected: 85,
+		},
+		{
+			name:     "specify 99.9 percentile of report",
+			input:    99.9,
+			expected: 150,
+		},
+		{
+			name:     "specify 99.99 percentile derived from simulation.log",
+			input:    99.99,
+			expected: 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latency, err := sampleReport.GetPercentileLatency(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, latency)
+		})
+	}
+}
+
 func TestGetPercentileLatency_Fail(t *testing.T) {
 	SampleReport := &GatlingReport{
 		FiftiethPercentiles: GatlingReportStats{
@@ -101,13 +140,16 @@ func TestGetPercentileLatency_Fail(t *testing.T) {
 	}
 	tests := []struct {
 		name     string
-		input    uint32
+		input    float64
 		expected error
 	}{
 		{
-			name:     "invalid percentile value",
-			input:    80,
-			expected: fmt.Errorf("specified percentile value is not matched to GatlingReport field"),
+			name:  "invalid percentile value",
+			input: 80,
+			expected: fmt.Errorf(
+				"specified percentile 80 is not matched to GatlingReport percentiles [50 75 95 99], " +
+					"and not derived from simulation.log",
+			),
 		},
 	}
 	for _, tt := range tests {
@@ -119,6 +161,104 @@ func TestGetPercentileLatency_Fail(t *testing.T) {
 	}
 }
 
+func TestParseReportPercentiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		expected    []float64
+		expectedErr error
+	}{
+		{
+			name: "default percentiles",
+			html: `<th class="header"><span>50th pct</span></th><th class="header"><span>75th pct</span></th>` +
+				`<th class="header"><span>95th pct</span></th><th class="header"><span>99th pct</span></th>` +
+				`<td>99th pct</td>`,
+			expected: []float64{50, 75, 95, 99},
+		},
+		{
+			name:     "customized percentiles",
+			html:     `<span>1st pct</span><span>90th pct</span><span>99th pct</span><span>99.9th pct</span>`,
+			expected: []float64{1, 90, 99, 99.9},
+		},
+		{
+			name:        "percentiles not found",
+			html:        `<html></html>`,
+			expectedErr: fmt.Errorf("0 percentiles found in gatling report, expected 4"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percentiles, err := ParseReportPercentiles([]byte(tt.html))
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, percentiles)
+		})
+	}
+}
+
+func TestParseConfPercentiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		conf        string
+		expected    []float64
+		expectedErr error
+	}{
+		{
+			name: "default percentiles",
+			conf: `gatling {
+  charting {
+    indicators {
+      #percentile1 = 50      # Value for the 1st percentile to track in the reports
+      #percentile4 = 99      # Value for the 4th percentile to track in the reports
+    }
+  }
+}`,
+			expected: []float64{50, 75, 95, 99},
+		},
+		{
+			name: "customized percentiles",
+			conf: `gatling {
+  charting {
+    indicators {
+      percentile3 = 99      # Value for the 3rd percentile to track in the reports
+      percentile4 = 99.9
+    }
+  }
+  http { percentile1 = 1 }
+}`,
+			expected: []float64{50, 75, 99, 99.9},
+		},
+		{
+			name:     "dotted keys",
+			conf:     "gatling.charting.indicators.percentile1 = 90\ngatling { charting.indicators { percentile2: \"95\" } }",
+			expected: []float64{90, 95, 95, 99},
+		},
+		{
+			name: "invalid percentile",
+			conf: "gatling.charting.indicators.percentile4 = high",
+			expectedErr: fmt.Errorf(
+				"failed to parse charting.indicators.percentile4 of gatling.conf, %v",
+				`strconv.ParseFloat: parsing "high": invalid syntax`,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percentiles, err := ParseConfPercentiles([]byte(tt.conf))
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, percentiles)
+		})
+	}
+}
+
+func TestLoadConfPercentiles(t *testing.T) {
+	percentiles, err := LoadConfPercentiles("../../../gatling/conf/gatling.conf")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultReportPercentiles, percentiles)
+
+	_, err = LoadConfPercentiles("testdata/not_exists.conf")
+	assert.Error(t, err)
+}
+
 func TestExtractLoadtestConditionToReport(t *testing.T) {
 	tests := []struct {
 		name                string
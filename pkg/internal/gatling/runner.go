@@ -0,0 +1,96 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RunnerContainerName is the name of container which runs Gatling in runner pod created by gatling-operator.
+	RunnerContainerName = "gatling-runner"
+	// RunnerJobNameLabelKey is label key of runner pods, which value is the name of runner Job.
+	RunnerJobNameLabelKey = "job-name"
+	// runnerLogTailLines is the number of log lines read for finding the latest console stats.
+	runnerLogTailLines = int64(200)
+)
+
+// globalStatsPattern matches the global request count line of Gatling console stats, e.g. "> Global (OK=10 KO=2 )".
+var globalStatsPattern = regexp.MustCompile(`>\s*Global\s+\(OK=(\d+)\s+KO=(\d+)\s*\)`)
+
+// RunnerJobName returns the name of runner Job which gatling-operator creates for gatling.
+func RunnerJobName(gatling *gatlingv1alpha1.Gatling) string {
+	return gatling.ObjectMeta.Name + "-runner"
+}
+
+/*
+CountRunnerKO returns sum of KO request count of the latest console stats printed by each runner pod.
+
+Runner pods are found by job-name label of the runner Job which gatling-operator creates.
+Pods which have not printed console stats yet are counted as 0.
+*/
+func CountRunnerKO(ctx context.Context, cs kubernetes.Interface, gatling *gatlingv1alpha1.Gatling) (int64, error) {
+	namespace := gatling.ObjectMeta.Namespace
+	pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%v=%v", RunnerJobNameLabelKey, RunnerJobName(gatling)),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list runner pods, %v", err)
+	}
+	tailLines := runnerLogTailLines
+	var total int64
+	for _, pod := range pods.Items {
+		logs, err := cs.CoreV1().Pods(namespace).GetLogs(pod.ObjectMeta.Name, &corev1.PodLogOptions{
+			Container: RunnerContainerName,
+			TailLines: &tailLines,
+		}).DoRaw(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get logs of runner pod %v, %v", pod.ObjectMeta.Name, err)
+		}
+		if ko, ok := parseLatestKO(string(logs)); ok {
+			total += ko
+		}
+	}
+	return total, nil
+}
+
+// parseLatestKO returns KO request count of the last console stats in logs.
+func parseLatestKO(logs string) (int64, bool) {
+	matches := globalStatsPattern.FindAllStringSubmatch(logs, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	ko, err := strconv.ParseInt(matches[len(matches)-1][2], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return ko, true
+}
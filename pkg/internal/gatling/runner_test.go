@@ -0,0 +1,68 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLatestKO(t *testing.T) {
+	tests := []struct {
+		name       string
+		logs       string
+		expectedKO int64
+		expectedOK bool
+	}{
+		{
+			name: "KO count of the last console stats",
+			logs: `================================================================================
+2023-11-13 10:50:05                                           5s elapsed
+---- Requests ------------------------------------------------------------------
+> Global                                                   (OK=120    KO=3     )
+> get sample                                               (OK=120    KO=3     )
+================================================================================
+2023-11-13 10:50:10                                          10s elapsed
+---- Requests ------------------------------------------------------------------
+> Global                                                   (OK=240    KO=15    )
+> get sample                                               (OK=240    KO=15    )
+`,
+			expectedKO: 15,
+			expectedOK: true,
+		},
+		{
+			name:       "console stats not printed yet",
+			logs:       "Simulation SampleSimulation started...\n",
+			expectedKO: 0,
+			expectedOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ko, ok := parseLatestKO(tt.logs)
+			assert.Equal(t, tt.expectedKO, ko)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
@@ -0,0 +1,420 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// simulationLogMaxLineBytes is max length of a record in simulation.log, KO message of request may be long.
+const simulationLogMaxLineBytes = 1024 * 1024
+
+/*
+simulationLogMaxDuration is max duration of simulation.
+
+Requests started out of it from the start of the simulation are regarded as malformed, so that a bogus timestamp
+does not make the series too long.
+*/
+const simulationLogMaxDuration = 24 * time.Hour
+
+/*
+SimulationLogStats has time series and summary of requests parsed from simulation.log of Gatling runners.
+
+Summary excludes requests started in the warm-up window of WarmUpSec from the start of the simulation, while Series
+has all seconds of the simulation.
+*/
+type SimulationLogStats struct {
+	WarmUpSec int32                `json:"warmUpSec"`
+	Series    []SimulationLogPoint `json:"series"`
+	Summary   SimulationLogSummary `json:"summary"`
+	/*
+		MalformedRecords is count of REQUEST records which can not be parsed, such as truncated last record, or which
+		started before the simulation or simulationLogMaxDuration after it.
+	*/
+	MalformedRecords int64 `json:"malformedRecords,omitempty"`
+}
+
+/*
+SimulationLogPoint has stats of requests started in a second.
+
+Requests is same as requests per second. Latency is calculated from OK requests same as Gatling report, and
+ErrorRate is percentage of KO requests.
+*/
+type SimulationLogPoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Requests    int64     `json:"requests"`
+	Failed      int64     `json:"failed"`
+	ErrorRate   float64   `json:"errorRate"`   // %
+	MeanLatency float64   `json:"meanLatency"` // ms
+	P50Latency  float64   `json:"p50Latency"`  // ms
+	P95Latency  float64   `json:"p95Latency"`  // ms
+	P99Latency  float64   `json:"p99Latency"`  // ms
+	MaxLatency  float64   `json:"maxLatency"`  // ms
+}
+
+// SimulationLogSummary has stats of requests out of warm-up window.
+type SimulationLogSummary struct {
+	StartedAt        time.Time `json:"startedAt"`
+	FinishedAt       time.Time `json:"finishedAt"`
+	Requests         int64     `json:"requests"`
+	Failed           int64     `json:"failed"`
+	FailedPercentage float64   `json:"failedPercentage"` // %
+	Throughput       float64   `json:"throughput"`       // req/s
+	MeanLatency      float64   `json:"meanLatency"`      // ms
+	P50Latency       float64   `json:"p50Latency"`       // ms
+	P75Latency       float64   `json:"p75Latency"`       // ms
+	P95Latency       float64   `json:"p95Latency"`       // ms
+	P99Latency       float64   `json:"p99Latency"`       // ms
+	MaxLatency       float64   `json:"maxLatency"`       // ms
+	// Percentiles has latency of percentiles specified to SimulationLogParser keyed by FormatPercentile.
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+}
+
+/*
+SimulationLogParser aggregates REQUEST records of simulation.log by second of request start.
+
+Latency is kept as count of each millisecond value, so memory usage depends on the length of the simulation and the
+distribution of latency, not on the number of requests.
+*/
+type SimulationLogParser struct {
+	warmUpSec        int32
+	percentiles      []float64 // additional percentiles of summary
+	runStart         int64     // unix ms of the earliest RUN record, 0 when it is not found
+	seconds          map[int64]*secondStats
+	malformedRecords int64
+}
+
+// secondStats has requests started in a second.
+type secondStats struct {
+	requests int64
+	failed   int64
+	latency  latencyHistogram // OK requests only
+}
+
+// latencyHistogram has count of each latency value in ms.
+type latencyHistogram struct {
+	counts map[int64]int64
+	count  int64
+	sum    int64
+	max    int64
+}
+
+/*
+NewSimulationLogParser returns SimulationLogParser which excludes warmUpSec seconds from summary.
+
+Latency of each of percentiles, such as 99.9, is calculated exactly for summary in addition to the fixed ones.
+*/
+func NewSimulationLogParser(warmUpSec int32, percentiles ...float64) *SimulationLogParser {
+	return &SimulationLogParser{
+		warmUpSec:   warmUpSec,
+		percentiles: percentiles,
+		seconds:     make(map[int64]*secondStats),
+	}
+}
+
+/*
+Parse reads simulation.log of a Gatling runner record by record and adds its requests to the parser.
+
+Parse can be called for each runner, requests of all runners are aggregated into the same seconds.
+Only text format of simulation.log, which starts with RUN record, is supported.
+*/
+func (p *SimulationLogParser) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), simulationLogMaxLineBytes)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if first {
+			if fields[0] != "RUN" {
+				return fmt.Errorf("unsupported simulation.log format, first record is not RUN")
+			}
+			first = false
+		}
+		switch fields[0] {
+		case "RUN":
+			p.parseRun(fields)
+		case "REQUEST":
+			p.parseRequest(fields)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read simulation.log, %v", err)
+	}
+	if first {
+		return fmt.Errorf("simulation.log is empty")
+	}
+	return nil
+}
+
+// parseRun keeps the earliest start timestamp of RUN record, such as "RUN\tclass\tid\t<start>\tdescription\t3.9".
+func (p *SimulationLogParser) parseRun(fields []string) {
+	if len(fields) < 4 {
+		return
+	}
+	start, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return
+	}
+	if p.runStart == 0 || start < p.runStart {
+		p.runStart = start
+	}
+}
+
+/*
+parseRequest adds REQUEST record to the second of its start.
+
+Columns of REQUEST record differ by Gatling version, so status OK or KO preceded by start and end timestamps is
+searched, such as "REQUEST\t<group>\t<name>\t<start>\t<end>\tOK\t<message>".
+*/
+func (p *SimulationLogParser) parseRequest(fields []string) {
+	for i := 3; i < len(fields); i++ {
+		if fields[i] != "OK" && fields[i] != "KO" {
+			continue
+		}
+		start, err := strconv.ParseInt(fields[i-2], 10, 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseInt(fields[i-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		second := start / 1000
+		stats, ok := p.seconds[second]
+		if !ok {
+			stats = &secondStats{}
+			p.seconds[second] = stats
+		}
+		stats.requests++
+		if fields[i] == "KO" {
+			stats.failed++
+		} else {
+			stats.latency.add(end - start)
+		}
+		return
+	}
+	p.malformedRecords++
+}
+
+// Stats returns time series of all seconds and summary out of warm-up window of parsed requests.
+func (p *SimulationLogParser) Stats() *SimulationLogStats {
+	result := &SimulationLogStats{
+		WarmUpSec:        p.warmUpSec,
+		Series:           []SimulationLogPoint{},
+		MalformedRecords: p.malformedRecords,
+	}
+	if len(p.seconds) == 0 {
+		return result
+	}
+	keys := make([]int64, 0, len(p.seconds))
+	for second := range p.seconds {
+		keys = append(keys, second)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	minSecond, maxSecond := p.secondRange(keys)
+	validKeys := make([]int64, 0, len(keys))
+	for _, second := range keys {
+		if second < minSecond || second > maxSecond {
+			result.MalformedRecords += p.seconds[second].requests
+			continue
+		}
+		validKeys = append(validKeys, second)
+	}
+	if len(validKeys) == 0 {
+		return result
+	}
+	firstSecond, lastSecond := validKeys[0], validKeys[len(validKeys)-1]
+
+	simulationStart := firstSecond * 1000
+	if p.runStart != 0 && p.runStart < simulationStart {
+		simulationStart = p.runStart
+	}
+	warmUpEnd := simulationStart + int64(p.warmUpSec)*1000
+
+	var summary secondStats
+	summaryFirst, summaryLast := int64(-1), int64(-1)
+	// Seconds without request are filled with zero, so that the series has same interval.
+	for second := firstSecond; second <= lastSecond; second++ {
+		stats, ok := p.seconds[second]
+		if !ok {
+			stats = &secondStats{}
+		}
+		result.Series = append(result.Series, stats.point(time.Unix(second, 0).UTC()))
+		if second*1000 < warmUpEnd {
+			continue
+		}
+		if summaryFirst < 0 {
+			summaryFirst = second
+		}
+		summaryLast = second
+		summary.requests += stats.requests
+		summary.failed += stats.failed
+		summary.latency.merge(&stats.latency)
+	}
+	if summaryFirst < 0 {
+		return result
+	}
+	percentiles := summary.latency.percentiles(50, 75, 95, 99)
+	result.Summary = SimulationLogSummary{
+		StartedAt:        time.Unix(summaryFirst, 0).UTC(),
+		FinishedAt:       time.Unix(summaryLast+1, 0).UTC(),
+		Requests:         summary.requests,
+		Failed:           summary.failed,
+		FailedPercentage: percentage(summary.failed, summary.requests),
+		Throughput:       float64(summary.requests) / float64(summaryLast-summaryFirst+1),
+		MeanLatency:      summary.latency.mean(),
+		P50Latency:       percentiles[0],
+		P75Latency:       percentiles[1],
+		P95Latency:       percentiles[2],
+		P99Latency:       percentiles[3],
+		MaxLatency:       float64(summary.latency.max),
+	}
+	if len(p.percentiles) > 0 {
+		result.Summary.Percentiles = make(map[string]float64, len(p.percentiles))
+		for i, latency := range summary.latency.percentiles(p.percentiles...) {
+			result.Summary.Percentiles[FormatPercentile(p.percentiles[i])] = latency
+		}
+	}
+	return result
+}
+
+/*
+secondRange returns the first and the last second in which requests are aggregated, keys are sorted seconds.
+
+The range is simulationLogMaxDuration from the start of RUN record. When the start is not found, the range is
+simulationLogMaxDuration before and after the median second of requests.
+*/
+func (p *SimulationLogParser) secondRange(keys []int64) (int64, int64) {
+	maxSeconds := int64(simulationLogMaxDuration / time.Second)
+	if p.runStart != 0 {
+		runStartSecond := p.runStart / 1000
+		return runStartSecond, runStartSecond + maxSeconds
+	}
+	median := keys[len(keys)/2]
+	return median - maxSeconds, median + maxSeconds
+}
+
+// point returns SimulationLogPoint of the second.
+func (s *secondStats) point(timestamp time.Time) SimulationLogPoint {
+	percentiles := s.latency.percentiles(50, 95, 99)
+	return SimulationLogPoint{
+		Timestamp:   timestamp,
+		Requests:    s.requests,
+		Failed:      s.failed,
+		ErrorRate:   percentage(s.failed, s.requests),
+		MeanLatency: s.latency.mean(),
+		P50Latency:  percentiles[0],
+		P95Latency:  percentiles[1],
+		P99Latency:  percentiles[2],
+		MaxLatency:  float64(s.latency.max),
+	}
+}
+
+// add adds latency value in ms to histogram.
+func (h *latencyHistogram) add(latency int64) {
+	if h.counts == nil {
+		h.counts = make(map[int64]int64)
+	}
+	h.counts[latency]++
+	h.count++
+	h.sum += latency
+	if latency > h.max {
+		h.max = latency
+	}
+}
+
+// merge adds all values of other histogram to histogram.
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	if other.count == 0 {
+		return
+	}
+	if h.counts == nil {
+		h.counts = make(map[int64]int64)
+	}
+	for latency, count := range other.counts {
+		h.counts[latency] += count
+	}
+	h.count += other.count
+	h.sum += other.sum
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// mean returns mean latency, 0 when histogram has no value.
+func (h *latencyHistogram) mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return float64(h.sum) / float64(h.count)
+}
+
+/*
+percentiles returns latency of each percentile by nearest-rank method, which is the smallest latency of which
+cumulative count is not less than percentile of all count. 0 is returned when histogram has no value.
+*/
+func (h *latencyHistogram) percentiles(percentiles ...float64) []float64 {
+	results := make([]float64, len(percentiles))
+	if h.count == 0 {
+		return results
+	}
+	latencies := make([]int64, 0, len(h.counts))
+	for latency := range h.counts {
+		latencies = append(latencies, latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	for i, percentile := range percentiles {
+		rank := int64(math.Ceil(percentile / 100 * float64(h.count)))
+		if rank < 1 {
+			rank = 1
+		}
+		var cumulative int64
+		for _, latency := range latencies {
+			cumulative += h.counts[latency]
+			if cumulative >= rank {
+				results[i] = float64(latency)
+				break
+			}
+		}
+	}
+	return results
+}
+
+// percentage returns part / total * 100, 0 when total is 0.
+func percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
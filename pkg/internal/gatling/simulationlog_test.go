@@ -0,0 +1,193 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runner logs of same simulation, second one has userId column of older Gatling version.
+const (
+	sampleSimulationLog1 = "RUN\tcom.example.SampleSimulation\tsamplesimulation\t1700000000000\t \t3.9.5\n" +
+		"USER\tsample\tSTART\t1700000000100\n" +
+		"REQUEST\t\thome\t1700000000100\t1700000000110\tOK\t \n" +
+		"REQUEST\t\thome\t1700000000200\t1700000000230\tOK\t \n" +
+		"REQUEST\tcheckout\tpay\t1700000001000\t1700000001100\tKO\tstatus.find.is(200), but actually found 500\n" +
+		"REQUEST\t\thome\t1700000002000\t1700000002050\tOK\t \n" +
+		"REQUEST\t\thome\t1700000002500"
+	sampleSimulationLog2 = "RUN\tcom.example.SampleSimulation\tsamplesimulation\t1700000000500\t \t3.0\n" +
+		"REQUEST\t1\t\thome\t1700000002100\t1700000002120\tOK\t \n" +
+		"REQUEST\t1\t\thome\t1700000003000\t1700000003040\tOK\t \n"
+)
+
+func TestSimulationLogParser(t *testing.T) {
+	expectedSeries := []SimulationLogPoint{
+		{
+			Timestamp: time.Unix(1700000000, 0).UTC(), Requests: 2, MeanLatency: 20,
+			P50Latency: 10, P95Latency: 30, P99Latency: 30, MaxLatency: 30,
+		},
+		{Timestamp: time.Unix(1700000001, 0).UTC(), Requests: 1, Failed: 1, ErrorRate: 100},
+		{
+			Timestamp: time.Unix(1700000002, 0).UTC(), Requests: 2, MeanLatency: 35,
+			P50Latency: 20, P95Latency: 50, P99Latency: 50, MaxLatency: 50,
+		},
+		{
+			Timestamp: time.Unix(1700000003, 0).UTC(), Requests: 1, MeanLatency: 40,
+			P50Latency: 40, P95Latency: 40, P99Latency: 40, MaxLatency: 40,
+		},
+	}
+	tests := []struct {
+		name            string
+		warmUpSec       int32
+		expectedSummary SimulationLogSummary
+	}{
+		{
+			name:      "without warm-up",
+			warmUpSec: 0,
+			expectedSummary: SimulationLogSummary{
+				StartedAt:        time.Unix(1700000000, 0).UTC(),
+				FinishedAt:       time.Unix(1700000004, 0).UTC(),
+				Requests:         6,
+				Failed:           1,
+				FailedPercentage: float64(1) / 6 * 100,
+				Throughput:       1.5,
+				MeanLatency:      30,
+				P50Latency:       30,
+				P75Latency:       40,
+				P95Latency:       50,
+				P99Latency:       50,
+				MaxLatency:       50,
+			},
+		},
+		{
+			name:      "exclude warm-up window",
+			warmUpSec: 2,
+			expectedSummary: SimulationLogSummary{
+				StartedAt:   time.Unix(1700000002, 0).UTC(),
+				FinishedAt:  time.Unix(1700000004, 0).UTC(),
+				Requests:    3,
+				Throughput:  1.5,
+				MeanLatency: float64(110) / 3,
+				P50Latency:  40,
+				P75Latency:  50,
+				P95Latency:  50,
+				P99Latency:  50,
+				MaxLatency:  50,
+			},
+		},
+		{
+			name:      "warm-up longer than simulation",
+			warmUpSec: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewSimulationLogParser(tt.warmUpSec)
+			assert.NoError(t, parser.Parse(strings.NewReader(sampleSimulationLog1)))
+			assert.NoError(t, parser.Parse(strings.NewReader(sampleSimulationLog2)))
+			stats := parser.Stats()
+			assert.Equal(t, tt.warmUpSec, stats.WarmUpSec)
+			assert.Equal(t, expectedSeries, stats.Series)
+			assert.Equal(t, tt.expectedSummary, stats.Summary)
+			assert.Equal(t, int64(1), stats.MalformedRecords)
+		})
+	}
+}
+
+func TestSimulationLogParser_OutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{
+			name: "bounded from start of RUN record",
+			log: "RUN\tcom.example.SampleSimulation\tsamplesimulation\t1700000000000\t \t3.9.5\n" +
+				"REQUEST\t\thome\t0\t10\tOK\t \n" +
+				"REQUEST\t\thome\t1700000000100\t1700000000110\tOK\t \n" +
+				"REQUEST\t\thome\t1700000001100\t1700000001120\tOK\t \n" +
+				"REQUEST\t\thome\t9999999999000\t9999999999010\tOK\t \n",
+		},
+		{
+			name: "bounded around median second without start of RUN record",
+			log: "RUN\tcom.example.SampleSimulation\tsamplesimulation\t \t \t3.9.5\n" +
+				"REQUEST\t\thome\t0\t10\tOK\t \n" +
+				"REQUEST\t\thome\t1700000000100\t1700000000110\tOK\t \n" +
+				"REQUEST\t\thome\t1700000001100\t1700000001120\tOK\t \n" +
+				"REQUEST\t\thome\t9999999999000\t9999999999010\tOK\t \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewSimulationLogParser(0)
+			assert.NoError(t, parser.Parse(strings.NewReader(tt.log)))
+			stats := parser.Stats()
+			assert.Equal(t, int64(2), stats.MalformedRecords)
+			assert.Len(t, stats.Series, 2)
+			assert.Equal(t, time.Unix(1700000000, 0).UTC(), stats.Series[0].Timestamp)
+			assert.Equal(t, int64(2), stats.Summary.Requests)
+		})
+	}
+}
+
+func TestSimulationLogParser_Percentiles(t *testing.T) {
+	parser := NewSimulationLogParser(0, 60, 99.9)
+	assert.NoError(t, parser.Parse(strings.NewReader(sampleSimulationLog1)))
+	assert.NoError(t, parser.Parse(strings.NewReader(sampleSimulationLog2)))
+	assert.Equal(t, map[string]float64{"60": 30, "99.9": 50}, parser.Stats().Summary.Percentiles)
+}
+
+func TestSimulationLogParser_Failed(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		expected error
+	}{
+		{
+			name:     "binary format",
+			log:      "\x00\x01\x02binary",
+			expected: fmt.Errorf("unsupported simulation.log format, first record is not RUN"),
+		},
+		{
+			name:     "empty",
+			log:      "",
+			expected: fmt.Errorf("simulation.log is empty"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewSimulationLogParser(0)
+			assert.Equal(t, tt.expected, parser.Parse(strings.NewReader(tt.log)))
+		})
+	}
+}
+
+func TestSimulationLogParser_NoRequest(t *testing.T) {
+	parser := NewSimulationLogParser(0)
+	assert.NoError(t, parser.Parse(strings.NewReader("RUN\tcom.example.SampleSimulation\tsample\t1700000000000\n")))
+	assert.Equal(t, &SimulationLogStats{Series: []SimulationLogPoint{}}, parser.Stats())
+}
@@ -0,0 +1,218 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeapiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// imagePullWaitingReasons are waiting reasons of container of which message can tell the image reference is wrong.
+var imagePullWaitingReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+}
+
+// imagePullEventMessagePrefix is prefix of Failed event message of kubelet on image pull error.
+const imagePullEventMessagePrefix = "failed to pull image"
+
+// badImageMessages are parts of image pull error messages which mean the image reference is wrong.
+var badImageMessages = []string{
+	"not found",
+	"manifest unknown",
+	"repository does not exist",
+	"invalid reference format",
+}
+
+// startupIssue is a reason why Gatling Job has not started.
+type startupIssue struct {
+	reason string
+	// terminal is true when the issue never resolves by waiting, such as a bad image reference.
+	terminal bool
+}
+
+// startupState has state of Gatling Job startup found by inspectStartup.
+type startupState struct {
+	started bool
+	issues  []startupIssue
+}
+
+/*
+inspectStartup inspects Gatling object, runner Job, runner pods and their Warning events since specified time.
+
+Gatling Job is regarded as started when runner container of any runner pod started, or runner Job finished.
+gatling-operator sets Status.RunnerStartTime when runner Job is created, so runner pods may not be started yet.
+Image pull errors are terminal only when they mean the image reference is wrong, and no issue is terminal once
+Gatling Job started, since failure of started Gatling Job is detected while waiting it running.
+*/
+func inspectStartup(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	gatling gatlingv1alpha1.Gatling,
+	since time.Time,
+) (startupState, error) {
+	var state startupState
+	if gatling.Status.Error != "" {
+		state.issues = append(state.issues, startupIssue{
+			reason: fmt.Sprintf("gatling %v error, %v", gatling.ObjectMeta.Name, gatling.Status.Error), terminal: true,
+		})
+		return state, nil
+	}
+	if gatling.Status.RunnerCompleted {
+		state.started = true
+		return state, nil
+	}
+	namespace := gatling.ObjectMeta.Namespace
+	involvedObjects := map[string]bool{gatling.ObjectMeta.Name: true}
+	if gatling.Status.RunnerStartTime > 0 {
+		jobName := RunnerJobName(&gatling)
+		involvedObjects[jobName] = true
+		var job batchv1.Job
+		err := cl.Get(ctx, ctrlClient.ObjectKey{Name: jobName, Namespace: namespace}, &job)
+		if err != nil && !kubeapiErrors.IsNotFound(err) {
+			return state, fmt.Errorf("failed to get runner job, %v", err)
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				state.started = true
+			case batchv1.JobFailed:
+				state.issues = append(state.issues, startupIssue{
+					reason:   fmt.Sprintf("runner job %v failed, %v %v", jobName, condition.Reason, condition.Message),
+					terminal: true,
+				})
+			}
+		}
+
+		var pods corev1.PodList
+		if err := cl.List(ctx, &pods, ctrlClient.InNamespace(namespace), ctrlClient.MatchingLabels{
+			RunnerJobNameLabelKey: jobName,
+		}); err != nil {
+			return state, fmt.Errorf("failed to list runner pods, %v", err)
+		}
+		for _, pod := range pods.Items {
+			involvedObjects[pod.ObjectMeta.Name] = true
+			if containerStarted(pod, RunnerContainerName) {
+				state.started = true
+			}
+			state.issues = append(state.issues, inspectPod(pod)...)
+		}
+	}
+
+	if state.started {
+		for i := range state.issues {
+			state.issues[i].terminal = false
+		}
+	}
+
+	// events are listed for each object of this run, not to scan all events in the namespace every inspection.
+	var events []corev1.Event
+	for name := range involvedObjects {
+		var eventList corev1.EventList
+		if err := cl.List(ctx, &eventList, ctrlClient.InNamespace(namespace), ctrlClient.MatchingFieldsSelector{
+			Selector: fields.SelectorFromSet(fields.Set{
+				"involvedObject.name": name,
+				"type":                corev1.EventTypeWarning,
+			}),
+		}); err != nil {
+			return state, fmt.Errorf("failed to list events of %v, %v", name, err)
+		}
+		events = append(events, eventList.Items...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	for _, e := range events {
+		lastTimestamp := e.LastTimestamp.Time
+		if lastTimestamp.IsZero() {
+			lastTimestamp = e.EventTime.Time
+		}
+		if lastTimestamp.Before(since) {
+			continue
+		}
+		state.issues = append(state.issues, startupIssue{
+			reason: fmt.Sprintf(
+				"%v %v event %v, %v", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, e.Reason, e.Message,
+			),
+			terminal: !state.started && e.Reason == "Failed" &&
+				strings.HasPrefix(strings.ToLower(e.Message), imagePullEventMessagePrefix) &&
+				isBadImageMessage(e.Message),
+		})
+	}
+	return state, nil
+}
+
+// inspectPod returns reasons why containers of runner pod have not started.
+func inspectPod(pod corev1.Pod) []startupIssue {
+	var issues []startupIssue
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			issues = append(issues, startupIssue{
+				reason: fmt.Sprintf("pod %v %v, %v", pod.ObjectMeta.Name, condition.Reason, condition.Message),
+			})
+		}
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses,
+	} {
+		for _, status := range statuses {
+			waiting := status.State.Waiting
+			// ContainerCreating and PodInitializing are waiting reasons of normal startup.
+			if waiting == nil || waiting.Reason == "" || waiting.Reason == "ContainerCreating" ||
+				waiting.Reason == "PodInitializing" {
+				continue
+			}
+			issues = append(issues, startupIssue{
+				reason: fmt.Sprintf(
+					"pod %v container %v %v, %v", pod.ObjectMeta.Name, status.Name, waiting.Reason, waiting.Message,
+				),
+				terminal: imagePullWaitingReasons[waiting.Reason] && isBadImageMessage(waiting.Message),
+			})
+		}
+	}
+	return issues
+}
+
+// isBadImageMessage returns true when image pull error message means the image reference is wrong.
+func isBadImageMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, badImageMessage := range badImageMessages {
+		if strings.Contains(message, badImageMessage) {
+			return true
+		}
+	}
+	return false
+}
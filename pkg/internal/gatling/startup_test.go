@@ -0,0 +1,303 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestInspectPod(t *testing.T) {
+	newPod := func(status corev1.PodStatus) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "sample-pod"}, Status: status}
+	}
+	waiting := func(reason, message string) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{
+			{
+				Name:  RunnerContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: message}},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		pod      corev1.Pod
+		expected []startupIssue
+	}{
+		{
+			name:     "container creating",
+			pod:      newPod(corev1.PodStatus{ContainerStatuses: waiting("ContainerCreating", "")}),
+			expected: nil,
+		},
+		{
+			name: "unschedulable",
+			pod: newPod(corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  "Unschedulable",
+					Message: "0/3 nodes are available: 3 node(s) didn't match pod affinity rules.",
+				},
+			}}),
+			expected: []startupIssue{
+				{reason: "pod sample-pod Unschedulable, 0/3 nodes are available: 3 node(s) didn't match pod affinity rules."},
+			},
+		},
+		{
+			name: "image pull back off by registry error",
+			pod: newPod(corev1.PodStatus{
+				ContainerStatuses: waiting("ErrImagePull", "rpc error: code = Unknown desc = 429 Too Many Requests"),
+			}),
+			expected: []startupIssue{
+				{reason: "pod sample-pod container gatling-runner ErrImagePull, " +
+					"rpc error: code = Unknown desc = 429 Too Many Requests"},
+			},
+		},
+		{
+			name: "image not found",
+			pod: newPod(corev1.PodStatus{
+				InitContainerStatuses: waiting("ErrImagePull", `rpc error: code = NotFound desc = "gatling:x" not found`),
+			}),
+			expected: []startupIssue{
+				{
+					reason: "pod sample-pod container gatling-runner ErrImagePull, " +
+						`rpc error: code = NotFound desc = "gatling:x" not found`,
+					terminal: true,
+				},
+			},
+		},
+		{
+			name: "invalid image name",
+			pod: newPod(corev1.PodStatus{
+				ContainerStatuses: waiting("InvalidImageName", `couldn't parse image reference "gatling::x"`),
+			}),
+			expected: []startupIssue{
+				{
+					reason: "pod sample-pod container gatling-runner InvalidImageName, " +
+						`couldn't parse image reference "gatling::x"`,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inspectPod(tt.pod))
+		})
+	}
+}
+
+/*
+eventFieldsClient is client which lists events with field selector of multiple fields.
+
+Fake client only supports field selector of a single indexed field, so events are filtered here instead. Listing
+events without field selector fails, since it scans all events in the namespace.
+*/
+type eventFieldsClient struct {
+	ctrlClient.WithWatch
+}
+
+func (c eventFieldsClient) List(ctx context.Context, list ctrlClient.ObjectList, opts ...ctrlClient.ListOption) error {
+	eventList, ok := list.(*corev1.EventList)
+	if !ok {
+		return c.WithWatch.List(ctx, list, opts...)
+	}
+	listOptions := (&ctrlClient.ListOptions{}).ApplyOptions(opts)
+	selector := listOptions.FieldSelector
+	if selector == nil || selector.Empty() {
+		return fmt.Errorf("events must be listed with field selector")
+	}
+	listOptions.FieldSelector = nil
+	if err := c.WithWatch.List(ctx, eventList, listOptions); err != nil {
+		return err
+	}
+	var items []corev1.Event
+	for _, e := range eventList.Items {
+		if selector.Matches(fields.Set{"involvedObject.name": e.InvolvedObject.Name, "type": e.Type}) {
+			items = append(items, e)
+		}
+	}
+	eventList.Items = items
+	return nil
+}
+
+func TestInspectStartup(t *testing.T) {
+	ctx := context.TODO()
+	since := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	gatling := gatlingv1alpha1.Gatling{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-gatling", Namespace: "sample-namespace"},
+		Status:     gatlingv1alpha1.GatlingStatus{RunnerStartTime: int32(since.Unix())},
+	}
+	newRunnerPod := func(state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sample-gatling-runner-a",
+				Namespace: "sample-namespace",
+				Labels:    map[string]string{RunnerJobNameLabelKey: "sample-gatling-runner"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: RunnerContainerName, State: state}},
+			},
+		}
+	}
+	quotaEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "quota-event", Namespace: "sample-namespace"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Job", Name: "sample-gatling-runner"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "FailedCreate",
+		Message:        "exceeded quota: compute-resources",
+		LastTimestamp:  metav1.NewTime(since.Add(time.Minute)),
+	}
+	normalEvent := quotaEvent.DeepCopy()
+	normalEvent.ObjectMeta.Name = "normal-event"
+	normalEvent.Type = corev1.EventTypeNormal
+	normalEvent.Reason = "SuccessfulCreate"
+	oldEvent := quotaEvent.DeepCopy()
+	oldEvent.ObjectMeta.Name = "old-event"
+	oldEvent.LastTimestamp = metav1.NewTime(since.Add(-time.Minute))
+	newFailedEvent := func(name, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "sample-namespace"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "sample-gatling-runner-a"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Failed",
+			Message:        message,
+			LastTimestamp:  metav1.NewTime(since.Add(time.Minute)),
+		}
+	}
+	imageNotFoundEvent := newFailedEvent(
+		"image-not-found-event", `Failed to pull image "gatling:x": rpc error: code = NotFound desc = "gatling:x" not found`,
+	)
+	configMapNotFoundEvent := newFailedEvent("configmap-not-found-event", `Error: configmap "gatling-conf" not found`)
+	imageNotFoundPod := newRunnerPod(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+		Reason: "ImagePullBackOff", Message: `Back-off pulling image "gatling:x": not found`,
+	}})
+	creatingPod := newRunnerPod(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}})
+	startedPod := newRunnerPod(corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+	startedPod.ObjectMeta.Name = "sample-gatling-runner-b"
+	failedJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample-gatling-runner", Namespace: "sample-namespace"},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{
+				Type:    batchv1.JobFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "BackoffLimitExceeded",
+				Message: "Job has reached the specified backoff limit",
+			},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		gatling  gatlingv1alpha1.Gatling
+		objects  []ctrlClient.Object
+		expected startupState
+	}{
+		{
+			name:     "runner job not created yet",
+			gatling:  gatlingv1alpha1.Gatling{ObjectMeta: gatling.ObjectMeta},
+			objects:  nil,
+			expected: startupState{},
+		},
+		{
+			name:     "runner container started",
+			gatling:  gatling,
+			objects:  []ctrlClient.Object{newRunnerPod(corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})},
+			expected: startupState{started: true},
+		},
+		{
+			name:    "pod creation exceeded quota",
+			gatling: gatling,
+			objects: []ctrlClient.Object{quotaEvent, normalEvent, oldEvent},
+			expected: startupState{issues: []startupIssue{
+				{reason: "job sample-gatling-runner event FailedCreate, exceeded quota: compute-resources"},
+			}},
+		},
+		{
+			name:    "runner job failed",
+			gatling: gatling,
+			objects: []ctrlClient.Object{failedJob},
+			expected: startupState{issues: []startupIssue{
+				{
+					reason: "runner job sample-gatling-runner failed, " +
+						"BackoffLimitExceeded Job has reached the specified backoff limit",
+					terminal: true,
+				},
+			}},
+		},
+		{
+			name:    "image not found",
+			gatling: gatling,
+			objects: []ctrlClient.Object{creatingPod, imageNotFoundEvent},
+			expected: startupState{issues: []startupIssue{
+				{
+					reason: "pod sample-gatling-runner-a event Failed, " +
+						`Failed to pull image "gatling:x": rpc error: code = NotFound desc = "gatling:x" not found`,
+					terminal: true,
+				},
+			}},
+		},
+		{
+			name:    "failed event other than image pull",
+			gatling: gatling,
+			objects: []ctrlClient.Object{creatingPod, configMapNotFoundEvent},
+			expected: startupState{issues: []startupIssue{
+				{reason: `pod sample-gatling-runner-a event Failed, Error: configmap "gatling-conf" not found`},
+			}},
+		},
+		{
+			name:    "image not found after runner container started",
+			gatling: gatling,
+			objects: []ctrlClient.Object{imageNotFoundPod, startedPod},
+			expected: startupState{started: true, issues: []startupIssue{
+				{
+					reason: "pod sample-gatling-runner-a container gatling-runner ImagePullBackOff, " +
+						`Back-off pulling image "gatling:x": not found`,
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := eventFieldsClient{WithWatch: kubeutil.InitFakeClient()}
+			for _, obj := range tt.objects {
+				// objects are shared by test cases, so copy them not to create with resourceVersion set.
+				assert.NoError(t, cl.Create(ctx, obj.DeepCopyObject().(ctrlClient.Object)))
+			}
+			state, err := inspectStartup(ctx, cl, tt.gatling, since)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Type values of GatlingStats node.
+const (
+	StatsTypeGroup   = "GROUP"
+	StatsTypeRequest = "REQUEST"
+)
+
+/*
+GatlingStats is a node of gatling report "/js/stats.json", which has stats of a group or a request.
+
+Root node is the group of all requests of the simulation, and Contents has its child groups and requests keyed by
+their formatted path. Path of request in group is joined with " / ", such as "group / request".
+*/
+type GatlingStats struct {
+	Type     string                   `json:"type"`
+	Name     string                   `json:"name"`
+	Path     string                   `json:"path"`
+	Stats    GatlingReport            `json:"stats"`
+	Contents map[string]*GatlingStats `json:"contents,omitempty"`
+}
+
+// BytesToGatlingStats parse jsonBytes of "/js/stats.json" to GatlingStats object.
+func BytesToGatlingStats(jsonBytes []byte) (*GatlingStats, error) {
+	var gatlingStats GatlingStats
+	if err := json.Unmarshal(jsonBytes, &gatlingStats); err != nil {
+		return nil, err
+	}
+	return &gatlingStats, nil
+}
+
+/*
+Flatten returns all groups and requests under the node in order of path, root node itself is not included.
+
+Child of each group follows the group, so the order is same as tree of Gatling report as long as names are sorted.
+*/
+func (s *GatlingStats) Flatten() []*GatlingStats {
+	if s == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(s.Contents))
+	for key := range s.Contents {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.Contents[keys[i]].Path < s.Contents[keys[j]].Path
+	})
+	var nodes []*GatlingStats
+	for _, key := range keys {
+		child := s.Contents[key]
+		nodes = append(nodes, child)
+		nodes = append(nodes, child.Flatten()...)
+	}
+	return nodes
+}
+
+/*
+Find returns group or request under the node of which path or name is name, nil when it is not found.
+
+Path is compared first, so request which has same name in different groups can be specified by path.
+*/
+func (s *GatlingStats) Find(name string) *GatlingStats {
+	nodes := s.Flatten()
+	for _, node := range nodes {
+		if node.Path == name {
+			return node
+		}
+	}
+	for _, node := range nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+/*
+SetPercentiles sets percentile of percentiles1 to percentiles4 to stats of the node and all groups and requests
+under it, same as GatlingReport.Percentiles.
+*/
+func (s *GatlingStats) SetPercentiles(percentiles []float64) {
+	if s == nil {
+		return
+	}
+	s.Stats.Percentiles = percentiles
+	for _, child := range s.Contents {
+		child.SetPercentiles(percentiles)
+	}
+}
+
+/*
+UnmarshalJSON parse stats value which is number or string.
+
+Values of "/js/stats.json" may be quoted, and "-" is used for the value which has no request, such as ko of
+request which never failed. "-" is parsed as 0.
+*/
+func (s *GatlingReportStats) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	for key, field := range map[string]*float64{"total": &s.Total, "ok": &s.Ok, "ko": &s.Ko} {
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+		value, err := parseStatsValue(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse stats %v, %v", key, err)
+		}
+		*field = value
+	}
+	return nil
+}
+
+// parseStatsValue parse raw JSON number or string to float64, "-" and empty string are parsed as 0.
+func parseStatsValue(raw json.RawMessage) (float64, error) {
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, err
+	}
+	if text == "-" || text == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const SampleGatlingStatsPath = "testdata/gatling_report_sample/js/stats.json"
+
+func TestBytesToGatlingStats(t *testing.T) {
+	statsBytes, err := os.ReadFile(SampleGatlingStatsPath)
+	assert.NoError(t, err)
+	gatlingStats, err := BytesToGatlingStats(statsBytes)
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatsTypeGroup, gatlingStats.Type)
+	assert.Equal(t, GatlingReportStats{Total: 10, Ok: 9, Ko: 1}, gatlingStats.Stats.NumberOfRequests)
+	assert.Equal(t, float64(589), gatlingStats.Stats.NintyNinthPercentiles.Ok)
+
+	paths := []string{}
+	for _, node := range gatlingStats.Flatten() {
+		paths = append(paths, node.Path)
+	}
+	assert.Equal(t, []string{"checkout", "checkout / pay", "home"}, paths)
+
+	home := gatlingStats.Find("home")
+	if assert.NotNil(t, home) {
+		assert.Equal(t, StatsTypeRequest, home.Type)
+		// "-" of request which never failed is parsed as 0
+		assert.Equal(t, GatlingReportStats{Total: 4, Ok: 4, Ko: 0}, home.Stats.NumberOfRequests)
+		assert.Equal(t, float64(130), home.Stats.NintyNinthPercentiles.Ok)
+	}
+}
+
+func TestGatlingStatsSetPercentiles(t *testing.T) {
+	statsBytes, err := os.ReadFile(SampleGatlingStatsPath)
+	assert.NoError(t, err)
+	gatlingStats, err := BytesToGatlingStats(statsBytes)
+	assert.NoError(t, err)
+
+	gatlingStats.SetPercentiles([]float64{50, 90, 99, 99.9})
+	latency, err := gatlingStats.Find("checkout / pay").Stats.GetPercentileLatency(99.9)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(590), latency)
+	for _, node := range gatlingStats.Flatten() {
+		assert.Equal(t, []float64{50, 90, 99, 99.9}, node.Stats.Percentiles)
+	}
+
+	var noStats *GatlingStats
+	noStats.SetPercentiles([]float64{50, 90, 99, 99.9})
+}
+
+func TestGatlingStatsFind(t *testing.T) {
+	gatlingStats := &GatlingStats{
+		Type: StatsTypeGroup,
+		Name: "All Requests",
+		Contents: map[string]*GatlingStats{
+			"req_pay": {Type: StatsTypeRequest, Name: "pay", Path: "pay"},
+			"group_checkout": {
+				Type: StatsTypeGroup,
+				Name: "checkout",
+				Path: "checkout",
+				Contents: map[string]*GatlingStats{
+					"req_checkout---pay":     {Type: StatsTypeRequest, Name: "pay", Path: "checkout / pay"},
+					"req_checkout---confirm": {Type: StatsTypeRequest, Name: "confirm", Path: "checkout / confirm"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		input        string
+		expectedPath string
+	}{
+		{
+			name:         "find by path",
+			input:        "checkout / pay",
+			expectedPath: "checkout / pay",
+		},
+		{
+			name:         "path is preferred to name",
+			input:        "pay",
+			expectedPath: "pay",
+		},
+		{
+			name:         "find by name",
+			input:        "confirm",
+			expectedPath: "checkout / confirm",
+		},
+		{
+			name:         "find group",
+			input:        "checkout",
+			expectedPath: "checkout",
+		},
+		{
+			name:         "not found",
+			input:        "home",
+			expectedPath: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := gatlingStats.Find(tt.input)
+			if tt.expectedPath == "" {
+				assert.Nil(t, node)
+				return
+			}
+			if assert.NotNil(t, node) {
+				assert.Equal(t, tt.expectedPath, node.Path)
+			}
+		})
+	}
+}
+
+func TestGatlingReportStatsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    GatlingReportStats
+		expectError bool
+	}{
+		{
+			name:     "numbers",
+			input:    `{"total": 10, "ok": 9.5, "ko": 0}`,
+			expected: GatlingReportStats{Total: 10, Ok: 9.5, Ko: 0},
+		},
+		{
+			name:     "quoted numbers and dash",
+			input:    `{"total": "10", "ok": "10", "ko": "-"}`,
+			expected: GatlingReportStats{Total: 10, Ok: 10, Ko: 0},
+		},
+		{
+			name:        "invalid value",
+			input:       `{"total": "ten", "ok": "10", "ko": "-"}`,
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats GatlingReportStats
+			err := json.Unmarshal([]byte(tt.input), &stats)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, stats)
+		})
+	}
+}
@@ -22,6 +22,147 @@ SOFTWARE.
 
 package gatling
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AbortChecker decides whether running Gatling Job should be aborted.
+type AbortChecker interface {
+	// CheckAbort returns abort reason, or empty string when Gatling Job can keep running.
+	CheckAbort(ctx context.Context) (string, error)
+}
+
+// AbortedError is returned when running Gatling Job is aborted by AbortChecker.
+type AbortedError struct {
+	Reason string
+}
+
+func (e *AbortedError) Error() string {
+	return fmt.Sprintf("gatling job aborted, %v", e.Reason)
+}
+
+// StartupFailedError is returned when Gatling Job can not start by terminal condition, such as bad image reference.
+type StartupFailedError struct {
+	Reason string
+}
+
+func (e *StartupFailedError) Error() string {
+	return fmt.Sprintf("gatling job startup failed, %v", e.Reason)
+}
+
+// Is returns true for ErrRunnerFailed, because runner Job which can not start is failed runner.
+func (e *StartupFailedError) Is(target error) bool {
+	_, ok := target.(*RunnerFailedError)
+	return ok
+}
+
+// Outcome is classified result of Gatling Job.
+type Outcome string
+
+// Outcome values of Gatling Job.
+const (
+	OutcomeSucceeded    Outcome = "succeeded"
+	OutcomeRunnerFailed Outcome = "runner-failed"
+	OutcomeReportFailed Outcome = "report-failed"
+	OutcomeTimedOut     Outcome = "timed-out"
+	OutcomeCancelled    Outcome = "cancelled"
+)
+
+// Errors of each Outcome class, which match any error of the same class with errors.Is.
+var (
+	ErrRunnerFailed error = &RunnerFailedError{}
+	ErrReportFailed error = &ReportFailedError{}
+	ErrTimedOut     error = &TimedOutError{}
+	ErrCancelled    error = &CancelledError{}
+)
+
+// RunnerFailedError is returned when runner Job of Gatling Job failed, such as crash of runner pods.
+type RunnerFailedError struct {
+	Reason string
+}
+
+func (e *RunnerFailedError) Error() string {
+	return fmt.Sprintf("gatling runner failed, %v", e.Reason)
+}
+
+// Is returns whether target is *RunnerFailedError, so that errors.Is(err, ErrRunnerFailed) matches any of them.
+func (e *RunnerFailedError) Is(target error) bool {
+	_, ok := target.(*RunnerFailedError)
+	return ok
+}
+
+// ReportFailedError is returned when reporter Job of Gatling Job failed after runner Job completed.
+type ReportFailedError struct {
+	Reason string
+}
+
+func (e *ReportFailedError) Error() string {
+	return fmt.Sprintf("gatling report failed, %v", e.Reason)
+}
+
+// Is returns whether target is *ReportFailedError, so that errors.Is(err, ErrReportFailed) matches any of them.
+func (e *ReportFailedError) Is(target error) bool {
+	_, ok := target.(*ReportFailedError)
+	return ok
+}
+
+// TimedOutError is returned when Gatling Job did not start or complete in timeout.
+type TimedOutError struct {
+	Reason string
+}
+
+func (e *TimedOutError) Error() string {
+	return fmt.Sprintf("gatling job timed out, %v", e.Reason)
+}
+
+// Is returns whether target is *TimedOutError, so that errors.Is(err, ErrTimedOut) matches any of them.
+func (e *TimedOutError) Is(target error) bool {
+	_, ok := target.(*TimedOutError)
+	return ok
+}
+
+/*
+CancelledError is returned when waiting Gatling Job is cancelled by context.
+
+Cause is error of the context, so errors.Is(err, context.Canceled) matches it too.
+*/
+type CancelledError struct {
+	Cause error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("gatling job cancelled, %v", e.Cause)
+}
+
+// Is returns whether target is *CancelledError, so that errors.Is(err, ErrCancelled) matches any of them.
+func (e *CancelledError) Is(target error) bool {
+	_, ok := target.(*CancelledError)
+	return ok
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Cause
+}
+
+// OutcomeOf returns Outcome class of err returned by Gatling Job, empty when err is not classified.
+func OutcomeOf(err error) Outcome {
+	switch {
+	case err == nil:
+		return OutcomeSucceeded
+	case errors.Is(err, ErrRunnerFailed):
+		return OutcomeRunnerFailed
+	case errors.Is(err, ErrReportFailed):
+		return OutcomeReportFailed
+	case errors.Is(err, ErrTimedOut):
+		return OutcomeTimedOut
+	case errors.Is(err, ErrCancelled):
+		return OutcomeCancelled
+	}
+	return ""
+}
+
 // GatlingReportStats has Gatling Report Stats field.
 type GatlingReportStats struct {
 	Total float64 `json:"total"`
@@ -0,0 +1,240 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultStatusResyncInterval is interval to list Gatling objects again, in case watch missed any change.
+const defaultStatusResyncInterval = 30 * time.Second
+
+/*
+StatusWatcher caches Gatling objects which match labels in namespace, and informs their status change.
+
+One StatusWatcher is shared by loadtests of all services in run, so Gatling objects are watched by one watch
+instead of GET loop of each loadtest. Gatling objects are listed at first and watched from its resource version,
+and listed again every resync interval and when watch is closed or failed, so change missed by watch is caught up.
+Gatling object which is not cached yet is got through the client, so the cache is never required for correctness.
+*/
+type StatusWatcher struct {
+	cl             ctrlClient.WithWatch
+	namespace      string
+	selector       labels.Selector
+	resyncInterval time.Duration
+
+	mu          sync.Mutex
+	gatlings    map[ctrlClient.ObjectKey]*gatlingv1alpha1.Gatling
+	subscribers map[ctrlClient.ObjectKey]map[chan struct{}]bool
+}
+
+// NewStatusWatcher returns StatusWatcher of Gatling objects in namespace which have all of matchingLabels.
+func NewStatusWatcher(cl ctrlClient.WithWatch, namespace string, matchingLabels map[string]string) *StatusWatcher {
+	return &StatusWatcher{
+		cl:             cl,
+		namespace:      namespace,
+		selector:       labels.SelectorFromSet(matchingLabels),
+		resyncInterval: defaultStatusResyncInterval,
+		gatlings:       map[ctrlClient.ObjectKey]*gatlingv1alpha1.Gatling{},
+		subscribers:    map[ctrlClient.ObjectKey]map[chan struct{}]bool{},
+	}
+}
+
+// Start starts to list and watch Gatling objects in background until ctx is done.
+func (w *StatusWatcher) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+/*
+Get sets Gatling object of key to gatling.
+
+Cached object is used when it exists and its UID is uid, otherwise the object is got through the client.
+Gatling object may be deleted and created again with the same name, so cached object of other UID is the old one
+which is not deleted from the cache yet. Any cached object is used when uid is empty.
+*/
+func (w *StatusWatcher) Get(
+	ctx context.Context,
+	key ctrlClient.ObjectKey,
+	uid types.UID,
+	gatling *gatlingv1alpha1.Gatling,
+) error {
+	w.mu.Lock()
+	cached, ok := w.gatlings[key]
+	ok = ok && (uid == "" || cached.ObjectMeta.UID == uid)
+	if ok {
+		cached.DeepCopyInto(gatling)
+	}
+	w.mu.Unlock()
+	if ok {
+		return nil
+	}
+	return w.cl.Get(ctx, key, gatling)
+}
+
+/*
+Subscribe returns channel which receives a value when Gatling object of key is changed, and function to unsubscribe.
+
+Changes are coalesced, so the channel has at most one pending value, and the latest object should be got by Get.
+*/
+func (w *StatusWatcher) Subscribe(key ctrlClient.ObjectKey) (<-chan struct{}, func()) {
+	changedCh := make(chan struct{}, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.subscribers[key] == nil {
+		w.subscribers[key] = map[chan struct{}]bool{}
+	}
+	w.subscribers[key][changedCh] = true
+	return changedCh, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers[key], changedCh)
+		if len(w.subscribers[key]) == 0 {
+			delete(w.subscribers, key)
+		}
+	}
+}
+
+// run repeats list and watch of Gatling objects until ctx is done.
+func (w *StatusWatcher) run(ctx context.Context) {
+	for {
+		resourceVersion, err := w.relist(ctx)
+		if err == nil {
+			err = w.watch(ctx, resourceVersion)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to watch Gatling objects, retry after resync interval, %v\n", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.resyncInterval):
+			}
+		}
+	}
+}
+
+// relist replaces cached Gatling objects with listed ones, and returns resource version of the list.
+func (w *StatusWatcher) relist(ctx context.Context) (string, error) {
+	var gatlingList gatlingv1alpha1.GatlingList
+	if err := w.cl.List(
+		ctx, &gatlingList, &ctrlClient.ListOptions{Namespace: w.namespace, LabelSelector: w.selector},
+	); err != nil {
+		return "", err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changedKeys := map[ctrlClient.ObjectKey]bool{}
+	for key := range w.gatlings {
+		changedKeys[key] = true
+	}
+	w.gatlings = map[ctrlClient.ObjectKey]*gatlingv1alpha1.Gatling{}
+	for i := range gatlingList.Items {
+		key := ctrlClient.ObjectKeyFromObject(&gatlingList.Items[i])
+		w.gatlings[key] = &gatlingList.Items[i]
+		changedKeys[key] = true
+	}
+	for key := range changedKeys {
+		w.notify(key)
+	}
+	return gatlingList.ResourceVersion, nil
+}
+
+/*
+watch applies watch events of Gatling objects to the cache from resourceVersion.
+
+Returns nil when resync interval passed or watch is closed by server, and error when watch failed.
+*/
+func (w *StatusWatcher) watch(ctx context.Context, resourceVersion string) error {
+	watcher, err := w.cl.Watch(ctx, &gatlingv1alpha1.GatlingList{}, &ctrlClient.ListOptions{
+		Namespace:     w.namespace,
+		LabelSelector: w.selector,
+		Raw:           &metav1.ListOptions{ResourceVersion: resourceVersion},
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	resyncTimer := time.NewTimer(w.resyncInterval)
+	defer resyncTimer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resyncTimer.C:
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				return fmt.Errorf("watch error event, %v", event.Object)
+			}
+			if gatling, ok := event.Object.(*gatlingv1alpha1.Gatling); ok {
+				w.apply(event.Type, gatling)
+			}
+		}
+	}
+}
+
+// apply applies watch event of Gatling object to the cache and informs subscribers of the object.
+func (w *StatusWatcher) apply(eventType watch.EventType, gatling *gatlingv1alpha1.Gatling) {
+	// label selector is not applied to watch of some clients, so check it again.
+	if !w.selector.Matches(labels.Set(gatling.ObjectMeta.Labels)) {
+		return
+	}
+	key := ctrlClient.ObjectKeyFromObject(gatling)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch eventType {
+	case watch.Added, watch.Modified:
+		w.gatlings[key] = gatling.DeepCopy()
+	case watch.Deleted:
+		delete(w.gatlings, key)
+	default:
+		return
+	}
+	w.notify(key)
+}
+
+// notify informs subscribers of key without blocking, w.mu must be held by caller.
+func (w *StatusWatcher) notify(key ctrlClient.ObjectKey) {
+	for changedCh := range w.subscribers[key] {
+		select {
+		case changedCh <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package gatling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/watch"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusWatcher(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	runGatling.ObjectMeta.Labels = map[string]string{LabelRunID: "run-a"}
+	otherRunGatling := runGatling.DeepCopy()
+	otherRunGatling.ObjectMeta.Name = "other-run-gatling"
+	otherRunGatling.ObjectMeta.Labels = map[string]string{LabelRunID: "run-b"}
+	assert.NoError(t, cl.Create(ctx, runGatling))
+	assert.NoError(t, cl.Create(ctx, otherRunGatling))
+
+	watcher := NewStatusWatcher(cl, runGatling.ObjectMeta.Namespace, map[string]string{LabelRunID: "run-a"})
+	key := ctrlClient.ObjectKeyFromObject(runGatling)
+	changedCh, unsubscribe := watcher.Subscribe(key)
+	defer unsubscribe()
+	watcher.Start(ctx)
+
+	// listed object is informed
+	select {
+	case <-changedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listed gatling object was not informed")
+	}
+	// wait watch to be started after list
+	time.Sleep(500 * time.Millisecond)
+
+	var foundGatling gatlingv1alpha1.Gatling
+	assert.NoError(t, cl.Get(ctx, key, &foundGatling))
+	foundGatling.Status.RunnerStartTime = int32(time.Now().Unix())
+	assert.NoError(t, cl.Update(ctx, &foundGatling))
+	select {
+	case <-changedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("status change of gatling object was not informed")
+	}
+
+	var cachedGatling gatlingv1alpha1.Gatling
+	assert.NoError(t, watcher.Get(ctx, key, "", &cachedGatling))
+	assert.Equal(t, foundGatling.Status.RunnerStartTime, cachedGatling.Status.RunnerStartTime)
+
+	// object which does not match labels is not cached, but can be got through the client
+	watcher.mu.Lock()
+	_, cached := watcher.gatlings[ctrlClient.ObjectKeyFromObject(otherRunGatling)]
+	watcher.mu.Unlock()
+	assert.False(t, cached)
+	assert.NoError(t, watcher.Get(ctx, ctrlClient.ObjectKeyFromObject(otherRunGatling), "", &cachedGatling))
+	assert.Equal(t, otherRunGatling.ObjectMeta.Name, cachedGatling.ObjectMeta.Name)
+
+	// deleted object is removed from cache
+	assert.NoError(t, cl.Delete(ctx, &foundGatling))
+	select {
+	case <-changedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deletion of gatling object was not informed")
+	}
+	err = watcher.Get(ctx, key, "", &cachedGatling)
+	assert.Error(t, err)
+}
+
+func TestStatusWatcherGet_RecreatedGatling(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	ctx := context.Background()
+
+	// the object of the previous run is still cached, before deletion of it is watched.
+	previousGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	previousGatling.ObjectMeta.UID = "previous-uid"
+	previousGatling.Status = gatlingv1alpha1.GatlingStatus{RunnerCompleted: true, Error: "previous run failed"}
+	watcher := NewStatusWatcher(cl, previousGatling.ObjectMeta.Namespace, nil)
+	watcher.apply(watch.Added, previousGatling)
+
+	recreatedGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	recreatedGatling.ObjectMeta.UID = "recreated-uid"
+	assert.NoError(t, cl.Create(ctx, recreatedGatling))
+	key := ctrlClient.ObjectKeyFromObject(recreatedGatling)
+
+	var foundGatling gatlingv1alpha1.Gatling
+	assert.NoError(t, watcher.Get(ctx, key, recreatedGatling.ObjectMeta.UID, &foundGatling))
+	assert.Equal(t, recreatedGatling.ObjectMeta.UID, foundGatling.ObjectMeta.UID)
+	assert.False(t, foundGatling.Status.RunnerCompleted)
+	assert.Equal(t, "", foundGatling.Status.Error)
+
+	// cached object is used when uid is not specified.
+	assert.NoError(t, watcher.Get(ctx, key, "", &foundGatling))
+	assert.Equal(t, previousGatling.ObjectMeta.UID, foundGatling.ObjectMeta.UID)
+}
+
+func TestWaitGatlingJobRunning_CompletedByWatch(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runningGatling, err := LoadGatlingManifest(SampleGatlingManifestPath)
+	assert.NoError(t, err)
+	runningGatling.Status = gatlingv1alpha1.GatlingStatus{
+		RunnerStartTime: int32(time.Now().Unix()),
+	}
+	assert.NoError(t, cl.Create(ctx, runningGatling))
+
+	watcher := NewStatusWatcher(cl, runningGatling.ObjectMeta.Namespace, nil)
+	watcher.Start(ctx)
+	go func() {
+		time.Sleep(time.Second)
+		var foundGatling gatlingv1alpha1.Gatling
+		if err := cl.Get(ctx, ctrlClient.ObjectKeyFromObject(runningGatling), &foundGatling); err != nil {
+			return
+		}
+		foundGatling.Status.RunnerCompleted = true
+		foundGatling.Status.ReportCompleted = true
+		_ = cl.Update(ctx, &foundGatling)
+	}()
+
+	startedAt := time.Now()
+	informJobFinishCh := make(chan bool, 1)
+	err = WaitGatlingJobRunning(ctx, cl, watcher, runningGatling, 60, informJobFinishCh, nil)
+	assert.NoError(t, err)
+	// completion is informed by watch without waiting 10 seconds check interval
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+}
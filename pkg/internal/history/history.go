@@ -0,0 +1,321 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package history implements local run-history store, which persists each loadtest result as JSON in run directory.
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/external/metricsproviders"
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+)
+
+const (
+	// ConfigSnapshotFileName is file name of config snapshot in run directory.
+	ConfigSnapshotFileName = "config.json"
+	// resultsDirName is directory name in run directory in which Record files are stored.
+	resultsDirName = "results"
+	// logsDirName is directory name in run directory in which logs of Gatling pods are stored.
+	logsDirName = "logs"
+)
+
+// Record has result of one loadtest.
+type Record struct {
+	RunID           string                         `json:"runID"`
+	ServiceName     string                         `json:"serviceName"`
+	ScenarioName    string                         `json:"scenarioName"`
+	ScenarioSubName string                         `json:"scenarioSubName"`
+	ImageURL        string                         `json:"imageURL"`
+	StartedAt       time.Time                      `json:"startedAt"`
+	FinishedAt      time.Time                      `json:"finishedAt"`
+	Gatling         *gatlingv1alpha1.Gatling       `json:"gatling"`
+	GatlingReport   *gatling.GatlingReport         `json:"gatlingReport"`
+	GatlingStats    *gatling.GatlingStats          `json:"gatlingStats,omitempty"` // nil when stats.json is not loaded
+	Container       ContainerMetrics               `json:"container"`              // metrics of the primary target
+	Targets         []TargetMetrics                `json:"targets"`
+	ProviderMetrics []metricsproviders.QueryResult `json:"providerMetrics,omitempty"` // queried over loadtest window
+	Runner          *RunnerMetrics                 `json:"runner,omitempty"`          // nil when saturation is not checked
+	Metrics         baseline.Metrics               `json:"metrics"`
+	Comparison      *baseline.Comparison           `json:"comparison,omitempty"`
+	// SimulationLog has time series parsed from simulation.log, nil when it is not loaded.
+	SimulationLog *gatling.SimulationLogStats `json:"simulationLog,omitempty"`
+	// SLO has result of each SLO assertion, nil when no assertion is set.
+	SLO *slo.Result `json:"slo,omitempty"`
+}
+
+// ContainerMetrics has loadtest target container metrics during loadtest.
+type ContainerMetrics struct {
+	CPUUsageMean       int64   `json:"cpuUsageMean"`    // mCPU
+	MemoryUsageMean    int64   `json:"memoryUsageMean"` // bytes
+	CPUUsagePercent    float64 `json:"cpuUsagePercent"`
+	MemoryUsagePercent float64 `json:"memoryUsagePercent"`
+	// Series has timestamped samples and statistics, nil when no sample was fetched.
+	Series *kubeapi.MetricsSeries `json:"series,omitempty"`
+}
+
+/*
+RunnerMetrics has metrics of Gatling runner pods, the load generator, during loadtest.
+
+Saturated is true when usage of any runner pod exceeded runnerSaturation threshold, and the latency of the loadtest
+may be affected by the load generator.
+*/
+type RunnerMetrics struct {
+	ResourcesLimit    kubeapi.MetricsField   `json:"resourcesLimit"`
+	Saturated         bool                   `json:"saturated"`
+	SaturationReasons []string               `json:"saturationReasons,omitempty"`
+	Series            *kubeapi.MetricsSeries `json:"series,omitempty"`
+}
+
+// LoadGeneratorState returns "saturated" or "ok" by saturation of Gatling runner pods, empty when it is not checked.
+func (r Record) LoadGeneratorState() string {
+	if r.Runner == nil {
+		return ""
+	}
+	if r.Runner.Saturated {
+		return "saturated"
+	}
+	return "ok"
+}
+
+// TargetMetrics has loadtest target container metrics of a target of service during loadtest.
+type TargetMetrics struct {
+	Name string `json:"name"`
+	// Source is where usage of target container was fetched from, metrics-server or type of metrics provider.
+	Source string `json:"source,omitempty"`
+	ContainerMetrics
+	// Health has restarts, OOMKills, evictions and readiness flaps of target pods, nil when it was not watched.
+	Health *kubeapi.PodHealth `json:"health,omitempty"`
+}
+
+/*
+Filter has conditions to filter Records.
+
+Empty field means the condition is not used. From is inclusive and To is exclusive.
+*/
+type Filter struct {
+	RunID        string
+	ServiceName  string
+	ScenarioName string
+	From         time.Time
+	To           time.Time
+}
+
+// WriteRecord writes Record to results directory in runDir, and returns path of written file.
+func WriteRecord(runDir string, record Record) (string, error) {
+	if record.Gatling == nil {
+		return "", fmt.Errorf("record has no gatling object")
+	}
+	recordPath := filepath.Join(runDir, resultsDirName, record.Gatling.ObjectMeta.Name+".json")
+	if err := writeJSON(recordPath, record); err != nil {
+		return "", fmt.Errorf("failed to write history record, %w", err)
+	}
+	return recordPath, nil
+}
+
+/*
+WriteMetricsSamples writes samples of target container metrics of each target as CSV next to Record file,
+and returns its path.
+
+Each row has timestamp in RFC3339, target name, pod name, cpu in mCPU and memory in bytes.
+Target which has no series is skipped.
+*/
+func WriteMetricsSamples(runDir string, gatlingName string, targets []TargetMetrics) (string, error) {
+	samplesPath := filepath.Join(runDir, resultsDirName, gatlingName+".metrics.csv")
+	if err := os.MkdirAll(filepath.Dir(samplesPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write metrics samples, %w", err)
+	}
+	f, err := os.Create(samplesPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to write metrics samples, %w", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"timestamp", "target", "podName", "cpu", "memory"})
+	for _, target := range targets {
+		if target.Series == nil {
+			continue
+		}
+		for _, sample := range target.Series.Samples {
+			_ = w.Write([]string{
+				sample.Timestamp.Format(time.RFC3339),
+				target.Name,
+				sample.PodName,
+				strconv.FormatInt(sample.Cpu, 10),
+				strconv.FormatInt(sample.Memory, 10),
+			})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write metrics samples, %w", err)
+	}
+	return samplesPath, nil
+}
+
+/*
+WriteSimulationLogSeries writes per-second series parsed from simulation.log as CSV next to Record file,
+and returns its path.
+
+Each row has timestamp in RFC3339, requests per second, failed requests, error rate in percentage and latency in ms.
+*/
+func WriteSimulationLogSeries(runDir string, gatlingName string, series []gatling.SimulationLogPoint) (string, error) {
+	seriesPath := filepath.Join(runDir, resultsDirName, gatlingName+".series.csv")
+	if err := os.MkdirAll(filepath.Dir(seriesPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write simulation log series, %w", err)
+	}
+	f, err := os.Create(seriesPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to write simulation log series, %w", err)
+	}
+	defer f.Close()
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{
+		"timestamp", "requests", "failed", "errorRate", "meanLatency", "p50Latency", "p95Latency", "p99Latency",
+		"maxLatency",
+	})
+	for _, point := range series {
+		_ = w.Write([]string{
+			point.Timestamp.Format(time.RFC3339),
+			strconv.FormatInt(point.Requests, 10),
+			strconv.FormatInt(point.Failed, 10),
+			formatFloat(point.ErrorRate),
+			formatFloat(point.MeanLatency),
+			formatFloat(point.P50Latency),
+			formatFloat(point.P95Latency),
+			formatFloat(point.P99Latency),
+			formatFloat(point.MaxLatency),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write simulation log series, %w", err)
+	}
+	return seriesPath, nil
+}
+
+/*
+CreateLogFile creates file in logs directory in runDir to which logs of Gatling pods of gatlingName are written.
+
+Existing file is appended, so that logs of resumed loadtest are kept.
+*/
+func CreateLogFile(runDir string, gatlingName string) (*os.File, error) {
+	logPath := filepath.Join(runDir, logsDirName, gatlingName+".log")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log file, %w", err)
+	}
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file, %w", err)
+	}
+	return f, nil
+}
+
+// WriteConfigSnapshot writes config used in run to runDir.
+func WriteConfigSnapshot(runDir string, config interface{}) error {
+	if err := writeJSON(filepath.Join(runDir, ConfigSnapshotFileName), config); err != nil {
+		return fmt.Errorf("failed to write config snapshot, %w", err)
+	}
+	return nil
+}
+
+// LoadConfigSnapshot reads config snapshot in runDir as raw JSON.
+func LoadConfigSnapshot(runDir string) (json.RawMessage, error) {
+	return os.ReadFile(filepath.Join(runDir, ConfigSnapshotFileName))
+}
+
+/*
+ListRecords reads Records of all runs under runsDir which match filter, and returns them in order of start time.
+
+Record files which can not be parsed are ignored.
+*/
+func ListRecords(runsDir string, filter Filter) ([]Record, error) {
+	recordPaths, err := filepath.Glob(filepath.Join(runsDir, "*", resultsDirName, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(recordPaths))
+	for _, recordPath := range recordPaths {
+		recordBytes, err := os.ReadFile(recordPath)
+		if err != nil {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(recordBytes, &record); err != nil {
+			continue
+		}
+		if filter.Match(record) {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartedAt.Before(records[j].StartedAt)
+	})
+	return records, nil
+}
+
+// Match returns whether record matches all conditions of filter.
+func (f Filter) Match(record Record) bool {
+	if f.RunID != "" && record.RunID != f.RunID {
+		return false
+	}
+	if f.ServiceName != "" && record.ServiceName != f.ServiceName {
+		return false
+	}
+	if f.ScenarioName != "" && record.ScenarioName != f.ScenarioName {
+		return false
+	}
+	if !f.From.IsZero() && record.StartedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.StartedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// writeJSON writes value as indented JSON, and creates parent directory if it doesn't exist.
+func writeJSON(path string, value interface{}) error {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, jsonBytes, 0o644)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeapi"
+
+	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRecord(runID, serviceName, scenarioName string, startedAt time.Time) Record {
+	return Record{
+		RunID:           runID,
+		ServiceName:     serviceName,
+		ScenarioName:    scenarioName,
+		ScenarioSubName: "1",
+		StartedAt:       startedAt,
+		FinishedAt:      startedAt.Add(3 * time.Minute),
+		Gatling: &gatlingv1alpha1.Gatling{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceName + "-" + scenarioName + "1-" + runID},
+		},
+		GatlingReport: &gatling.GatlingReport{
+			NintyNinthPercentiles: gatling.GatlingReportStats{Ok: 100},
+		},
+		Metrics: baseline.Metrics{P99Latency: 100},
+	}
+}
+
+func TestWriteAndListRecords(t *testing.T) {
+	runsDir := t.TempDir()
+	day := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	records := []Record{
+		newRecord("run-2", "sample-service", "sample-scenario", day.AddDate(0, 0, 1)),
+		newRecord("run-1", "sample-service", "sample-scenario", day),
+		newRecord("run-1", "other-service", "sample-scenario", day.Add(time.Minute)),
+		newRecord("run-3", "sample-service", "other-scenario", day.AddDate(0, 0, 2)),
+	}
+	for _, record := range records {
+		_, err := WriteRecord(runsDir+"/"+record.RunID, record)
+		assert.NoError(t, err)
+	}
+	err := WriteConfigSnapshot(runsDir+"/run-1", map[string]string{"runsDir": runsDir})
+	assert.NoError(t, err)
+
+	type recordKey struct {
+		runID       string
+		serviceName string
+	}
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []recordKey
+	}{
+		{
+			name:   "no filter, sorted by start time",
+			filter: Filter{},
+			expected: []recordKey{
+				{"run-1", "sample-service"}, {"run-1", "other-service"}, {"run-2", "sample-service"},
+				{"run-3", "sample-service"},
+			},
+		},
+		{
+			name:     "filter by run ID and service",
+			filter:   Filter{RunID: "run-1", ServiceName: "other-service"},
+			expected: []recordKey{{"run-1", "other-service"}},
+		},
+		{
+			name:     "filter by scenario",
+			filter:   Filter{ScenarioName: "other-scenario"},
+			expected: []recordKey{{"run-3", "sample-service"}},
+		},
+		{
+			name:     "filter by date range",
+			filter:   Filter{From: day.Add(time.Minute), To: day.AddDate(0, 0, 2)},
+			expected: []recordKey{{"run-1", "other-service"}, {"run-2", "sample-service"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := ListRecords(runsDir, tt.filter)
+			assert.NoError(t, err)
+			keys := make([]recordKey, 0, len(found))
+			for _, record := range found {
+				keys = append(keys, recordKey{record.RunID, record.ServiceName})
+			}
+			assert.Equal(t, tt.expected, keys)
+		})
+	}
+
+	snapshot, err := LoadConfigSnapshot(runsDir + "/run-1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"runsDir": "`+runsDir+`"}`, string(snapshot))
+}
+
+func TestWriteRecord_Failed(t *testing.T) {
+	_, err := WriteRecord(t.TempDir(), Record{RunID: "run-1"})
+	assert.Error(t, err)
+}
+
+func TestWriteMetricsSamples(t *testing.T) {
+	runDir := t.TempDir()
+	sampledAt := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	samplesPath, err := WriteMetricsSamples(runDir, "sample-gatling", []TargetMetrics{
+		{
+			Name: "sample-api",
+			ContainerMetrics: ContainerMetrics{Series: &kubeapi.MetricsSeries{Samples: []kubeapi.MetricsSample{
+				{PodName: "sample-pod-0", Timestamp: sampledAt, Cpu: 500, Memory: 1024},
+				{PodName: "sample-pod-1", Timestamp: sampledAt.Add(15 * time.Second), Cpu: 600, Memory: 2048},
+			}}},
+		},
+		{Name: "sample-proxy"}, // no series
+		{
+			Name: "sample-cache",
+			ContainerMetrics: ContainerMetrics{Series: &kubeapi.MetricsSeries{Samples: []kubeapi.MetricsSample{
+				{PodName: "sample-cache-0", Timestamp: sampledAt, Cpu: 100, Memory: 512},
+			}}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(runDir, "results", "sample-gatling.metrics.csv"), samplesPath)
+	content, err := os.ReadFile(samplesPath)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"timestamp,target,podName,cpu,memory\n"+
+			"2023-11-13T10:50:00Z,sample-api,sample-pod-0,500,1024\n"+
+			"2023-11-13T10:50:15Z,sample-api,sample-pod-1,600,2048\n"+
+			"2023-11-13T10:50:00Z,sample-cache,sample-cache-0,100,512\n",
+		string(content),
+	)
+
+	// samples file is not listed as Record
+	records, err := ListRecords(filepath.Dir(runDir), Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestWriteSimulationLogSeries(t *testing.T) {
+	runDir := t.TempDir()
+	startedAt := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	seriesPath, err := WriteSimulationLogSeries(runDir, "sample-gatling", []gatling.SimulationLogPoint{
+		{
+			Timestamp: startedAt, Requests: 2, MeanLatency: 20,
+			P50Latency: 10, P95Latency: 30, P99Latency: 30, MaxLatency: 30,
+		},
+		{Timestamp: startedAt.Add(time.Second), Requests: 4, Failed: 1, ErrorRate: 25, MeanLatency: 12.5},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(runDir, "results", "sample-gatling.series.csv"), seriesPath)
+	content, err := os.ReadFile(seriesPath)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"timestamp,requests,failed,errorRate,meanLatency,p50Latency,p95Latency,p99Latency,maxLatency\n"+
+			"2023-11-13T10:50:00Z,2,0,0,20,10,30,30,30\n"+
+			"2023-11-13T10:50:01Z,4,1,25,12.5,0,0,0,0\n",
+		string(content),
+	)
+}
+
+func TestCreateLogFile(t *testing.T) {
+	runDir := t.TempDir()
+	for _, line := range []string{"first run\n", "resumed run\n"} {
+		f, err := CreateLogFile(runDir, "sample-gatling")
+		assert.NoError(t, err)
+		_, err = f.WriteString(line)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+	content, err := os.ReadFile(filepath.Join(runDir, "logs", "sample-gatling.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first run\nresumed run\n", string(content))
+}
@@ -26,6 +26,7 @@ import (
 	gatlingv1alpha1 "github.com/st-tech/gatling-operator/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	metricsClientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +39,15 @@ InitClient returns client of kubeapi.
 use for operate gatling object.
 */
 func InitClient(k8sCtxName string) (ctrlClient.Client, error) {
+	return InitWatchClient(k8sCtxName)
+}
+
+/*
+InitWatchClient returns client of kubeapi which can watch objects too.
+
+use for watch status of gatling objects.
+*/
+func InitWatchClient(k8sCtxName string) (ctrlClient.WithWatch, error) {
 	// add custom resource gatling to scheme
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -47,7 +57,7 @@ func InitClient(k8sCtxName string) (ctrlClient.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	cl, err := ctrlClient.New(k8sConfig, ctrlClient.Options{
+	cl, err := ctrlClient.NewWithWatch(k8sConfig, ctrlClient.Options{
 		Scheme: scheme,
 	})
 	if err != nil {
@@ -68,3 +78,20 @@ func InitMetricsClient(k8sCtxName string) (*metricsClientset.Clientset, error) {
 	}
 	return cl, nil
 }
+
+/*
+InitClientset returns clientset of kubeapi.
+
+use for reading pod logs, which is not supported by controller-runtime client.
+*/
+func InitClientset(k8sCtxName string) (*kubernetes.Clientset, error) {
+	k8sConfig, err := ctrlConfig.GetConfigWithContext(k8sCtxName)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
@@ -27,63 +27,205 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"gopkg.in/inf.v0"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	metricsClientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	cfg "github.com/st-tech/gatling-commander/pkg/config"
 )
 
+// metricsPollInterval is interval of fetching metrics of target container, to avoid excessive cpu usage.
+var metricsPollInterval = 5 * time.Second
+
 type metricsPool struct {
-	pool []MetricsField
+	pool []MetricsSample
+	// lastTimestamps has timestamp of the last sample of each pod, used for skipping the same sample.
+	lastTimestamps map[string]time.Time
+	// podLimits has the last fetched resources limit of target container of each pod.
+	podLimits  map[string]MetricsField
+	aggregates []AggregateSample
 }
 
 func newMetricsPool() *metricsPool {
-	return &metricsPool{}
+	return &metricsPool{
+		lastTimestamps: map[string]time.Time{},
+		podLimits:      map[string]MetricsField{},
+	}
 }
 
-// calcEachMetricsMean calcurate mean of each MetricsField value.
-func (metricsPool *metricsPool) calcEachMetricsMean() (*MetricsField, error) {
-	// fieldExtractor extract field value by given argument function returns value.
-	mean := func(fieldExtractor func(metrics MetricsField) int64) (int64, error) {
-		if len(metricsPool.pool) <= 0 {
-			return 0, fmt.Errorf("input value length is 0")
+/*
+calcResourceStats returns statistics of a resource of samples.
+
+Percentiles are calculated with nearest-rank method.
+samples must not be empty.
+*/
+func calcResourceStats(samples []MetricsSample, fieldExtractor func(sample MetricsSample) int64) ResourceStats {
+	values := make([]int64, 0, len(samples))
+	peak := samples[0]
+	sum := int64(0)
+	for _, sample := range samples {
+		value := fieldExtractor(sample)
+		values = append(values, value)
+		sum += value
+		if value > fieldExtractor(peak) {
+			peak = sample
 		}
-		sum := int64(0)
-		for _, metrics := range metricsPool.pool {
-			sum += fieldExtractor(metrics)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	percentile := func(p float64) int64 {
+		rank := int(math.Ceil(p / 100 * float64(len(values))))
+		return values[max(rank-1, 0)]
+	}
+	return ResourceStats{
+		Min:         values[0],
+		Max:         values[len(values)-1],
+		Mean:        sum / int64(len(values)),
+		P50:         percentile(50),
+		P95:         percentile(95),
+		P99:         percentile(99),
+		PeakPodName: peak.PodName,
+		PeakAt:      peak.Timestamp,
+	}
+}
+
+/*
+summarize returns samples and statistics of each resource.
+
+Statistics are calculated for samples of all pods and for samples of each pod.
+Aggregate usage percent is total usage of all pods divided by total limits of them through loadtest.
+*/
+func (metricsPool *metricsPool) summarize() (*MetricsSeries, error) {
+	if len(metricsPool.pool) <= 0 {
+		return nil, fmt.Errorf("input value length is 0")
+	}
+	cpu := func(sample MetricsSample) int64 { return sample.Cpu }
+	memory := func(sample MetricsSample) int64 { return sample.Memory }
+	series := &MetricsSeries{
+		Samples:    metricsPool.pool,
+		Cpu:        calcResourceStats(metricsPool.pool, cpu),
+		Memory:     calcResourceStats(metricsPool.pool, memory),
+		Aggregates: metricsPool.aggregates,
+		Replicas:   calcReplicaStats(metricsPool.aggregates),
+	}
+
+	samplesByPod := map[string][]MetricsSample{}
+	var podNames []string
+	for _, sample := range metricsPool.pool {
+		if _, ok := samplesByPod[sample.PodName]; !ok {
+			podNames = append(podNames, sample.PodName)
 		}
-		return sum / int64(len(metricsPool.pool)), nil
+		samplesByPod[sample.PodName] = append(samplesByPod[sample.PodName], sample)
+	}
+	for _, podName := range podNames {
+		podStats := PodMetricsStats{
+			PodName: podName,
+			Cpu:     calcResourceStats(samplesByPod[podName], cpu),
+			Memory:  calcResourceStats(samplesByPod[podName], memory),
+		}
+		if limit, ok := metricsPool.podLimits[podName]; ok {
+			podStats.CPUUsagePercent = usagePercent(podStats.Cpu.Mean, limit.Cpu)
+			podStats.MemoryUsagePercent = usagePercent(podStats.Memory.Mean, limit.Memory)
+		}
+		series.Pods = append(series.Pods, podStats)
 	}
 
-	cpu, err := mean(func(metrics MetricsField) int64 {
-		return metrics.Cpu
-	})
-	if err != nil {
-		return nil, fmt.Errorf("calculate cpu mean error %v", err)
+	var totalUsage, totalLimit MetricsField
+	for _, aggregate := range metricsPool.aggregates {
+		totalUsage.Cpu += aggregate.Cpu
+		totalUsage.Memory += aggregate.Memory
+		totalLimit.Cpu += aggregate.CpuLimit
+		totalLimit.Memory += aggregate.MemoryLimit
 	}
+	series.CPUUsagePercent = usagePercent(totalUsage.Cpu, totalLimit.Cpu)
+	series.MemoryUsagePercent = usagePercent(totalUsage.Memory, totalLimit.Memory)
+	return series, nil
+}
 
-	memory, err := mean(func(metrics MetricsField) int64 {
-		return metrics.Memory
-	})
-	if err != nil {
-		return nil, fmt.Errorf("calculate memory mean error %v", err)
+// usagePercent returns usage percentage of limit, returns 0 when limit is 0.
+func usagePercent(usage, limit int64) float64 {
+	if limit == 0 {
+		return 0
 	}
+	return CalcAndRoundMetricsRatio(usage, limit) * 100
+}
 
-	return &MetricsField{
-		Cpu:    cpu,
-		Memory: memory,
-	}, nil
+/*
+calcReplicaStats returns statistics of replica count of aggregates.
+
+Mean is weighted by time until the next aggregate, so it is not biased by polling interval.
+*/
+func calcReplicaStats(aggregates []AggregateSample) ReplicaStats {
+	if len(aggregates) == 0 {
+		return ReplicaStats{}
+	}
+	stats := ReplicaStats{
+		Min:              aggregates[0].Replicas,
+		Max:              aggregates[0].Replicas,
+		TimeWeightedMean: float64(aggregates[0].Replicas),
+	}
+	var weightedSum float64
+	for i, aggregate := range aggregates {
+		stats.Min = min(stats.Min, aggregate.Replicas)
+		stats.Max = max(stats.Max, aggregate.Replicas)
+		if i+1 < len(aggregates) {
+			weightedSum += float64(aggregate.Replicas) * aggregates[i+1].Timestamp.Sub(aggregate.Timestamp).Seconds()
+		}
+	}
+	if duration := aggregates[len(aggregates)-1].Timestamp.Sub(aggregates[0].Timestamp).Seconds(); duration > 0 {
+		stats.TimeWeightedMean = math.Round(weightedSum/duration*100) / 100
+	}
+	return stats
+}
+
+/*
+append adds sample to pool.
+
+metrics-server updates metrics at its own interval, so sample which has the same timestamp as the last sample
+of the pod is skipped not to weight it by polling count.
+*/
+func (metricsPool *metricsPool) append(sample MetricsSample) {
+	if last, ok := metricsPool.lastTimestamps[sample.PodName]; ok && last.Equal(sample.Timestamp) {
+		return
+	}
+	metricsPool.lastTimestamps[sample.PodName] = sample.Timestamp
+	metricsPool.pool = append(metricsPool.pool, sample)
 }
 
-func (metricsPool *metricsPool) append(metrics MetricsField) {
-	metricsPool.pool = append(metricsPool.pool, metrics)
+/*
+appendPoll adds samples and resources limit of each pod fetched at a poll.
+
+Replica count is the number of Running pods, and total usage and limits are of pods which have sample.
+*/
+func (metricsPool *metricsPool) appendPoll(
+	polledAt time.Time,
+	podLimits map[string]MetricsField,
+	samples []MetricsSample,
+) {
+	aggregate := AggregateSample{
+		Timestamp: polledAt,
+		Replicas:  len(podLimits),
+	}
+	for podName, limit := range podLimits {
+		metricsPool.podLimits[podName] = limit
+	}
+	for _, sample := range samples {
+		metricsPool.append(sample)
+		limit, ok := podLimits[sample.PodName]
+		if !ok {
+			continue
+		}
+		aggregate.Cpu += sample.Cpu
+		aggregate.Memory += sample.Memory
+		aggregate.CpuLimit += limit.Cpu
+		aggregate.MemoryLimit += limit.Memory
+	}
+	metricsPool.aggregates = append(metricsPool.aggregates, aggregate)
 }
 
 // FetchContainerResourcesLimit fetch specified container and get resources limits or requests field value.
@@ -93,18 +235,19 @@ func FetchContainerResourcesLimit(
 	podConfig cfg.TargetPodConfig,
 ) (*MetricsField, error) {
 	namespace := podConfig.Namespace
-	labelKey := podConfig.LabelKey
-	labelValue := podConfig.LabelValue
 	targetContainerName := podConfig.ContainerName
 
 	var foundPods corev1.PodList
-	labelSelector := labels.SelectorFromSet(labels.Set{labelKey: labelValue})
+	labelSelector, err := podConfig.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target pod label selector, %v", err)
+	}
 	listOptions := &ctrlClient.ListOptions{
 		Namespace:     namespace,
 		LabelSelector: labelSelector,
 	}
 
-	err := cl.List(ctx, &foundPods, listOptions)
+	err = cl.List(ctx, &foundPods, listOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list target pod, %v", err)
 	}
@@ -137,32 +280,99 @@ func FetchContainerResourcesLimit(
 }
 
 /*
-FetchContainerMetricsMean returns container resources value mean.
+FetchContainerResourcesLimits returns resources limits of target container of each Running pod.
+
+Key of returned map is pod name. Limits of each pod are got in the same way as FetchContainerResourcesLimit.
+*/
+func FetchContainerResourcesLimits(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	podConfig cfg.TargetPodConfig,
+) (map[string]MetricsField, error) {
+	var foundPods corev1.PodList
+	labelSelector, err := podConfig.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target pod label selector, %v", err)
+	}
+	listOptions := &ctrlClient.ListOptions{
+		Namespace:     podConfig.Namespace,
+		LabelSelector: labelSelector,
+	}
+	if err := cl.List(ctx, &foundPods, listOptions); err != nil {
+		return nil, fmt.Errorf("failed to list target pod, %v", err)
+	}
+	limits := map[string]MetricsField{}
+	for _, pod := range foundPods.Items {
+		if pod.Status.Phase != "Running" {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if c.Name != podConfig.ContainerName {
+				continue
+			}
+			limit, err := getContainerResourcesLimits(c.Resources)
+			if err != nil {
+				return nil, fmt.Errorf("pod %v, %v", pod.ObjectMeta.Name, err)
+			}
+			limits[pod.ObjectMeta.Name] = *limit
+		}
+	}
+	return limits, nil
+}
+
+/*
+MetricsSource fetches current resources usage of target container of each pod.
 
-Fetch metrics value every 5 seconds until informerCh get value or context done.
+MetricsServerSource fetches it from metrics-server, and other metrics backends, such as Prometheus, implement it to
+be used for target container metrics instead.
+*/
+type MetricsSource interface {
+	// FetchContainerMetrics returns current resources usage sample of target container of each pod.
+	FetchContainerMetrics(ctx context.Context, podConfig cfg.TargetPodConfig) ([]MetricsSample, error)
+}
+
+// MetricsServerSource implements MetricsSource with PodMetrics of metrics-server.
+type MetricsServerSource struct {
+	Client metricsClientset.Interface
+}
+
+// FetchContainerMetrics returns samples of pods which match label selector of podConfig from metrics-server.
+func (s MetricsServerSource) FetchContainerMetrics(
+	ctx context.Context,
+	podConfig cfg.TargetPodConfig,
+) ([]MetricsSample, error) {
+	return FetchContainerMetrics(ctx, s.Client, podConfig)
+}
+
+/*
+FetchContainerMetricsSeries returns timestamped samples of container resources value and statistics of them.
+
+Fetch metrics value from source every 5 seconds until informerCh get value or context done.
+Running pods and their resources limits are also fetched every time, to track replica count and aggregate usage
+when the number of pods changes during loadtest, e.g. by HPA.
 Cpu and Memory value is rounded and cast from *inf.Dec to int64.
 If error occured this error only log error and continue to run. (not returns error object)
 */
-func FetchContainerMetricsMean(
+func FetchContainerMetricsSeries(
 	ctx context.Context,
 	wg *sync.WaitGroup,
-	cl metricsClientset.Interface,
-	resultCh chan MetricsField,
+	podCl ctrlClient.Client,
+	source MetricsSource,
+	resultCh chan MetricsSeries,
 	receiveGatlingFinishedCh chan bool,
 	podConfig cfg.TargetPodConfig,
 ) {
 	defer wg.Done()
 
 	namespace := podConfig.Namespace
-	labelKey := podConfig.LabelKey
-	labelValue := podConfig.LabelValue
-	targetContainerName := podConfig.ContainerName
+	labelSelector, _ := podConfig.Selector() // selector is validated with config, so error is not expected here.
 
 	log := func(msg string, isErr bool) {
 		logCommonPodInfo := fmt.Sprintf(
-			"Namespace: %v, Label: %v",
+			"Target: %v, Namespace: %v, Label: %v",
+			podConfig.Name,
 			namespace,
-			fmt.Sprintf("%v=%v", labelKey, labelValue),
+			labelSelector,
 		)
 		if isErr {
 			fmt.Fprintf(os.Stderr, "Error: %v, %v\n", msg, logCommonPodInfo)
@@ -178,53 +388,93 @@ func FetchContainerMetricsMean(
 			return
 		case <-receiveGatlingFinishedCh:
 			log("receive gatling job finished", false)
-			meanMetrics, err := metricsPool.calcEachMetricsMean()
+			series, err := metricsPool.summarize()
 			if err != nil {
 				log(err.Error(), true)
 				return
 			}
-			resultCh <- *meanMetrics
+			resultCh <- *series
 			return
 		default:
-			listOptions := metav1.ListOptions{
-				LabelSelector: fmt.Sprintf("%v=%v", labelKey, labelValue),
+			polledAt := time.Now()
+			if samples, err := source.FetchContainerMetrics(ctx, podConfig); err != nil {
+				log(err.Error(), true)
+			} else if podLimits, err := FetchContainerResourcesLimits(ctx, podCl, podConfig); err != nil {
+				// samples are still recorded, only replica count and aggregate usage of this poll are lost.
+				log(err.Error(), true)
+				for _, sample := range samples {
+					metricsPool.append(sample)
+				}
+			} else {
+				metricsPool.appendPoll(polledAt, podLimits, samples)
 			}
-			metricses, err := cl.MetricsV1beta1().PodMetricses(namespace).List(ctx, listOptions)
-			if err != nil {
-				log(fmt.Sprintf("failed to get pod metricses list %v", err), true)
+			// wait on failed poll too, otherwise failed polls are retried in a busy loop.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(metricsPollInterval):
+			}
+		}
+	}
+}
+
+/*
+FetchContainerMetrics returns current resources usage sample of target container of each pod.
+
+Cpu value unit is mCPU and Memory value unit is bytes.
+Timestamp is the time metrics-server collected the metrics, or now when it is not set.
+*/
+func FetchContainerMetrics(
+	ctx context.Context,
+	cl metricsClientset.Interface,
+	podConfig cfg.TargetPodConfig,
+) ([]MetricsSample, error) {
+	labelSelector, err := podConfig.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target pod label selector, %v", err)
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	}
+	podMetricses, err := cl.MetricsV1beta1().PodMetricses(podConfig.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metricses list %v", err)
+	}
+	var samples []MetricsSample
+	for _, podMetrics := range podMetricses.Items {
+		timestamp := podMetrics.Timestamp.Time
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		for _, c := range podMetrics.Containers {
+			if c.Name != podConfig.ContainerName {
 				continue
 			}
-			for _, podMetrics := range metricses.Items {
-				for _, c := range podMetrics.Containers {
-					if c.Name == targetContainerName {
-						// The unit of CPU value that the container object has is cores.
-						// ref: https://github.com/kubernetes/api/blob/5e9982075c8d828d9501e306ed0a2e133f1ebd88/core/v1/types.go#L5704
-						// CPU, in cores. (500 mCPU = .5 vCPU)
-						cpuUsageDec := c.Usage.Cpu().AsDec()
-						// change unit to mCPU and convert *inf.Dec to int64
-						// The Round method returns a 3-digit reounded up value which has type inf.Dec.
-						// The inf.Dec object Unscaled method returns unscaled property value which has int64 type.
-						// nolint:lll // ex: cpuUsageDec: inf.Dec{unscale: 5005, scale: 4} (equal to float64 0.5005), rounded up with scale 3 result: inf.Dec{unscale: 501, scale: 3}, Unscaled result: int64 501
-						// So if get 0.5005 vCPU it convert 501 mCPU
-						cpuUsage, ok := cpuUsageDec.Round(cpuUsageDec, 3, inf.RoundUp).Unscaled()
-						if !ok {
-							log("failed to convert value of container metrics cpu usage *inf.Dec to int64", true)
-						}
-						// The unit of Memory value that the container object has is bytes.
-						// ref: https://github.com/kubernetes/api/blob/5e9982075c8d828d9501e306ed0a2e133f1ebd88/core/v1/types.go#L5704
-						memUsage, _ := c.Usage.Memory().AsInt64()
-						metricsPool.append(
-							MetricsField{
-								Cpu:    cpuUsage,
-								Memory: memUsage,
-							},
-						)
-					}
-				}
+			// The unit of CPU value that the container object has is cores.
+			// ref: https://github.com/kubernetes/api/blob/5e9982075c8d828d9501e306ed0a2e133f1ebd88/core/v1/types.go#L5704
+			// CPU, in cores. (500 mCPU = .5 vCPU)
+			cpuUsageDec := c.Usage.Cpu().AsDec()
+			// change unit to mCPU and convert *inf.Dec to int64
+			// The Round method returns a 3-digit reounded up value which has type inf.Dec.
+			// The inf.Dec object Unscaled method returns unscaled property value which has int64 type.
+			// nolint:lll // ex: cpuUsageDec: inf.Dec{unscale: 5005, scale: 4} (equal to float64 0.5005), rounded up with scale 3 result: inf.Dec{unscale: 501, scale: 3}, Unscaled result: int64 501
+			// So if get 0.5005 vCPU it convert 501 mCPU
+			cpuUsage, ok := cpuUsageDec.Round(cpuUsageDec, 3, inf.RoundUp).Unscaled()
+			if !ok {
+				return nil, fmt.Errorf("failed to convert value of container metrics cpu usage *inf.Dec to int64")
 			}
-			time.Sleep(5 * time.Second) // NOTE: wait few seconds to avoid excessive cpu usage.
+			// The unit of Memory value that the container object has is bytes.
+			// ref: https://github.com/kubernetes/api/blob/5e9982075c8d828d9501e306ed0a2e133f1ebd88/core/v1/types.go#L5704
+			memUsage, _ := c.Usage.Memory().AsInt64()
+			samples = append(samples, MetricsSample{
+				PodName:   podMetrics.ObjectMeta.Name,
+				Timestamp: timestamp,
+				Cpu:       cpuUsage,
+				Memory:    memUsage,
+			})
 		}
 	}
+	return samples, nil
 }
 
 // CalcAndRoundMetricsRatio calculate ratio by resource usage and limit.
@@ -234,6 +484,42 @@ func CalcAndRoundMetricsRatio(usage, limit int64) float64 {
 	return roundedRatio
 }
 
+/*
+CheckSaturation returns reasons of saturation of target container of pods in series, empty when not saturated.
+
+Peak usage of each pod is compared with limit, and the resource which threshold is 0 is not checked.
+*/
+func CheckSaturation(series MetricsSeries, limit MetricsField, cpuPercent, memoryPercent float64) []string {
+	var reasons []string
+	for _, pod := range series.Pods {
+		usages := []struct {
+			name      string
+			threshold float64
+			usage     int64
+			limit     int64
+		}{
+			{name: "cpu", threshold: cpuPercent, usage: pod.Cpu.Max, limit: limit.Cpu},
+			{name: "memory", threshold: memoryPercent, usage: pod.Memory.Max, limit: limit.Memory},
+		}
+		for _, u := range usages {
+			if u.threshold == 0 || u.limit == 0 {
+				continue
+			}
+			if percent := usagePercent(u.usage, u.limit); percent > u.threshold {
+				reasons = append(reasons, fmt.Sprintf(
+					"pod %v %v usage %.1f%% exceeded %v%%", pod.PodName, u.name, percent, u.threshold,
+				))
+			}
+		}
+	}
+	return reasons
+}
+
+// ContainerResourcesLimits returns resources limits of container, or requests when limits are not set.
+func ContainerResourcesLimits(resources corev1.ResourceRequirements) (*MetricsField, error) {
+	return getContainerResourcesLimits(resources)
+}
+
 /*
 getContainerResourcesLimits returns container resources limits field value.
 If the limits field is not present, get requests field value.
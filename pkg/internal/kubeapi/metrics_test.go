@@ -26,6 +26,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -137,6 +138,18 @@ func TestFetchContainerResourcesLimit(t *testing.T) {
 			name:      "success to fetched resources requests value",
 			podConfig: samplePodOnlyResourcesRequestsConfig,
 		},
+		{
+			name: "success to fetched resources limits value of pod selected by matchExpressions",
+			podConfig: cfg.TargetPodConfig{
+				Namespace: samplePod.ObjectMeta.Namespace,
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: labelKey, Operator: metav1.LabelSelectorOpIn, Values: []string{"sample-app", "other-app"}},
+					},
+				},
+				ContainerName: samplePod.Spec.Containers[0].Name,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -566,7 +579,7 @@ func TestGetContainerResourcesLimits_Failed(t *testing.T) {
 	}
 }
 
-func TestFetchTargetPodMetricsMean(t *testing.T) {
+func TestFetchContainerMetricsSeries(t *testing.T) {
 	ctx := context.TODO()
 
 	const (
@@ -610,6 +623,27 @@ func TestFetchTargetPodMetricsMean(t *testing.T) {
 		ContainerName: podMetrics.Containers[0].Name,
 	}
 
+	// pod of which limits are double of usage
+	podCl := kubeutil.InitFakeClient()
+	err := podCl.Create(ctx, &corev1.Pod{
+		ObjectMeta: podMetrics.ObjectMeta,
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: podMetrics.Containers[0].Name,
+					Resources: corev1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%vm", podCpu*2)),
+							v1.ResourceMemory: resource.MustParse(fmt.Sprintf("%v", podMem*2)),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: "Running"},
+	})
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name      string
 		podConfig cfg.TargetPodConfig
@@ -630,8 +664,10 @@ func TestFetchTargetPodMetricsMean(t *testing.T) {
 			var wg sync.WaitGroup
 			wg.Add(1)
 			informJobFinishCh := make(chan bool)
-			resultCh := make(chan MetricsField, 1)
-			go FetchContainerMetricsMean(ctx, &wg, cl, resultCh, informJobFinishCh, tt.podConfig)
+			resultCh := make(chan MetricsSeries, 1)
+			go FetchContainerMetricsSeries(
+				ctx, &wg, podCl, MetricsServerSource{Client: cl}, resultCh, informJobFinishCh, tt.podConfig,
+			)
 
 			mockWaitGatlingJobRunning := func(jobFinishCh chan bool) {
 				defer func() { jobFinishCh <- true }()
@@ -645,13 +681,18 @@ func TestFetchTargetPodMetricsMean(t *testing.T) {
 			close(resultCh)
 
 			metricsResult := <-resultCh
-			assert.Equal(t, tt.expected.Cpu, metricsResult.Cpu)
-			assert.Equal(t, tt.expected.Memory, metricsResult.Memory)
+			assert.Equal(t, tt.expected.Cpu, metricsResult.Cpu.Mean)
+			assert.Equal(t, tt.expected.Memory, metricsResult.Memory.Mean)
+			assert.Equal(t, 50.0, metricsResult.CPUUsagePercent)
+			assert.Equal(t, 50.0, metricsResult.MemoryUsagePercent)
+			assert.Equal(t, 1, metricsResult.Replicas.Max)
+			assert.Equal(t, 1, len(metricsResult.Pods))
+			assert.Equal(t, 50.0, metricsResult.Pods[0].CPUUsagePercent)
 		})
 	}
 }
 
-func TestFetchTargetPodMetricsMean_Failed(t *testing.T) {
+func TestFetchContainerMetricsSeries_Failed(t *testing.T) {
 	ctx := context.TODO()
 
 	const (
@@ -682,7 +723,7 @@ func TestFetchTargetPodMetricsMean_Failed(t *testing.T) {
 	cl := metricsFake.NewSimpleClientset(podMetrics)
 	_ = cl.Tracker().Create(gvr, podMetrics, podMetrics.ObjectMeta.Namespace)
 
-	resultCh := make(chan MetricsField, 1)
+	resultCh := make(chan MetricsSeries, 1)
 	informerCh := make(chan bool)
 
 	var targetLabelKey, targetLabelVal string
@@ -699,7 +740,9 @@ func TestFetchTargetPodMetricsMean_Failed(t *testing.T) {
 
 	var wg sync.WaitGroup
 	wg.Add(1)
-	go FetchContainerMetricsMean(ctx, &wg, cl, resultCh, informerCh, targetPodConfig)
+	go FetchContainerMetricsSeries(
+		ctx, &wg, kubeutil.InitFakeClient(), MetricsServerSource{Client: cl}, resultCh, informerCh, targetPodConfig,
+	)
 
 	mockWaitGatlingJobRunning := func(ch chan bool) {
 		defer func() { ch <- true }()
@@ -714,8 +757,57 @@ func TestFetchTargetPodMetricsMean_Failed(t *testing.T) {
 
 	metricsResult, ok := <-resultCh // resultCh has no metric value so return 0 value
 	assert.Equal(t, ok, false)
-	assert.Equal(t, int64(0), metricsResult.Cpu)
-	assert.Equal(t, int64(0), metricsResult.Memory)
+	assert.Equal(t, int64(0), metricsResult.Cpu.Mean)
+	assert.Equal(t, int64(0), metricsResult.Memory.Mean)
+}
+
+// failingMetricsSource is MetricsSource which always fails and counts how many times it is polled.
+type failingMetricsSource struct {
+	polled atomic.Int32
+}
+
+func (s *failingMetricsSource) FetchContainerMetrics(context.Context, cfg.TargetPodConfig) ([]MetricsSample, error) {
+	s.polled.Add(1)
+	return nil, fmt.Errorf("metrics server is unavailable")
+}
+
+func TestFetchContainerMetricsSeries_FailedPollWaits(t *testing.T) {
+	defaultInterval := metricsPollInterval
+	metricsPollInterval = 100 * time.Millisecond
+	defer func() { metricsPollInterval = defaultInterval }()
+
+	source := &failingMetricsSource{}
+	resultCh := make(chan MetricsSeries, 1)
+	informerCh := make(chan bool)
+	targetPodConfig := cfg.TargetPodConfig{
+		Namespace:     "sample-namespace",
+		LabelKey:      "app",
+		LabelValue:    "sample-app",
+		ContainerName: "sample-container",
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go FetchContainerMetricsSeries(
+		context.TODO(), &wg, kubeutil.InitFakeClient(), source, resultCh, informerCh, targetPodConfig,
+	)
+	time.Sleep(550 * time.Millisecond)
+	close(informerCh)
+	wg.Wait()
+
+	// failed polls are retried every metricsPollInterval, not in a busy loop.
+	polled := source.polled.Load()
+	assert.GreaterOrEqual(t, polled, int32(2))
+	assert.LessOrEqual(t, polled, int32(7))
+
+	// polling stops as soon as context is cancelled.
+	ctx, cancel := context.WithCancel(context.TODO())
+	wg.Add(1)
+	go FetchContainerMetricsSeries(
+		ctx, &wg, kubeutil.InitFakeClient(), source, resultCh, make(chan bool), targetPodConfig,
+	)
+	cancel()
+	wg.Wait()
 }
 
 func TestCalcAndRoundMetricsRatio(t *testing.T) {
@@ -744,3 +836,145 @@ func TestCalcAndRoundMetricsRatio(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSaturation(t *testing.T) {
+	limit := MetricsField{Cpu: 1000, Memory: 2000}
+	series := MetricsSeries{
+		Pods: []PodMetricsStats{
+			{PodName: "runner-a", Cpu: ResourceStats{Max: 950}, Memory: ResourceStats{Max: 1000}},
+			{PodName: "runner-b", Cpu: ResourceStats{Max: 500}, Memory: ResourceStats{Max: 1900}},
+		},
+	}
+	tests := []struct {
+		name          string
+		cpuPercent    float64
+		memoryPercent float64
+		expected      []string
+	}{
+		{
+			name:          "cpu and memory saturated",
+			cpuPercent:    90,
+			memoryPercent: 90,
+			expected: []string{
+				"pod runner-a cpu usage 95.0% exceeded 90%",
+				"pod runner-b memory usage 95.0% exceeded 90%",
+			},
+		},
+		{
+			name:          "memory is not checked",
+			cpuPercent:    90,
+			memoryPercent: 0,
+			expected:      []string{"pod runner-a cpu usage 95.0% exceeded 90%"},
+		},
+		{
+			name:          "not saturated",
+			cpuPercent:    99,
+			memoryPercent: 99,
+			expected:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CheckSaturation(series, limit, tt.cpuPercent, tt.memoryPercent))
+		})
+	}
+}
+
+func TestMetricsPoolSummarize(t *testing.T) {
+	startedAt := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	pool := newMetricsPool()
+	// cpu 100, 200, ..., 1000 mCPU and memory 1000, 900, ..., 100 bytes, sampled every 15 seconds.
+	for i := int64(1); i <= 10; i++ {
+		pool.append(MetricsSample{
+			PodName:   fmt.Sprintf("sample-pod-%v", i%2),
+			Timestamp: startedAt.Add(time.Duration(i) * 15 * time.Second),
+			Cpu:       i * 100,
+			Memory:    (11 - i) * 100,
+		})
+	}
+	// sample which has the same timestamp as the last sample of the pod is skipped
+	pool.append(MetricsSample{
+		PodName:   "sample-pod-0",
+		Timestamp: startedAt.Add(150 * time.Second),
+		Cpu:       5000,
+		Memory:    5000,
+	})
+
+	series, err := pool.summarize()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(series.Samples))
+	assert.Equal(t, ResourceStats{
+		Min:         100,
+		Max:         1000,
+		Mean:        550,
+		P50:         500,
+		P95:         1000,
+		P99:         1000,
+		PeakPodName: "sample-pod-0",
+		PeakAt:      startedAt.Add(150 * time.Second),
+	}, series.Cpu)
+	assert.Equal(t, ResourceStats{
+		Min:         100,
+		Max:         1000,
+		Mean:        550,
+		P50:         500,
+		P95:         1000,
+		P99:         1000,
+		PeakPodName: "sample-pod-1",
+		PeakAt:      startedAt.Add(15 * time.Second),
+	}, series.Memory)
+	assert.Equal(t, MetricsField{Cpu: 550, Memory: 550}, series.Mean())
+
+	_, err = newMetricsPool().summarize()
+	assert.EqualError(t, err, "input value length is 0")
+}
+
+func TestMetricsPoolAppendPoll(t *testing.T) {
+	startedAt := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	limit := MetricsField{Cpu: 1000, Memory: 1000}
+	pool := newMetricsPool()
+	// scaled out from 1 to 3 replicas after 60 seconds, and keep 3 replicas for 30 seconds.
+	pool.appendPoll(startedAt, map[string]MetricsField{"pod-a": limit}, []MetricsSample{
+		{PodName: "pod-a", Timestamp: startedAt, Cpu: 900, Memory: 500},
+	})
+	pool.appendPoll(
+		startedAt.Add(60*time.Second),
+		map[string]MetricsField{"pod-a": limit, "pod-b": limit, "pod-c": limit},
+		[]MetricsSample{
+			{PodName: "pod-a", Timestamp: startedAt.Add(60 * time.Second), Cpu: 300, Memory: 500},
+			{PodName: "pod-b", Timestamp: startedAt.Add(60 * time.Second), Cpu: 300, Memory: 500},
+			// pod-c has no metrics yet, so it is not counted in aggregate usage
+		},
+	)
+	pool.appendPoll(
+		startedAt.Add(90*time.Second),
+		map[string]MetricsField{"pod-a": limit, "pod-b": limit, "pod-c": limit},
+		[]MetricsSample{
+			{PodName: "pod-a", Timestamp: startedAt.Add(90 * time.Second), Cpu: 300, Memory: 500},
+			{PodName: "pod-b", Timestamp: startedAt.Add(90 * time.Second), Cpu: 300, Memory: 500},
+			{PodName: "pod-c", Timestamp: startedAt.Add(90 * time.Second), Cpu: 300, Memory: 500},
+		},
+	)
+
+	series, err := pool.summarize()
+	assert.NoError(t, err)
+	assert.Equal(t, ReplicaStats{Min: 1, Max: 3, TimeWeightedMean: 1.67}, series.Replicas)
+	assert.Equal(t, 3, len(series.Aggregates))
+	assert.Equal(t, AggregateSample{
+		Timestamp:   startedAt.Add(60 * time.Second),
+		Replicas:    3,
+		Cpu:         600,
+		Memory:      1000,
+		CpuLimit:    2000,
+		MemoryLimit: 2000,
+	}, series.Aggregates[1])
+	// total usage 2400 mCPU of total limits 6000 mCPU
+	assert.Equal(t, 40.0, series.CPUUsagePercent)
+	assert.Equal(t, 50.0, series.MemoryUsagePercent)
+	assert.Equal(t, []string{"pod-a", "pod-b", "pod-c"}, []string{
+		series.Pods[0].PodName, series.Pods[1].PodName, series.Pods[2].PodName,
+	})
+	// pod-a mean cpu usage 500 mCPU of limit 1000 mCPU
+	assert.Equal(t, 50.0, series.Pods[0].CPUUsagePercent)
+	assert.Equal(t, 30.0, series.Pods[1].CPUUsagePercent)
+}
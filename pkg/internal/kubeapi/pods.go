@@ -0,0 +1,276 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrlClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+)
+
+// FetchContainerStatuses returns restart status of target container of each pod selected by podConfig.
+func FetchContainerStatuses(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	podConfig cfg.TargetPodConfig,
+) ([]ContainerStatus, error) {
+	var foundPods corev1.PodList
+	labelSelector, err := podConfig.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target pod label selector, %v", err)
+	}
+	listOptions := &ctrlClient.ListOptions{
+		Namespace:     podConfig.Namespace,
+		LabelSelector: labelSelector,
+	}
+	if err := cl.List(ctx, &foundPods, listOptions); err != nil {
+		return nil, fmt.Errorf("failed to list target pod, %v", err)
+	}
+	var statuses []ContainerStatus
+	for _, pod := range foundPods.Items {
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name != podConfig.ContainerName {
+				continue
+			}
+			status := ContainerStatus{
+				PodName:      pod.ObjectMeta.Name,
+				RestartCount: c.RestartCount,
+			}
+			if terminated := c.LastTerminationState.Terminated; terminated != nil {
+				status.LastTerminationReason = terminated.Reason
+				status.LastTerminatedAt = terminated.FinishedAt.Time
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// FetchRunningPodNames returns names of Running pods selected by podConfig in ascending order.
+func FetchRunningPodNames(ctx context.Context, cl ctrlClient.Client, podConfig cfg.TargetPodConfig) ([]string, error) {
+	var foundPods corev1.PodList
+	labelSelector, err := podConfig.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target pod label selector, %v", err)
+	}
+	listOptions := &ctrlClient.ListOptions{
+		Namespace:     podConfig.Namespace,
+		LabelSelector: labelSelector,
+	}
+	if err := cl.List(ctx, &foundPods, listOptions); err != nil {
+		return nil, fmt.Errorf("failed to list target pod, %v", err)
+	}
+	var podNames []string
+	for _, pod := range foundPods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podNames = append(podNames, pod.ObjectMeta.Name)
+	}
+	sort.Strings(podNames)
+	return podNames, nil
+}
+
+/*
+healthTracker counts changes of target pods between polls.
+
+Restarts and evictions of pods found by the first poll are counted from the first poll, except pods created after
+since. Pods found by following polls are regarded as created during loadtest.
+*/
+type healthTracker struct {
+	since         time.Time
+	containerName string
+	polled        bool                 // true after the first poll
+	observed      map[string]bool      // pod name which was observed at least once
+	restarts      map[string]int32     // last restart count of target container by pod name
+	terminatedAt  map[string]time.Time // last counted OOMKilled termination by pod name
+	ready         map[string]bool      // last Ready condition by pod name
+	evicted       map[string]bool      // evicted pod name, including the one evicted before the first poll
+	health        PodHealth
+}
+
+func newHealthTracker(since time.Time, containerName string) *healthTracker {
+	return &healthTracker{
+		since:         since,
+		containerName: containerName,
+		observed:      map[string]bool{},
+		restarts:      map[string]int32{},
+		terminatedAt:  map[string]time.Time{},
+		ready:         map[string]bool{},
+		evicted:       map[string]bool{},
+	}
+}
+
+// observe compares pods with the ones of the previous poll, and counts restarts, OOMKills, evictions and flaps.
+func (t *healthTracker) observe(pods []corev1.Pod) {
+	for _, pod := range pods {
+		name := pod.ObjectMeta.Name
+		// restarts and eviction which had happened before loadtest started are not counted.
+		firstObserved := !t.polled && pod.ObjectMeta.CreationTimestamp.Time.Before(t.since)
+		t.observed[name] = true
+
+		if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted" {
+			if !t.evicted[name] && !firstObserved {
+				t.health.Evictions++
+			}
+			t.evicted[name] = true
+		}
+
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name != t.containerName {
+				continue
+			}
+			if !firstObserved && c.RestartCount > t.restarts[name] {
+				t.health.Restarts += c.RestartCount - t.restarts[name]
+			}
+			t.restarts[name] = c.RestartCount
+			for _, terminated := range []*corev1.ContainerStateTerminated{
+				c.State.Terminated, c.LastTerminationState.Terminated,
+			} {
+				if terminated == nil || terminated.Reason != "OOMKilled" {
+					continue
+				}
+				finishedAt := terminated.FinishedAt.Time
+				if finishedAt.Before(t.since) || !finishedAt.After(t.terminatedAt[name]) {
+					continue
+				}
+				t.health.OOMKills++
+				t.terminatedAt[name] = finishedAt
+			}
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type != corev1.PodReady {
+				continue
+			}
+			ready := condition.Status == corev1.ConditionTrue
+			if wasReady, ok := t.ready[name]; ok && wasReady && !ready {
+				t.health.ReadinessFlaps++
+			}
+			t.ready[name] = ready
+		}
+	}
+	t.polled = true
+}
+
+/*
+WatchPodHealth watches target pods during loadtest, and sends PodHealth to resultCh when loadtest finished.
+
+Target pods are polled every 5 seconds until receiveGatlingFinishedCh get value or context done.
+Warning events of target pods since loadtest started are fetched when loadtest finished.
+If error occured this error only log error and continue to run. (not returns error object)
+*/
+func WatchPodHealth(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	cl ctrlClient.Client,
+	resultCh chan PodHealth,
+	receiveGatlingFinishedCh chan bool,
+	podConfig cfg.TargetPodConfig,
+	since time.Time,
+) {
+	defer wg.Done()
+
+	log := func(msg string) {
+		fmt.Fprintf(
+			os.Stderr, "Error: %v, Target: %v, Namespace: %v\n", msg, podConfig.Name, podConfig.Namespace,
+		)
+	}
+	labelSelector, _ := podConfig.Selector() // selector is validated with config, so error is not expected here.
+	tracker := newHealthTracker(since, podConfig.ContainerName)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-receiveGatlingFinishedCh:
+			health := tracker.health
+			events, err := FetchPodEvents(ctx, cl, podConfig.Namespace, tracker.observed, since)
+			if err != nil {
+				log(err.Error())
+			}
+			health.Events = events
+			resultCh <- health
+			return
+		default:
+			var foundPods corev1.PodList
+			listOptions := &ctrlClient.ListOptions{
+				Namespace:     podConfig.Namespace,
+				LabelSelector: labelSelector,
+			}
+			if err := cl.List(ctx, &foundPods, listOptions); err != nil {
+				log(fmt.Sprintf("failed to list target pod, %v", err))
+			} else {
+				tracker.observe(foundPods.Items)
+			}
+			time.Sleep(5 * time.Second) // NOTE: wait few seconds to avoid excessive cpu usage.
+		}
+	}
+}
+
+// FetchPodEvents returns Warning events of specified pods which occured since specified time, in time order.
+func FetchPodEvents(
+	ctx context.Context,
+	cl ctrlClient.Client,
+	namespace string,
+	podNames map[string]bool,
+	since time.Time,
+) ([]PodEvent, error) {
+	var foundEvents corev1.EventList
+	if err := cl.List(ctx, &foundEvents, &ctrlClient.ListOptions{Namespace: namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list events, %v", err)
+	}
+	var events []PodEvent
+	for _, e := range foundEvents.Items {
+		if e.Type != corev1.EventTypeWarning || e.InvolvedObject.Kind != "Pod" || !podNames[e.InvolvedObject.Name] {
+			continue
+		}
+		// lastTimestamp is not set to events created by events.k8s.io API, so eventTime is used instead.
+		lastTimestamp := e.LastTimestamp.Time
+		if lastTimestamp.IsZero() {
+			lastTimestamp = e.EventTime.Time
+		}
+		if lastTimestamp.Before(since) {
+			continue
+		}
+		events = append(events, PodEvent{
+			PodName:       e.InvolvedObject.Name,
+			Type:          e.Type,
+			Reason:        e.Reason,
+			Message:       e.Message,
+			Count:         e.Count,
+			LastTimestamp: lastTimestamp,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(events[j].LastTimestamp)
+	})
+	return events, nil
+}
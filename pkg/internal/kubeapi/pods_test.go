@@ -0,0 +1,286 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cfg "github.com/st-tech/gatling-commander/pkg/config"
+	"github.com/st-tech/gatling-commander/pkg/internal/kubeutil"
+)
+
+func TestFetchContainerStatuses(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	terminatedAt := metav1.NewTime(time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC))
+	samplePod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sample-pod",
+			Namespace: "sample-namespace",
+			Labels:    map[string]string{"app": "sample-app"},
+		},
+		Status: corev1.PodStatus{
+			Phase: "Running",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "sample-container",
+					RestartCount: 2,
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: terminatedAt},
+					},
+				},
+				{
+					Name:         "sidecar-container",
+					RestartCount: 5,
+				},
+			},
+		},
+	}
+	err := cl.Create(context.TODO(), &samplePod)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		podConfig cfg.TargetPodConfig
+		expected  []ContainerStatus
+	}{
+		{
+			name: "fetch status of target container",
+			podConfig: cfg.TargetPodConfig{
+				Namespace:     "sample-namespace",
+				LabelKey:      "app",
+				LabelValue:    "sample-app",
+				ContainerName: "sample-container",
+			},
+			expected: []ContainerStatus{
+				{
+					PodName:               "sample-pod",
+					RestartCount:          2,
+					LastTerminationReason: "OOMKilled",
+					LastTerminatedAt:      terminatedAt.Time,
+				},
+			},
+		},
+		{
+			name: "no pod matches label",
+			podConfig: cfg.TargetPodConfig{
+				Namespace:     "sample-namespace",
+				LabelKey:      "app",
+				LabelValue:    "other-app",
+				ContainerName: "sample-container",
+			},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, err := FetchContainerStatuses(context.TODO(), cl, tt.podConfig)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expected), len(statuses))
+			for i := range tt.expected {
+				assert.Equal(t, tt.expected[i].PodName, statuses[i].PodName)
+				assert.Equal(t, tt.expected[i].RestartCount, statuses[i].RestartCount)
+				assert.Equal(t, tt.expected[i].LastTerminationReason, statuses[i].LastTerminationReason)
+				assert.True(t, tt.expected[i].LastTerminatedAt.Equal(statuses[i].LastTerminatedAt))
+			}
+		})
+	}
+}
+
+func TestFetchRunningPodNames(t *testing.T) {
+	cl := kubeutil.InitFakeClient()
+	newPod := func(name, app string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sample-namespace", Labels: map[string]string{"app": app}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []corev1.Pod{
+		newPod("sample-pod-b", "sample-app", corev1.PodRunning),
+		newPod("sample-pod-a", "sample-app", corev1.PodRunning),
+		newPod("pending-pod", "sample-app", corev1.PodPending),
+		newPod("other-pod", "other-app", corev1.PodRunning),
+	}
+	for i := range pods {
+		assert.NoError(t, cl.Create(context.TODO(), &pods[i]))
+	}
+	podNames, err := FetchRunningPodNames(context.TODO(), cl, cfg.TargetPodConfig{
+		Namespace:     "sample-namespace",
+		LabelKey:      "app",
+		LabelValue:    "sample-app",
+		ContainerName: "sample-container",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sample-pod-a", "sample-pod-b"}, podNames)
+}
+
+func TestHealthTrackerObserve(t *testing.T) {
+	since := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	createdAt := metav1.NewTime(since.Add(-time.Hour))
+	newPod := func(
+		name string, restarts int32, ready corev1.ConditionStatus, terminated *corev1.ContainerStateTerminated,
+	) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: createdAt},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:                 "sample-container",
+						RestartCount:         restarts,
+						LastTerminationState: corev1.ContainerState{Terminated: terminated},
+					},
+				},
+			},
+		}
+	}
+	oomKilledBefore := &corev1.ContainerStateTerminated{
+		Reason: "OOMKilled", FinishedAt: metav1.NewTime(since.Add(-time.Minute)),
+	}
+	oomKilled := &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(since.Add(time.Minute))}
+	evictedPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-c", CreationTimestamp: createdAt},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+	}
+	// pod created during loadtest, restarts of it are all counted.
+	createdPod := newPod("pod-d", 1, corev1.ConditionTrue, nil)
+	createdPod.ObjectMeta.CreationTimestamp = metav1.NewTime(since.Add(time.Minute))
+
+	tracker := newHealthTracker(since, "sample-container")
+	polls := [][]corev1.Pod{
+		{
+			newPod("pod-a", 3, corev1.ConditionTrue, oomKilledBefore),
+			newPod("pod-b", 0, corev1.ConditionTrue, nil),
+		},
+		{
+			newPod("pod-a", 4, corev1.ConditionFalse, oomKilled),
+			newPod("pod-b", 0, corev1.ConditionTrue, nil),
+			evictedPod,
+		},
+		{
+			newPod("pod-a", 4, corev1.ConditionTrue, oomKilled),
+			newPod("pod-b", 0, corev1.ConditionFalse, nil),
+			evictedPod,
+			createdPod,
+		},
+	}
+	for _, pods := range polls {
+		tracker.observe(pods)
+	}
+	assert.Equal(t, PodHealth{Restarts: 2, OOMKills: 1, Evictions: 1, ReadinessFlaps: 2}, tracker.health)
+}
+
+func TestFetchPodEvents(t *testing.T) {
+	ctx := context.TODO()
+	cl := kubeutil.InitFakeClient()
+	since := time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	newEvent := func(name, eventType, podName string, lastTimestamp time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "sample-namespace"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: podName},
+			Type:           eventType,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          2,
+			LastTimestamp:  metav1.NewTime(lastTimestamp),
+		}
+	}
+	for _, e := range []*corev1.Event{
+		newEvent("warning-event", corev1.EventTypeWarning, "sample-pod", since.Add(2*time.Minute)),
+		newEvent("normal-event", corev1.EventTypeNormal, "sample-pod", since.Add(time.Minute)),
+		newEvent("other-pod-event", corev1.EventTypeWarning, "other-pod", since.Add(time.Minute)),
+		newEvent("old-event", corev1.EventTypeWarning, "sample-pod", since.Add(-time.Minute)),
+	} {
+		assert.NoError(t, cl.Create(ctx, e))
+	}
+
+	events, err := FetchPodEvents(ctx, cl, "sample-namespace", map[string]bool{"sample-pod": true}, since)
+	assert.NoError(t, err)
+	for i := range events {
+		events[i].LastTimestamp = events[i].LastTimestamp.UTC() // fake client decodes time in local location
+	}
+	assert.Equal(t, []PodEvent{
+		{
+			PodName:       "sample-pod",
+			Type:          corev1.EventTypeWarning,
+			Reason:        "BackOff",
+			Message:       "Back-off restarting failed container",
+			Count:         2,
+			LastTimestamp: since.Add(2 * time.Minute),
+		},
+	}, events)
+}
+
+func TestWatchPodHealth(t *testing.T) {
+	ctx := context.TODO()
+	cl := kubeutil.InitFakeClient()
+	since := time.Now()
+	err := cl.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sample-pod",
+			Namespace: "sample-namespace",
+			Labels:    map[string]string{"app": "sample-app"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "sample-container",
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Reason: "OOMKilled", FinishedAt: metav1.NewTime(since.Add(time.Minute)),
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	podConfig := cfg.TargetPodConfig{
+		Namespace:     "sample-namespace",
+		LabelKey:      "app",
+		LabelValue:    "sample-app",
+		ContainerName: "sample-container",
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	informJobFinishCh := make(chan bool)
+	resultCh := make(chan PodHealth, 1)
+	go WatchPodHealth(ctx, &wg, cl, resultCh, informJobFinishCh, podConfig, since)
+	time.Sleep(time.Second) // wait the first poll of target pods
+	informJobFinishCh <- true
+	close(informJobFinishCh)
+	wg.Wait()
+	close(resultCh)
+
+	health := <-resultCh
+	assert.Equal(t, PodHealth{OOMKills: 1}, health)
+}
@@ -22,8 +22,135 @@ SOFTWARE.
 
 package kubeapi
 
+import (
+	"fmt"
+	"time"
+)
+
 // MetricsField hold container metrics value.
 type MetricsField struct {
 	Cpu    int64 // milli vCPU
 	Memory int64 // bytes
 }
+
+// ContainerStatus hold restart status of target container in a pod.
+type ContainerStatus struct {
+	PodName      string
+	RestartCount int32
+	// LastTerminationReason is reason of the last termination of container, e.g. OOMKilled.
+	LastTerminationReason string
+	LastTerminatedAt      time.Time
+}
+
+/*
+PodHealth hold health of target pods observed during loadtest.
+
+Restarts and OOMKills are counted for target container. Evictions and ReadinessFlaps are counted for pods,
+ReadinessFlaps is count of transition of Ready condition from True to False.
+*/
+type PodHealth struct {
+	Restarts       int32      `json:"restarts"`
+	OOMKills       int        `json:"oomKills"`
+	Evictions      int        `json:"evictions"`
+	ReadinessFlaps int        `json:"readinessFlaps"`
+	Events         []PodEvent `json:"events,omitempty"` // Warning events of target pods
+}
+
+// Healthy returns true when no restart, OOMKill, eviction and readiness flap is observed.
+func (h PodHealth) Healthy() bool {
+	return h.Restarts == 0 && h.OOMKills == 0 && h.Evictions == 0 && h.ReadinessFlaps == 0
+}
+
+// Summary returns counts of PodHealth as one line.
+func (h PodHealth) Summary() string {
+	return fmt.Sprintf(
+		"restarts %v, oomKills %v, evictions %v, readiness flaps %v",
+		h.Restarts,
+		h.OOMKills,
+		h.Evictions,
+		h.ReadinessFlaps,
+	)
+}
+
+// PodEvent hold Kubernetes Event of a target pod.
+type PodEvent struct {
+	PodName       string    `json:"podName"`
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// MetricsSample hold target container metrics value of a pod at a time.
+type MetricsSample struct {
+	PodName   string    `json:"podName"`
+	Timestamp time.Time `json:"timestamp"`
+	Cpu       int64     `json:"cpu"`    // milli vCPU
+	Memory    int64     `json:"memory"` // bytes
+}
+
+// ResourceStats hold statistics of samples of a resource.
+type ResourceStats struct {
+	Min  int64 `json:"min"`
+	Max  int64 `json:"max"`
+	Mean int64 `json:"mean"`
+	P50  int64 `json:"p50"`
+	P95  int64 `json:"p95"`
+	P99  int64 `json:"p99"`
+	// PeakPodName and PeakAt are pod name and timestamp of the sample which has Max value.
+	PeakPodName string    `json:"peakPodName"`
+	PeakAt      time.Time `json:"peakAt"`
+}
+
+// PodMetricsStats hold statistics of samples of target container of a pod.
+type PodMetricsStats struct {
+	PodName string        `json:"podName"`
+	Cpu     ResourceStats `json:"cpu"`
+	Memory  ResourceStats `json:"memory"`
+	// CPUUsagePercent and MemoryUsagePercent are mean usage divided by limit of the pod.
+	CPUUsagePercent    float64 `json:"cpuUsagePercent"`
+	MemoryUsagePercent float64 `json:"memoryUsagePercent"`
+}
+
+// AggregateSample hold replica count and total usage and limits of target containers of all pods at a poll.
+type AggregateSample struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Replicas    int       `json:"replicas"`
+	Cpu         int64     `json:"cpu"`         // milli vCPU
+	Memory      int64     `json:"memory"`      // bytes
+	CpuLimit    int64     `json:"cpuLimit"`    // milli vCPU
+	MemoryLimit int64     `json:"memoryLimit"` // bytes
+}
+
+// ReplicaStats hold statistics of replica count of target pods during loadtest.
+type ReplicaStats struct {
+	Min              int     `json:"min"`
+	Max              int     `json:"max"`
+	TimeWeightedMean float64 `json:"timeWeightedMean"`
+}
+
+/*
+MetricsSeries hold timestamped samples of target container during loadtest and statistics of them.
+
+Cpu and Memory are statistics of samples of all pods, and Pods are the ones of each pod.
+CPUUsagePercent and MemoryUsagePercent are aggregate usage, total usage of all pods divided by total limits of them.
+*/
+type MetricsSeries struct {
+	Samples            []MetricsSample   `json:"samples"`
+	Cpu                ResourceStats     `json:"cpu"`
+	Memory             ResourceStats     `json:"memory"`
+	Pods               []PodMetricsStats `json:"pods"`
+	Aggregates         []AggregateSample `json:"aggregates"`
+	Replicas           ReplicaStats      `json:"replicas"`
+	CPUUsagePercent    float64           `json:"cpuUsagePercent"`
+	MemoryUsagePercent float64           `json:"memoryUsagePercent"`
+}
+
+// Mean returns mean value of each resource.
+func (s MetricsSeries) Mean() MetricsField {
+	return MetricsField{
+		Cpu:    s.Cpu.Mean,
+		Memory: s.Memory.Mean,
+	}
+}
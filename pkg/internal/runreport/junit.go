@@ -0,0 +1,227 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package runreport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+)
+
+// junitTestSuites is root element of JUnit XML, which has test suite of each service.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Cases      []junitTestCase  `xml:"testcase"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	Classname  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitResult     `xml:"failure,omitempty"`
+	Error      *junitResult     `xml:"error,omitempty"`
+	Skipped    *junitResult     `xml:"skipped,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+/*
+WriteJUnit writes report as JUnit XML.
+
+Each service is test suite, and each loadtest is test case of which classname is service name. Metrics and result of
+each SLO assertion are properties of test case, and violated assertions are written in failure of test case.
+Outputs of loadtest, such as path of history record, are written to system-out.
+*/
+func (r Report) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name:     "gatling-commander " + r.RunID,
+		Tests:    r.Counts.Tests,
+		Failures: r.Counts.Failures,
+		Errors:   r.Counts.Errors,
+		Skipped:  r.Counts.Skipped,
+		Suites:   make([]junitTestSuite, 0, len(r.Services)),
+	}
+	var totalSec float64
+	for _, service := range r.Services {
+		suite := junitTestSuite{
+			Name:     service.Name,
+			Tests:    service.Counts.Tests,
+			Failures: service.Counts.Failures,
+			Errors:   service.Counts.Errors,
+			Skipped:  service.Counts.Skipped,
+			Cases:    make([]junitTestCase, 0, len(service.Cases)),
+		}
+		if !r.StartedAt.IsZero() {
+			suite.Timestamp = r.StartedAt.Format(time.RFC3339)
+		}
+		suite.Properties = newJUnitProperties(suiteProperties(r.RunID, service))
+		var suiteSec float64
+		for _, testCase := range service.Cases {
+			suite.Cases = append(suite.Cases, newJUnitTestCase(service.Name, testCase))
+			suiteSec += testCase.DurationSec
+		}
+		suite.Time = formatSec(suiteSec)
+		totalSec += suiteSec
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = formatSec(totalSec)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// suiteProperties returns properties of test suite of service, which are run ID, stop reason and capacity.
+func suiteProperties(runID string, service Service) []junitProperty {
+	properties := []junitProperty{{Name: "runID", Value: runID}}
+	if service.StopReason != "" {
+		properties = append(properties, junitProperty{Name: "stopReason", Value: service.StopReason})
+	}
+	if service.Capacity != nil {
+		capacity := "not found"
+		if service.Capacity.Found {
+			capacity = strconv.Itoa(service.Capacity.Capacity)
+		}
+		properties = append(properties, junitProperty{Name: "capacity", Value: capacity})
+	}
+	return properties
+}
+
+func newJUnitTestCase(serviceName string, testCase Case) junitTestCase {
+	junitCase := junitTestCase{
+		Name:      strings.TrimSpace(testCase.Scenario + " " + testCase.SubName),
+		Classname: serviceName,
+		Time:      formatSec(testCase.DurationSec),
+		SystemOut: strings.Join(testCase.Outputs, "\n"),
+	}
+	properties := make([]junitProperty, 0, len(testCase.Metrics))
+	for _, metric := range testCase.Metrics {
+		properties = append(properties, junitProperty{
+			Name:  metric.Name,
+			Value: strconv.FormatFloat(metric.Value, 'f', -1, 64),
+		})
+	}
+	if testCase.SLO != nil {
+		for _, assertion := range testCase.SLO.Assertions {
+			properties = append(properties, junitProperty{
+				Name:  "slo " + assertion.Assertion,
+				Value: formatAssertionResult(assertion),
+			})
+		}
+	}
+	if testCase.Comparison != nil {
+		properties = append(properties, junitProperty{Name: "baseline", Value: testCase.Comparison.Summary()})
+	}
+	junitCase.Properties = newJUnitProperties(properties)
+
+	switch testCase.Result {
+	case ResultFailed:
+		var details []string
+		for _, violation := range testCase.SLO.Violations() {
+			details = append(details, fmt.Sprintf("%v: %v", violation.Assertion, formatAssertionResult(violation)))
+		}
+		if testCase.Comparison.Regressed() {
+			details = append(details, testCase.Comparison.Summary())
+		}
+		junitCase.Failure = &junitResult{
+			Message: testCase.Message,
+			Type:    "assertion",
+			Text:    strings.Join(details, "\n"),
+		}
+	case ResultError:
+		junitCase.Error = &junitResult{Message: testCase.Message, Type: string(testCase.Status)}
+	case ResultSkipped:
+		junitCase.Skipped = &junitResult{Message: testCase.Message}
+	}
+	return junitCase
+}
+
+// formatAssertionResult returns result of assertion, such as "failed [stop] (result: 350, threshold: 300)".
+func formatAssertionResult(assertion slo.AssertionResult) string {
+	if assertion.Error != "" {
+		return fmt.Sprintf("error [%v] (%v)", assertion.Severity, assertion.Error)
+	}
+	result := "failed"
+	if assertion.Passed {
+		result = "passed"
+	}
+	return fmt.Sprintf(
+		"%v [%v] (result: %.4g, threshold: %.4g)", result, assertion.Severity, assertion.Actual, assertion.Threshold,
+	)
+}
+
+func newJUnitProperties(properties []junitProperty) *junitProperties {
+	if len(properties) == 0 {
+		return nil
+	}
+	return &junitProperties{Properties: properties}
+}
+
+func formatSec(sec float64) string {
+	return strconv.FormatFloat(sec, 'f', 3, 64)
+}
@@ -0,0 +1,332 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package runreport implements machine-readable report of run for CI, which is written as JUnit XML or JSON.
+package runreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/history"
+	"github.com/st-tech/gatling-commander/pkg/internal/runstate"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+)
+
+// Format is file format of run report.
+type Format string
+
+// Formats of run report.
+const (
+	FormatJUnit Format = "junit"
+	FormatJSON  Format = "json"
+)
+
+// CaseResult is result of test case, which is the same as the one of JUnit.
+type CaseResult string
+
+// Results of test case.
+const (
+	ResultPassed  CaseResult = "passed"
+	ResultFailed  CaseResult = "failed"
+	ResultError   CaseResult = "error"
+	ResultSkipped CaseResult = "skipped"
+)
+
+// Output is format and file path of run report, specified as "<format>=<path>".
+type Output struct {
+	Format Format
+	Path   string
+}
+
+/*
+Report has result of each loadtest of run as test case.
+
+Passed is false when run returned error, so that it is the same as exit status of exec command.
+*/
+type Report struct {
+	RunID     string    `json:"runID"`
+	StartedAt time.Time `json:"startedAt"`
+	Passed    bool      `json:"passed"`
+	Error     string    `json:"error,omitempty"`
+	Counts    Counts    `json:"counts"`
+	Services  []Service `json:"services"`
+}
+
+// Counts has number of test cases of each result.
+type Counts struct {
+	Tests    int `json:"tests"`
+	Failures int `json:"failures"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+}
+
+// Service has test cases of loadtests of the service.
+type Service struct {
+	Name       string           `json:"name"`
+	StopReason string           `json:"stopReason,omitempty"`
+	Capacity   *capacity.Result `json:"capacity,omitempty"`
+	Counts     Counts           `json:"counts"`
+	Cases      []Case           `json:"cases"`
+}
+
+/*
+Case is test case of a loadtest.
+
+Metrics are values of Gatling report and the primary target container, empty when loadtest result is not recorded.
+*/
+type Case struct {
+	Scenario    string                  `json:"scenario"`
+	SubName     string                  `json:"subName"`
+	Status      runstate.ScenarioStatus `json:"status"`
+	Result      CaseResult              `json:"result"`
+	Message     string                  `json:"message,omitempty"`
+	DurationSec float64                 `json:"durationSec"`
+	Metrics     []Metric                `json:"metrics,omitempty"`
+	SLO         *slo.Result             `json:"slo,omitempty"`
+	Comparison  *baseline.Comparison    `json:"comparison,omitempty"`
+	Outputs     []string                `json:"outputs,omitempty"`
+}
+
+// Metric is a value of loadtest result, named as the column of history export.
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// ParseOutput parses value of output flag, such as "junit=results/junit.xml".
+func ParseOutput(value string) (Output, error) {
+	format, path, found := strings.Cut(value, "=")
+	if !found || path == "" {
+		return Output{}, fmt.Errorf("output %v must be formatted as <format>=<path>", value)
+	}
+	switch Format(format) {
+	case FormatJUnit, FormatJSON:
+	default:
+		return Output{}, fmt.Errorf("unsupported output format %v, specify %v or %v", format, FormatJUnit, FormatJSON)
+	}
+	return Output{Format: Format(format), Path: path}, nil
+}
+
+/*
+New returns Report of run from run state and Records of the run, runErr is error returned by the run.
+
+Each scenario of run state becomes a test case, and its result is decided as below.
+  - error: loadtest failed, or aborted except capacity search trial
+  - failed: loadtest regressed from baseline, or violated SLO of stop or fail-run severity except capacity search trial
+  - skipped: loadtest was not executed or cancelled
+  - passed: otherwise
+
+SLO and abort of capacity search trials decide capacity, so they don't fail test case.
+*/
+func New(state runstate.RunState, records []history.Record, runErr error) Report {
+	report := Report{
+		RunID:     state.RunID,
+		StartedAt: state.StartedAt,
+		Passed:    runErr == nil,
+		Services:  make([]Service, 0, len(state.Services)),
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	for _, serviceState := range state.Services {
+		service := Service{
+			Name:       serviceState.Name,
+			StopReason: serviceState.StopReason,
+			Capacity:   serviceState.Capacity,
+			Cases:      make([]Case, 0, len(serviceState.Scenarios)),
+		}
+		for _, scenario := range serviceState.Scenarios {
+			testCase := newCase(serviceState, scenario)
+			if record := findRecord(records, serviceState.Name, scenario.Name, scenario.SubName); record != nil {
+				testCase.DurationSec = record.FinishedAt.Sub(record.StartedAt).Seconds()
+				testCase.Metrics = recordMetrics(*record)
+			}
+			service.Cases = append(service.Cases, testCase)
+			service.Counts.add(testCase.Result)
+		}
+		report.Services = append(report.Services, service)
+		report.Counts.Tests += service.Counts.Tests
+		report.Counts.Failures += service.Counts.Failures
+		report.Counts.Errors += service.Counts.Errors
+		report.Counts.Skipped += service.Counts.Skipped
+	}
+	return report
+}
+
+// newCase returns Case of scenario, of which result is decided by status, comparison and SLO.
+func newCase(serviceState runstate.ServiceState, scenario runstate.ScenarioState) Case {
+	testCase := Case{
+		Scenario:   scenario.Name,
+		SubName:    scenario.SubName,
+		Status:     scenario.Status,
+		Result:     ResultPassed,
+		SLO:        scenario.SLO,
+		Comparison: scenario.Comparison,
+		Outputs:    scenario.Outputs,
+	}
+	capacityTrial := serviceState.Capacity != nil
+	switch scenario.Status {
+	case runstate.StatusCompleted:
+		var reasons []string
+		if scenario.Comparison.Regressed() {
+			reasons = append(reasons, scenario.Comparison.Summary())
+		}
+		if !capacityTrial && scenario.SLO.Failed() {
+			reasons = append(reasons, scenario.SLO.Summary())
+		}
+		if len(reasons) > 0 {
+			testCase.Result = ResultFailed
+			testCase.Message = strings.Join(reasons, ", ")
+		}
+	case runstate.StatusAborted:
+		testCase.Message = fmt.Sprintf("aborted, %v", scenario.AbortReason)
+		if !capacityTrial {
+			testCase.Result = ResultError
+		}
+	case runstate.StatusFailed:
+		testCase.Result = ResultError
+		testCase.Message = scenario.Error
+	case runstate.StatusCancelled:
+		testCase.Result = ResultSkipped
+		testCase.Message = "cancelled"
+	default:
+		testCase.Result = ResultSkipped
+		testCase.Message = "not executed"
+		if serviceState.Stopped {
+			testCase.Message = fmt.Sprintf("not executed, service stopped, %v", serviceState.StopReason)
+		}
+	}
+	return testCase
+}
+
+// findRecord returns the last Record of the scenario, returns nil when it is not found.
+func findRecord(records []history.Record, serviceName, scenarioName, scenarioSubName string) *history.Record {
+	var found *history.Record
+	for i := range records {
+		record := &records[i]
+		if record.ServiceName == serviceName &&
+			record.ScenarioName == scenarioName &&
+			record.ScenarioSubName == scenarioSubName {
+			found = record
+		}
+	}
+	return found
+}
+
+/*
+recordMetrics returns metrics of Record, latency of each percentile is named such as p99Latency.
+
+Percentiles derived from simulation.log follow the ones of Gatling report in ascending order.
+*/
+func recordMetrics(record history.Record) []Metric {
+	var metrics []Metric
+	if report := record.GatlingReport; report != nil {
+		metrics = append(metrics,
+			Metric{Name: "requests", Value: report.NumberOfRequests.Total},
+			Metric{Name: "failedPercentage", Value: report.Failed.Percentage},
+			Metric{Name: "throughput", Value: report.MeanNumberOfRequestsPerSecond.Total},
+			Metric{Name: "meanLatency", Value: report.MeanResponseTime.Ok},
+			Metric{Name: "maxLatency", Value: report.MaxResponseTime.Ok},
+		)
+		for _, percentile := range report.ReportPercentiles() {
+			latency, err := report.GetPercentileLatency(percentile)
+			if err != nil {
+				continue
+			}
+			metrics = append(metrics, Metric{Name: percentileMetricName(percentile), Value: latency})
+		}
+		exactPercentiles := make([]float64, 0, len(report.ExactPercentiles))
+		for key := range report.ExactPercentiles {
+			percentile, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				continue
+			}
+			exactPercentiles = append(exactPercentiles, percentile)
+		}
+		sort.Float64s(exactPercentiles)
+		for _, percentile := range exactPercentiles {
+			metrics = append(metrics, Metric{
+				Name:  percentileMetricName(percentile),
+				Value: report.ExactPercentiles[gatling.FormatPercentile(percentile)],
+			})
+		}
+	}
+	metrics = append(metrics,
+		Metric{Name: "cpuUsagePercent", Value: record.Container.CPUUsagePercent},
+		Metric{Name: "memoryUsagePercent", Value: record.Container.MemoryUsagePercent},
+	)
+	return metrics
+}
+
+func percentileMetricName(percentile float64) string {
+	return fmt.Sprintf("p%vLatency", gatling.FormatPercentile(percentile))
+}
+
+func (c *Counts) add(result CaseResult) {
+	c.Tests++
+	switch result {
+	case ResultFailed:
+		c.Failures++
+	case ResultError:
+		c.Errors++
+	case ResultSkipped:
+		c.Skipped++
+	}
+}
+
+// Write writes report to path of output in its format, and creates parent directory if it doesn't exist.
+func (o Output) Write(report Report) error {
+	if err := os.MkdirAll(filepath.Dir(o.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(o.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch o.Format {
+	case FormatJUnit:
+		return report.WriteJUnit(f)
+	case FormatJSON:
+		return report.WriteJSON(f)
+	default:
+		return fmt.Errorf("unsupported output format %v", o.Format)
+	}
+}
+
+// WriteJSON writes report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
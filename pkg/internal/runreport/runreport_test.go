@@ -0,0 +1,301 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package runreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+	"github.com/st-tech/gatling-commander/pkg/internal/history"
+	"github.com/st-tech/gatling-commander/pkg/internal/runstate"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	startedAt = time.Date(2023, 11, 13, 10, 50, 0, 0, time.UTC)
+	failedSLO = &slo.Result{Assertions: []slo.AssertionResult{
+		{Assertion: "p99 < 300ms", Severity: slo.SeverityStop, Passed: false, Actual: 350, Threshold: 300},
+		{Assertion: "cpu% < 70", Severity: slo.SeverityWarn, Passed: true, Actual: 50, Threshold: 70},
+	}}
+)
+
+func sampleState() runstate.RunState {
+	return runstate.RunState{
+		RunID:     "202311131050-1a2b",
+		StartedAt: startedAt,
+		Services: []runstate.ServiceState{
+			{
+				Name:       "sample-service",
+				Stopped:    true,
+				StopReason: failedSLO.Summary(),
+				Scenarios: []runstate.ScenarioState{
+					{
+						Name:    "sample-scenario",
+						SubName: "1",
+						Status:  runstate.StatusCompleted,
+						Outputs: []string{"history results/sample-1.json"},
+						SLO:     failedSLO,
+					},
+					{Name: "sample-scenario", SubName: "2", Status: runstate.StatusPending},
+				},
+			},
+			{
+				Name:     "search-service",
+				Capacity: &capacity.Result{Strategy: capacity.StrategyStep, Found: true, Capacity: 10},
+				Scenarios: []runstate.ScenarioState{
+					{Name: "search-scenario", SubName: "concurrency 10", Status: runstate.StatusCompleted},
+					{
+						Name:        "search-scenario",
+						SubName:     "concurrency 20",
+						Status:      runstate.StatusAborted,
+						AbortReason: "cpu usage exceeded",
+						SLO:         failedSLO,
+					},
+				},
+			},
+			{
+				Name: "other-service",
+				Scenarios: []runstate.ScenarioState{
+					{Name: "other-scenario", SubName: "1", Status: runstate.StatusFailed, Error: "gatling job startup failed"},
+					{Name: "other-scenario", SubName: "2", Status: runstate.StatusCancelled},
+				},
+			},
+		},
+	}
+}
+
+func sampleRecords() []history.Record {
+	return []history.Record{
+		{
+			RunID:           "202311131050-1a2b",
+			ServiceName:     "sample-service",
+			ScenarioName:    "sample-scenario",
+			ScenarioSubName: "1",
+			StartedAt:       startedAt,
+			FinishedAt:      startedAt.Add(90 * time.Second),
+			GatlingReport: &gatling.GatlingReport{
+				NumberOfRequests:              gatling.GatlingReportStats{Total: 1000},
+				MeanResponseTime:              gatling.GatlingReportStats{Ok: 80},
+				MaxResponseTime:               gatling.GatlingReportStats{Ok: 500},
+				FiftiethPercentiles:           gatling.GatlingReportStats{Ok: 70},
+				SeventyFifthPercentiles:       gatling.GatlingReportStats{Ok: 90},
+				NintyFifthPercentiles:         gatling.GatlingReportStats{Ok: 200},
+				NintyNinthPercentiles:         gatling.GatlingReportStats{Ok: 350},
+				MeanNumberOfRequestsPerSecond: gatling.GatlingReportStats{Total: 11.1},
+				Failed:                        gatling.GatlingReportGroup{Percentage: 0.5},
+				ExactPercentiles:              map[string]float64{"99.9": 450},
+			},
+			Container: history.ContainerMetrics{CPUUsagePercent: 50, MemoryUsagePercent: 40},
+		},
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    Output
+		expectedErr error
+	}{
+		{
+			name:     "junit",
+			value:    "junit=results/junit.xml",
+			expected: Output{Format: FormatJUnit, Path: "results/junit.xml"},
+		},
+		{
+			name:     "json",
+			value:    "json=results.json",
+			expected: Output{Format: FormatJSON, Path: "results.json"},
+		},
+		{
+			name:        "no path",
+			value:       "junit=",
+			expectedErr: fmt.Errorf("output junit= must be formatted as <format>=<path>"),
+		},
+		{
+			name:        "unsupported format",
+			value:       "html=report.html",
+			expectedErr: fmt.Errorf("unsupported output format html, specify junit or json"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseOutput(tt.value)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	report := New(sampleState(), sampleRecords(), fmt.Errorf("more than one loadtest scenario failed"))
+	assert.False(t, report.Passed)
+	assert.Equal(t, "more than one loadtest scenario failed", report.Error)
+	assert.Equal(t, Counts{Tests: 6, Failures: 1, Errors: 1, Skipped: 2}, report.Counts)
+
+	results := make([]CaseResult, 0, 6)
+	messages := make([]string, 0, 6)
+	for _, service := range report.Services {
+		for _, testCase := range service.Cases {
+			results = append(results, testCase.Result)
+			messages = append(messages, testCase.Message)
+		}
+	}
+	assert.Equal(t, []CaseResult{
+		ResultFailed, ResultSkipped, ResultPassed, ResultPassed, ResultError, ResultSkipped,
+	}, results)
+	assert.Equal(t, []string{
+		"slo violated: p99 < 300ms [stop] (result: 350)",
+		"not executed, service stopped, slo violated: p99 < 300ms [stop] (result: 350)",
+		"",
+		// abort and SLO of capacity search trial decide capacity.
+		"aborted, cpu usage exceeded",
+		"gatling job startup failed",
+		"cancelled",
+	}, messages)
+
+	testCase := report.Services[0].Cases[0]
+	assert.Equal(t, float64(90), testCase.DurationSec)
+	assert.Equal(t, []Metric{
+		{Name: "requests", Value: 1000},
+		{Name: "failedPercentage", Value: 0.5},
+		{Name: "throughput", Value: 11.1},
+		{Name: "meanLatency", Value: 80},
+		{Name: "maxLatency", Value: 500},
+		{Name: "p50Latency", Value: 70},
+		{Name: "p75Latency", Value: 90},
+		{Name: "p95Latency", Value: 200},
+		{Name: "p99Latency", Value: 350},
+		{Name: "p99.9Latency", Value: 450},
+		{Name: "cpuUsagePercent", Value: 50},
+		{Name: "memoryUsagePercent", Value: 40},
+	}, testCase.Metrics)
+	// metrics of loadtest without record are not known.
+	assert.Nil(t, report.Services[0].Cases[1].Metrics)
+}
+
+func TestNew_Regressed(t *testing.T) {
+	state := runstate.RunState{
+		Services: []runstate.ServiceState{
+			{
+				Name: "sample-service",
+				Scenarios: []runstate.ScenarioState{
+					{
+						Name:    "sample-scenario",
+						SubName: "1",
+						Status:  runstate.StatusCompleted,
+						Comparison: &baseline.Comparison{
+							BaselineRunID: "202311131050-1a2b",
+							Regressions: []baseline.Regression{
+								{Metric: baseline.MetricP99Latency, Baseline: 100, Current: 150, Change: 50},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	report := New(state, nil, nil)
+	assert.True(t, report.Passed)
+	assert.Equal(t, ResultFailed, report.Services[0].Cases[0].Result)
+	assert.Equal(
+		t, "regression vs 202311131050-1a2b: p99 +50.0% (100 -> 150)", report.Services[0].Cases[0].Message,
+	)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	state := sampleState()
+	state.Services = state.Services[:1]
+	report := New(state, sampleRecords(), fmt.Errorf("slo violated in 1 loadtests"))
+	var out bytes.Buffer
+	assert.NoError(t, report.WriteJUnit(&out))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="gatling-commander 202311131050-1a2b" tests="2" failures="1" errors="0" skipped="1" time="90.000">
+  <testsuite name="sample-service" tests="2" failures="1" errors="0" skipped="1" time="90.000" ` +
+		`timestamp="2023-11-13T10:50:00Z">
+    <properties>
+      <property name="runID" value="202311131050-1a2b"></property>
+      <property name="stopReason" value="slo violated: p99 &lt; 300ms [stop] (result: 350)"></property>
+    </properties>
+    <testcase name="sample-scenario 1" classname="sample-service" time="90.000">
+      <properties>
+        <property name="requests" value="1000"></property>
+        <property name="failedPercentage" value="0.5"></property>
+        <property name="throughput" value="11.1"></property>
+        <property name="meanLatency" value="80"></property>
+        <property name="maxLatency" value="500"></property>
+        <property name="p50Latency" value="70"></property>
+        <property name="p75Latency" value="90"></property>
+        <property name="p95Latency" value="200"></property>
+        <property name="p99Latency" value="350"></property>
+        <property name="p99.9Latency" value="450"></property>
+        <property name="cpuUsagePercent" value="50"></property>
+        <property name="memoryUsagePercent" value="40"></property>
+        <property name="slo p99 &lt; 300ms" value="failed [stop] (result: 350, threshold: 300)"></property>
+        <property name="slo cpu% &lt; 70" value="passed [warn] (result: 50, threshold: 70)"></property>
+      </properties>
+      <failure message="slo violated: p99 &lt; 300ms [stop] (result: 350)" type="assertion">` +
+		`p99 &lt; 300ms: failed [stop] (result: 350, threshold: 300)</failure>
+      <system-out>history results/sample-1.json</system-out>
+    </testcase>
+    <testcase name="sample-scenario 2" classname="sample-service" time="0.000">
+      <skipped message="not executed, service stopped, slo violated: p99 &lt; 300ms [stop] (result: 350)"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestOutputWrite(t *testing.T) {
+	report := New(sampleState(), sampleRecords(), nil)
+	dir := t.TempDir()
+
+	jsonOutput := Output{Format: FormatJSON, Path: filepath.Join(dir, "reports", "result.json")}
+	assert.NoError(t, jsonOutput.Write(report))
+	jsonBytes, err := os.ReadFile(jsonOutput.Path)
+	assert.NoError(t, err)
+	var written Report
+	assert.NoError(t, json.Unmarshal(jsonBytes, &written))
+	assert.Equal(t, report.RunID, written.RunID)
+	assert.True(t, written.Passed)
+	assert.Equal(t, report.Counts, written.Counts)
+	assert.Equal(t, report.Services[0].Cases[0].Metrics, written.Services[0].Cases[0].Metrics)
+
+	junitOutput := Output{Format: FormatJUnit, Path: filepath.Join(dir, "junit.xml")}
+	assert.NoError(t, junitOutput.Write(report))
+	junitBytes, err := os.ReadFile(junitOutput.Path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(junitBytes), `<testsuite name="search-service"`)
+	assert.Contains(t, string(junitBytes), `<property name="capacity" value="10"></property>`)
+}
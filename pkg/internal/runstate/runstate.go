@@ -0,0 +1,389 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package runstate implements run-state file which records progress of exec command to resume it.
+package runstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/capacity"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+)
+
+// FileName is file name of run-state file in run directory.
+const FileName = "state.json"
+
+// ScenarioStatus is status of each scenario in run-state file.
+type ScenarioStatus string
+
+// Status values of scenario.
+const (
+	StatusPending   ScenarioStatus = "pending"
+	StatusRunning   ScenarioStatus = "running"
+	StatusCompleted ScenarioStatus = "completed"
+	StatusFailed    ScenarioStatus = "failed"
+	StatusSkipped   ScenarioStatus = "skipped"
+	// StatusAborted means the loadtest was aborted while running by abort rules.
+	StatusAborted ScenarioStatus = "aborted"
+	// StatusCancelled means the loadtest was cancelled while running by interrupt or termination signal.
+	StatusCancelled ScenarioStatus = "cancelled"
+)
+
+// RunState has progress of all services and scenarios in exec command run.
+type RunState struct {
+	RunID     string         `json:"runID"`
+	ImageURL  string         `json:"imageURL"`
+	StartedAt time.Time      `json:"startedAt"`
+	Services  []ServiceState `json:"services"`
+}
+
+// ServiceState has progress of scenarios of the service.
+type ServiceState struct {
+	Name string `json:"name"`
+	// Stopped is true when following scenarios are not executed by checkContinueToExec result.
+	Stopped    bool   `json:"stopped"`
+	StopReason string `json:"stopReason,omitempty"`
+	// Capacity is set when capacity search of the service finished.
+	Capacity  *capacity.Result `json:"capacity,omitempty"`
+	Scenarios []ScenarioState  `json:"scenarios"`
+}
+
+// ScenarioState has status of the scenario, and Gatling object and outputs of it.
+type ScenarioState struct {
+	Name              string         `json:"name"`
+	SubName           string         `json:"subName"`
+	Status            ScenarioStatus `json:"status"`
+	GatlingName       string         `json:"gatlingName,omitempty"`
+	GatlingNamespace  string         `json:"gatlingNamespace,omitempty"`
+	ReportStoragePath string         `json:"reportStoragePath,omitempty"`
+	Outputs           []string       `json:"outputs,omitempty"`
+	Error             string         `json:"error,omitempty"`
+	AbortReason       string         `json:"abortReason,omitempty"`
+	// Outcome is classified outcome of Gatling Job, such as succeeded and runner-failed, empty when not known.
+	Outcome string `json:"outcome,omitempty"`
+	// LoadGeneratorSaturated is true when Gatling runner pods exceeded runnerSaturation threshold.
+	LoadGeneratorSaturated bool     `json:"loadGeneratorSaturated,omitempty"`
+	SaturationReasons      []string `json:"saturationReasons,omitempty"`
+	// UnhealthyTargets has health summary of targets which pods restarted, OOMKilled, evicted or flapped.
+	UnhealthyTargets []string  `json:"unhealthyTargets,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	// Metrics is used as baseline of following runs.
+	Metrics    *baseline.Metrics    `json:"metrics,omitempty"`
+	Comparison *baseline.Comparison `json:"comparison,omitempty"`
+	SLO        *slo.Result          `json:"slo,omitempty"`
+}
+
+/*
+Recorder holds RunState and writes it to file every time it is updated.
+
+Recorder is shared among goroutines of each service, so its methods are guarded by mutex.
+*/
+type Recorder struct {
+	mu    sync.Mutex
+	path  string
+	state *RunState
+}
+
+// ScenarioRecorder updates state of one scenario through Recorder.
+type ScenarioRecorder struct {
+	recorder        *Recorder
+	serviceName     string
+	scenarioName    string
+	scenarioSubName string
+}
+
+// Load reads run-state file.
+func Load(path string) (*RunState, error) {
+	stateBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state RunState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse run-state file %v, %w", path, err)
+	}
+	return &state, nil
+}
+
+/*
+ListRuns reads run-state files in run directories under runsDir, and returns them in order of start time.
+
+Run directories which have no valid run-state file are ignored.
+*/
+func ListRuns(runsDir string) ([]*RunState, error) {
+	statePaths, err := filepath.Glob(filepath.Join(runsDir, "*", FileName))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*RunState, 0, len(statePaths))
+	for _, statePath := range statePaths {
+		state, err := Load(statePath)
+		if err != nil {
+			continue
+		}
+		runs = append(runs, state)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+/*
+FindBaseline returns run ID and state of the scenario used as baseline of the scenario in current run.
+
+If pinnedRunID is specified, the scenario in the run is used. Otherwise the scenario in the last green run is used.
+Only completed scenarios which have metrics comparable with current metrics can be baseline, so that metrics read
+from the report or from simulation.log with different warm-up are not compared. If there is no baseline, returns nil.
+*/
+func FindBaseline(
+	runs []*RunState,
+	currentRunID, pinnedRunID string,
+	serviceName, scenarioName, scenarioSubName string,
+	current baseline.Metrics,
+) (string, *ScenarioState) {
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.RunID == currentRunID {
+			continue
+		}
+		if pinnedRunID != "" && run.RunID != pinnedRunID {
+			continue
+		}
+		if pinnedRunID == "" && !run.IsGreen() {
+			continue
+		}
+		scenario := run.findScenario(serviceName, scenarioName, scenarioSubName)
+		if scenario != nil && scenario.Status == StatusCompleted && scenario.Metrics != nil &&
+			scenario.Metrics.Comparable(current) {
+			return run.RunID, scenario
+		}
+	}
+	return "", nil
+}
+
+/*
+IsGreen returns whether no scenario of the run failed, was cancelled, regressed or failed SLO.
+
+SLO of capacity search trials decides capacity, so it is not taken into account.
+*/
+func (s *RunState) IsGreen() bool {
+	for _, service := range s.Services {
+		for _, scenario := range service.Scenarios {
+			switch {
+			case scenario.Status == StatusFailed, scenario.Status == StatusAborted, scenario.Status == StatusCancelled:
+				return false
+			case scenario.Comparison.Regressed():
+				return false
+			case service.Capacity == nil && scenario.SLO.Failed():
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// findScenario returns pointer to ScenarioState, returns nil when it is not found.
+func (s *RunState) findScenario(serviceName, scenarioName, scenarioSubName string) *ScenarioState {
+	for i := range s.Services {
+		if s.Services[i].Name != serviceName {
+			continue
+		}
+		for j := range s.Services[i].Scenarios {
+			scenario := &s.Services[i].Scenarios[j]
+			if scenario.Name == scenarioName && scenario.SubName == scenarioSubName {
+				return scenario
+			}
+		}
+	}
+	return nil
+}
+
+// NewRecorder returns Recorder and writes given state to path.
+func NewRecorder(path string, state *RunState) (*Recorder, error) {
+	r := &Recorder{
+		path:  path,
+		state: state,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create run-state file directory, %w", err)
+	}
+	if err := r.write(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// State returns copy of RunState, nested fields are shared with Recorder so they must not be modified.
+func (r *Recorder) State() RunState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *r.state
+	copied.Services = make([]ServiceState, len(r.state.Services))
+	for i, service := range r.state.Services {
+		copied.Services[i] = service
+		copied.Services[i].Scenarios = append([]ScenarioState(nil), service.Scenarios...)
+	}
+	return copied
+}
+
+// Path returns path of run-state file.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// RunDir returns run directory, in which run-state file exists.
+func (r *Recorder) RunDir() string {
+	return filepath.Dir(r.path)
+}
+
+/*
+AddScenario adds scenario of service as pending if it is not recorded yet.
+
+Scenarios which are already recorded, for example loaded from run-state file of resumed run, are kept as they are.
+*/
+func (r *Recorder) AddScenario(serviceName, scenarioName, scenarioSubName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.findOrAddScenario(serviceName, scenarioName, scenarioSubName)
+	return r.write()
+}
+
+// Scenario returns ScenarioRecorder of specified scenario.
+func (r *Recorder) Scenario(serviceName, scenarioName, scenarioSubName string) *ScenarioRecorder {
+	return &ScenarioRecorder{
+		recorder:        r,
+		serviceName:     serviceName,
+		scenarioName:    scenarioName,
+		scenarioSubName: scenarioSubName,
+	}
+}
+
+// Service returns copy of ServiceState of specified service.
+func (r *Recorder) Service(serviceName string) ServiceState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	service := r.findOrAddService(serviceName)
+	copied := *service
+	copied.Scenarios = append([]ScenarioState(nil), service.Scenarios...)
+	return copied
+}
+
+// StopService records that following scenarios of service are not executed.
+func (r *Recorder) StopService(serviceName, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	service := r.findOrAddService(serviceName)
+	service.Stopped = true
+	service.StopReason = reason
+	return r.write()
+}
+
+// SetCapacity records result of capacity search of service.
+func (r *Recorder) SetCapacity(serviceName string, result capacity.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	service := r.findOrAddService(serviceName)
+	service.Capacity = &result
+	return r.write()
+}
+
+// RunDir returns run directory, in which run-state file exists.
+func (sr *ScenarioRecorder) RunDir() string {
+	return sr.recorder.RunDir()
+}
+
+// Get returns copy of ScenarioState.
+func (sr *ScenarioRecorder) Get() ScenarioState {
+	sr.recorder.mu.Lock()
+	defer sr.recorder.mu.Unlock()
+	scenario := sr.recorder.findOrAddScenario(sr.serviceName, sr.scenarioName, sr.scenarioSubName)
+	copied := *scenario
+	copied.Outputs = append([]string(nil), scenario.Outputs...)
+	return copied
+}
+
+// Update updates ScenarioState by given function and writes run-state file.
+func (sr *ScenarioRecorder) Update(update func(scenario *ScenarioState)) error {
+	sr.recorder.mu.Lock()
+	defer sr.recorder.mu.Unlock()
+	scenario := sr.recorder.findOrAddScenario(sr.serviceName, sr.scenarioName, sr.scenarioSubName)
+	update(scenario)
+	scenario.UpdatedAt = time.Now()
+	return sr.recorder.write()
+}
+
+// findOrAddService returns pointer to ServiceState, caller must hold lock.
+func (r *Recorder) findOrAddService(serviceName string) *ServiceState {
+	for i := range r.state.Services {
+		if r.state.Services[i].Name == serviceName {
+			return &r.state.Services[i]
+		}
+	}
+	r.state.Services = append(r.state.Services, ServiceState{Name: serviceName})
+	return &r.state.Services[len(r.state.Services)-1]
+}
+
+// findOrAddScenario returns pointer to ScenarioState, caller must hold lock.
+func (r *Recorder) findOrAddScenario(serviceName, scenarioName, scenarioSubName string) *ScenarioState {
+	service := r.findOrAddService(serviceName)
+	for i := range service.Scenarios {
+		if service.Scenarios[i].Name == scenarioName && service.Scenarios[i].SubName == scenarioSubName {
+			return &service.Scenarios[i]
+		}
+	}
+	service.Scenarios = append(service.Scenarios, ScenarioState{
+		Name:      scenarioName,
+		SubName:   scenarioSubName,
+		Status:    StatusPending,
+		UpdatedAt: time.Now(),
+	})
+	return &service.Scenarios[len(service.Scenarios)-1]
+}
+
+/*
+write writes state to file, caller must hold lock.
+
+State is written to temporary file and renamed, so the file is not broken even if process dies while writing.
+*/
+func (r *Recorder) write() error {
+	stateBytes, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, stateBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write run-state file, %w", err)
+	}
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		return fmt.Errorf("failed to write run-state file, %w", err)
+	}
+	return nil
+}
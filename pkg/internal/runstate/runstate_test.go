@@ -0,0 +1,220 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package runstate
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/baseline"
+	"github.com/st-tech/gatling-commander/pkg/internal/slo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "runs", "202308021850-abcd", FileName)
+	recorder, err := NewRecorder(statePath, &RunState{
+		RunID:     "202308021850-abcd",
+		ImageURL:  "example/gatling-scenario/sample-202308021850",
+		StartedAt: time.Date(2023, 8, 2, 18, 50, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.AddScenario("sample-service", "sample-scenario", "1"))
+	assert.NoError(t, recorder.AddScenario("sample-service", "sample-scenario", "2"))
+	assert.NoError(t, recorder.AddScenario("other-service", "sample-scenario", "1"))
+
+	// each service is recorded in parallel.
+	wg := new(sync.WaitGroup)
+	for _, serviceName := range []string{"sample-service", "other-service"} {
+		wg.Add(1)
+		go func(serviceName string) {
+			defer wg.Done()
+			err := recorder.Scenario(serviceName, "sample-scenario", "1").Update(func(state *ScenarioState) {
+				state.Status = StatusCompleted
+				state.GatlingName = serviceName + "-gatling"
+				state.Outputs = append(state.Outputs, "spreadsheet sample sheet sample-scenario-20230802")
+			})
+			assert.NoError(t, err)
+		}(serviceName)
+	}
+	wg.Wait()
+	assert.NoError(t, recorder.StopService("other-service", "failed percentage greater than 0"))
+
+	loaded, err := Load(statePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "202308021850-abcd", loaded.RunID)
+	assert.Equal(t, 2, len(loaded.Services))
+	assert.Equal(t, "sample-service", loaded.Services[0].Name)
+	assert.Equal(t, StatusCompleted, loaded.Services[0].Scenarios[0].Status)
+	assert.Equal(t, "sample-service-gatling", loaded.Services[0].Scenarios[0].GatlingName)
+	assert.Equal(t, StatusPending, loaded.Services[0].Scenarios[1].Status)
+	assert.True(t, loaded.Services[1].Stopped)
+
+	// recorded scenario is kept when it is added again in resumed run.
+	resumed, err := NewRecorder(statePath, loaded)
+	assert.NoError(t, err)
+	assert.NoError(t, resumed.AddScenario("sample-service", "sample-scenario", "1"))
+	assert.NoError(t, resumed.AddScenario("sample-service", "sample-scenario", "3"))
+	scenario := resumed.Scenario("sample-service", "sample-scenario", "1").Get()
+	assert.Equal(t, StatusCompleted, scenario.Status)
+	assert.Equal(t, []string{"spreadsheet sample sheet sample-scenario-20230802"}, scenario.Outputs)
+	assert.Equal(t, 3, len(resumed.Service("sample-service").Scenarios))
+}
+
+func TestLoad_Failed(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "not_exists.json"))
+	assert.Error(t, err)
+	_, err = Load("runstate.go")
+	assert.Error(t, err)
+}
+
+// writeRun writes run-state file of run which has one completed scenario to runsDir.
+func writeRun(t *testing.T, runsDir, runID string, startedAt time.Time, scenarios ...ScenarioState) {
+	_, err := NewRecorder(filepath.Join(runsDir, runID, FileName), &RunState{
+		RunID:     runID,
+		StartedAt: startedAt,
+		Services: []ServiceState{
+			{
+				Name:      "sample-service",
+				Scenarios: scenarios,
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestListRunsAndFindBaseline(t *testing.T) {
+	runsDir := t.TempDir()
+	startedAt := time.Date(2023, 8, 2, 18, 50, 0, 0, time.UTC)
+	metrics := &baseline.Metrics{P99Latency: 100}
+	completed := ScenarioState{Name: "sample-scenario", SubName: "1", Status: StatusCompleted, Metrics: metrics}
+	regressed := completed
+	regressed.Comparison = &baseline.Comparison{
+		BaselineRunID: "run-1",
+		Regressions:   []baseline.Regression{{Metric: baseline.MetricP99Latency}},
+	}
+	failed := ScenarioState{Name: "sample-scenario", SubName: "1", Status: StatusFailed}
+	cancelled := ScenarioState{Name: "sample-scenario", SubName: "2", Status: StatusCancelled}
+	sloFailed := completed
+	sloFailed.SLO = &slo.Result{Assertions: []slo.AssertionResult{
+		{Assertion: "p99 < 50ms", Severity: slo.SeverityFailRun, Passed: false, Actual: 100, Threshold: 50},
+	}}
+	simulationLogMetrics := baseline.Metrics{P99Latency: 90, Source: baseline.SourceSimulationLog, WarmUpSec: 60}
+	fromSimulationLog := completed
+	fromSimulationLog.Metrics = &simulationLogMetrics
+
+	// run-1 and run-2 are green, run-3 is regressed, run-4 is failed, run-5 is cancelled and run-6 failed SLO.
+	// run-7 is green, and its metrics are read from simulation.log.
+	writeRun(t, runsDir, "run-2", startedAt.Add(time.Hour), completed)
+	writeRun(t, runsDir, "run-1", startedAt, completed)
+	writeRun(t, runsDir, "run-3", startedAt.Add(2*time.Hour), regressed)
+	writeRun(t, runsDir, "run-4", startedAt.Add(3*time.Hour), failed)
+	writeRun(t, runsDir, "run-5", startedAt.Add(4*time.Hour), completed, cancelled)
+	writeRun(t, runsDir, "run-6", startedAt.Add(5*time.Hour), sloFailed)
+	writeRun(t, runsDir, "run-7", startedAt.Add(6*time.Hour), fromSimulationLog)
+
+	runs, err := ListRuns(runsDir)
+	assert.NoError(t, err)
+	runIDs := make([]string, 0, len(runs))
+	for _, run := range runs {
+		runIDs = append(runIDs, run.RunID)
+	}
+	assert.Equal(t, []string{"run-1", "run-2", "run-3", "run-4", "run-5", "run-6", "run-7"}, runIDs)
+
+	tests := []struct {
+		name          string
+		currentRunID  string
+		pinnedRunID   string
+		scenarioName  string
+		current       baseline.Metrics
+		expectedRunID string
+	}{
+		{
+			name:          "last green run is used",
+			currentRunID:  "run-8",
+			expectedRunID: "run-2",
+			scenarioName:  "sample-scenario",
+		},
+		{
+			name:          "current run is not used",
+			currentRunID:  "run-2",
+			expectedRunID: "run-1",
+			scenarioName:  "sample-scenario",
+		},
+		{
+			name:          "pinned run is used even if it is regressed",
+			currentRunID:  "run-8",
+			pinnedRunID:   "run-3",
+			expectedRunID: "run-3",
+			scenarioName:  "sample-scenario",
+		},
+		{
+			name:          "failed scenario is not used as baseline",
+			currentRunID:  "run-8",
+			pinnedRunID:   "run-4",
+			expectedRunID: "",
+			scenarioName:  "sample-scenario",
+		},
+		{
+			name:          "last green run of the same metrics source is used",
+			currentRunID:  "run-8",
+			expectedRunID: "run-7",
+			scenarioName:  "sample-scenario",
+			current:       baseline.Metrics{Source: baseline.SourceSimulationLog, WarmUpSec: 60},
+		},
+		{
+			name:          "run of different warm-up is not used",
+			currentRunID:  "run-8",
+			expectedRunID: "",
+			scenarioName:  "sample-scenario",
+			current:       baseline.Metrics{Source: baseline.SourceSimulationLog, WarmUpSec: 30},
+		},
+		{
+			name:          "pinned run of different metrics source is not used",
+			currentRunID:  "run-8",
+			pinnedRunID:   "run-7",
+			expectedRunID: "",
+			scenarioName:  "sample-scenario",
+			current:       baseline.Metrics{Source: baseline.SourceReport},
+		},
+		{
+			name:          "scenario not found",
+			currentRunID:  "run-8",
+			expectedRunID: "",
+			scenarioName:  "other-scenario",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runID, scenario := FindBaseline(
+				runs, tt.currentRunID, tt.pinnedRunID, "sample-service", tt.scenarioName, "1", tt.current,
+			)
+			assert.Equal(t, tt.expectedRunID, runID)
+			assert.Equal(t, tt.expectedRunID != "", scenario != nil)
+		})
+	}
+}
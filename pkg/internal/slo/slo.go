@@ -0,0 +1,312 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package slo implements assertions on loadtest result, such as "p99 < 300ms", which decide outcome of loadtest.
+package slo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+)
+
+// Severity decides how violation of assertion affects run.
+type Severity string
+
+// Severities of assertion.
+const (
+	// SeverityStop stops following loadtests of the service, and fails run.
+	SeverityStop Severity = "stop"
+	// SeverityFailRun fails run, and following loadtests of the service are executed.
+	SeverityFailRun Severity = "fail-run"
+	// SeverityWarn only records and notifies violation.
+	SeverityWarn Severity = "warn"
+)
+
+// Metric names of assertion, latency percentile is named p followed by percentile, such as p99 and p99.9.
+const (
+	MetricMean          = "mean"
+	MetricMax           = "max"
+	MetricFailedPercent = "failed%"
+	MetricRPS           = "rps"
+	MetricCPUPercent    = "cpu%"
+	MetricMemoryPercent = "memory%"
+)
+
+// assertionPattern matches assertion such as "p99 < 300ms", "failed% <= 0.1" and "rps >= 95% of target".
+var assertionPattern = regexp.MustCompile(
+	`^\s*(p\d+(?:\.\d+)?|[a-z]+%?)\s*(<=|>=|<|>)\s*(\d+(?:\.\d+)?)\s*(ms|%)?(\s+of target)?\s*$`,
+)
+
+/*
+Assertion is parsed assertion on loadtest result.
+
+Value is compared with actual value of Metric by Operator. When OfTarget is true, Value is percentage of target rps.
+*/
+type Assertion struct {
+	Expr       string
+	Metric     string
+	Percentile float64 // percentile of latency, 0 when Metric is not latency percentile
+	Operator   string
+	Value      float64
+	OfTarget   bool
+	Severity   Severity
+}
+
+// Values has loadtest result which assertions are evaluated with.
+type Values struct {
+	Report        *gatling.GatlingReport
+	CPUPercent    float64 // cpu usage of the primary target
+	MemoryPercent float64 // memory usage of the primary target
+	TargetRPS     float64 // 0 when target rps is not set
+}
+
+// AssertionResult is result of evaluating an assertion.
+type AssertionResult struct {
+	Assertion string   `json:"assertion"`
+	Severity  Severity `json:"severity"`
+	Passed    bool     `json:"passed"`
+	Actual    float64  `json:"actual"`
+	Threshold float64  `json:"threshold"`
+	// Error is reason why the assertion could not be evaluated, and such assertion is not passed.
+	Error string `json:"error,omitempty"`
+}
+
+// Result has results of all assertions of loadtest.
+type Result struct {
+	Assertions []AssertionResult `json:"assertions"`
+}
+
+/*
+ParseAssertion parses expr as assertion with severity.
+
+expr is "<metric> <operator> <value>", operator is one of <, <=, > and >=. Metrics and units of value are below.
+  - p<percentile>, mean and max: latency (ms) of OK requests, value may have ms unit
+  - failed%: percentage of failed requests, value may have % unit
+  - rps: mean requests per second, value may be percentage of target rps such as "95% of target"
+  - cpu% and memory%: usage (%) of resources limit of the primary target container, value may have % unit
+*/
+func ParseAssertion(expr string, severity Severity) (Assertion, error) {
+	switch severity {
+	case SeverityStop, SeverityFailRun, SeverityWarn:
+	default:
+		return Assertion{}, fmt.Errorf(
+			"severity of %q must be one of %v, %v and %v", expr, SeverityStop, SeverityFailRun, SeverityWarn,
+		)
+	}
+	match := assertionPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return Assertion{}, fmt.Errorf("assertion %q must be formatted as <metric> <operator> <value>", expr)
+	}
+	value, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return Assertion{}, fmt.Errorf("assertion %q has invalid value, %v", expr, err)
+	}
+	assertion := Assertion{
+		Expr:     strings.TrimSpace(expr),
+		Metric:   match[1],
+		Operator: match[2],
+		Value:    value,
+		OfTarget: match[5] != "",
+		Severity: severity,
+	}
+	unit := match[4]
+	switch {
+	case strings.HasPrefix(assertion.Metric, "p"):
+		percentile, err := strconv.ParseFloat(strings.TrimPrefix(assertion.Metric, "p"), 64)
+		if err != nil || percentile <= 0 || percentile > 100 {
+			return Assertion{}, fmt.Errorf("assertion %q percentile must be more than 0 and not more than 100", expr)
+		}
+		assertion.Percentile = percentile
+		fallthrough
+	case assertion.Metric == MetricMean, assertion.Metric == MetricMax:
+		if unit != "" && unit != "ms" {
+			return Assertion{}, fmt.Errorf("assertion %q latency unit must be ms", expr)
+		}
+	case assertion.Metric == MetricFailedPercent,
+		assertion.Metric == MetricCPUPercent,
+		assertion.Metric == MetricMemoryPercent:
+		if unit != "" && unit != "%" {
+			return Assertion{}, fmt.Errorf("assertion %q percentage unit must be %%", expr)
+		}
+	case assertion.Metric == MetricRPS:
+		if assertion.OfTarget != (unit == "%") {
+			return Assertion{}, fmt.Errorf("assertion %q rps must be number or percentage of target", expr)
+		}
+		return assertion, nil
+	default:
+		return Assertion{}, fmt.Errorf("assertion %q has unknown metric %v", expr, assertion.Metric)
+	}
+	if assertion.OfTarget {
+		return Assertion{}, fmt.Errorf("assertion %q only rps can be compared with target", expr)
+	}
+	return assertion, nil
+}
+
+// Percentiles returns percentiles of latency asserted in assertions.
+func Percentiles(assertions []Assertion) []float64 {
+	var percentiles []float64
+	for _, assertion := range assertions {
+		if assertion.Percentile != 0 {
+			percentiles = append(percentiles, assertion.Percentile)
+		}
+	}
+	return percentiles
+}
+
+// Evaluate evaluates assertions with values, and returns nil when there is no assertion.
+func Evaluate(assertions []Assertion, values Values) *Result {
+	if len(assertions) == 0 {
+		return nil
+	}
+	result := &Result{Assertions: make([]AssertionResult, 0, len(assertions))}
+	for _, assertion := range assertions {
+		assertionResult := AssertionResult{
+			Assertion: assertion.Expr,
+			Severity:  assertion.Severity,
+		}
+		actual, threshold, err := assertion.resolve(values)
+		if err != nil {
+			assertionResult.Error = err.Error()
+		} else {
+			assertionResult.Actual = actual
+			assertionResult.Threshold = threshold
+			assertionResult.Passed = compare(actual, assertion.Operator, threshold)
+		}
+		result.Assertions = append(result.Assertions, assertionResult)
+	}
+	return result
+}
+
+// resolve returns actual value of metric and threshold which it is compared with.
+func (a Assertion) resolve(values Values) (float64, float64, error) {
+	threshold := a.Value
+	if a.OfTarget {
+		if values.TargetRPS <= 0 {
+			return 0, 0, fmt.Errorf("targetRPS is not set")
+		}
+		threshold = values.TargetRPS * a.Value / 100
+	}
+	switch a.Metric {
+	case MetricCPUPercent:
+		return values.CPUPercent, threshold, nil
+	case MetricMemoryPercent:
+		return values.MemoryPercent, threshold, nil
+	}
+	if values.Report == nil {
+		return 0, 0, fmt.Errorf("gatling report is not loaded")
+	}
+	switch a.Metric {
+	case MetricMean:
+		return values.Report.MeanResponseTime.Ok, threshold, nil
+	case MetricMax:
+		return values.Report.MaxResponseTime.Ok, threshold, nil
+	case MetricFailedPercent:
+		return values.Report.Failed.Percentage, threshold, nil
+	case MetricRPS:
+		return values.Report.MeanNumberOfRequestsPerSecond.Total, threshold, nil
+	}
+	latency, err := values.Report.GetPercentileLatency(a.Percentile)
+	if err != nil {
+		return 0, 0, err
+	}
+	return latency, threshold, nil
+}
+
+func compare(actual float64, operator string, threshold float64) bool {
+	switch operator {
+	case "<":
+		return actual < threshold
+	case "<=":
+		return actual <= threshold
+	case ">":
+		return actual > threshold
+	case ">=":
+		return actual >= threshold
+	}
+	return false
+}
+
+// Violations returns results of assertions which are not passed.
+func (r *Result) Violations() []AssertionResult {
+	if r == nil {
+		return nil
+	}
+	var violations []AssertionResult
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			violations = append(violations, assertion)
+		}
+	}
+	return violations
+}
+
+// ShouldStop returns whether any assertion of stop severity is violated.
+func (r *Result) ShouldStop() bool {
+	for _, violation := range r.Violations() {
+		if violation.Severity == SeverityStop {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed returns whether any assertion of stop or fail-run severity is violated, which fails run.
+func (r *Result) Failed() bool {
+	for _, violation := range r.Violations() {
+		if violation.Severity != SeverityWarn {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Summary returns one line summary of result, it is printed and notified.
+
+Summary of nil Result means there is no assertion.
+*/
+func (r *Result) Summary() string {
+	if r == nil {
+		return "no slo"
+	}
+	violations := r.Violations()
+	if len(violations) == 0 {
+		return fmt.Sprintf("slo met, %v assertions passed", len(r.Assertions))
+	}
+	summaries := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		if violation.Error != "" {
+			summaries = append(summaries, fmt.Sprintf(
+				"%v [%v] (%v)", violation.Assertion, violation.Severity, violation.Error,
+			))
+			continue
+		}
+		summaries = append(summaries, fmt.Sprintf(
+			"%v [%v] (result: %.4g)", violation.Assertion, violation.Severity, violation.Actual,
+		))
+	}
+	return fmt.Sprintf("slo violated: %v", strings.Join(summaries, ", "))
+}
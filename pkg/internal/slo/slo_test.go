@@ -0,0 +1,215 @@
+/*
+Copyright &copy; ZOZO, Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the “Software”), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included
+in all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package slo
+
+import (
+	"testing"
+
+	"github.com/st-tech/gatling-commander/pkg/internal/gatling"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssertion(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		severity    Severity
+		expected    Assertion
+		expectedErr string
+	}{
+		{
+			name:     "latency percentile",
+			expr:     "p99 < 300ms",
+			severity: SeverityStop,
+			expected: Assertion{
+				Expr: "p99 < 300ms", Metric: "p99", Percentile: 99, Operator: "<", Value: 300, Severity: SeverityStop,
+			},
+		},
+		{
+			name:     "latency percentile with decimal point and without unit",
+			expr:     "p99.9<=500",
+			severity: SeverityWarn,
+			expected: Assertion{
+				Expr: "p99.9<=500", Metric: "p99.9", Percentile: 99.9, Operator: "<=", Value: 500, Severity: SeverityWarn,
+			},
+		},
+		{
+			name:     "failed percentage",
+			expr:     "failed% <= 0.1",
+			severity: SeverityFailRun,
+			expected: Assertion{
+				Expr: "failed% <= 0.1", Metric: MetricFailedPercent, Operator: "<=", Value: 0.1, Severity: SeverityFailRun,
+			},
+		},
+		{
+			name:     "rps of target",
+			expr:     "rps >= 95% of target",
+			severity: SeverityFailRun,
+			expected: Assertion{
+				Expr:     "rps >= 95% of target",
+				Metric:   MetricRPS,
+				Operator: ">=",
+				Value:    95,
+				OfTarget: true,
+				Severity: SeverityFailRun,
+			},
+		},
+		{
+			name:        "invalid severity",
+			expr:        "mean < 80ms",
+			severity:    "error",
+			expectedErr: `severity of "mean < 80ms" must be one of stop, fail-run and warn`,
+		},
+		{
+			name:        "invalid format",
+			expr:        "p99 is less than 300ms",
+			severity:    SeverityStop,
+			expectedErr: `assertion "p99 is less than 300ms" must be formatted as <metric> <operator> <value>`,
+		},
+		{
+			name:        "unknown metric",
+			expr:        "latency < 300ms",
+			severity:    SeverityStop,
+			expectedErr: `assertion "latency < 300ms" has unknown metric latency`,
+		},
+		{
+			name:        "percentile out of range",
+			expr:        "p101 < 300ms",
+			severity:    SeverityStop,
+			expectedErr: `assertion "p101 < 300ms" percentile must be more than 0 and not more than 100`,
+		},
+		{
+			name:        "latency with percentage unit",
+			expr:        "mean < 80%",
+			severity:    SeverityStop,
+			expectedErr: `assertion "mean < 80%" latency unit must be ms`,
+		},
+		{
+			name:        "rps percentage without target",
+			expr:        "rps >= 95%",
+			severity:    SeverityStop,
+			expectedErr: `assertion "rps >= 95%" rps must be number or percentage of target`,
+		},
+		{
+			name:        "cpu compared with target",
+			expr:        "cpu% < 70% of target",
+			severity:    SeverityStop,
+			expectedErr: `assertion "cpu% < 70% of target" only rps can be compared with target`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseAssertion(tt.expr, tt.severity)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	mustParse := func(expr string, severity Severity) Assertion {
+		assertion, err := ParseAssertion(expr, severity)
+		assert.NoError(t, err)
+		return assertion
+	}
+	assertions := []Assertion{
+		mustParse("p99 < 300ms", SeverityStop),
+		mustParse("mean < 80ms", SeverityFailRun),
+		mustParse("failed% <= 0.1", SeverityFailRun),
+		mustParse("rps >= 95% of target", SeverityWarn),
+		mustParse("cpu% < 70", SeverityWarn),
+		mustParse("p99.9 < 500ms", SeverityFailRun),
+	}
+	values := Values{
+		Report: &gatling.GatlingReport{
+			NintyNinthPercentiles:         gatling.GatlingReportStats{Ok: 250},
+			MeanResponseTime:              gatling.GatlingReportStats{Ok: 90},
+			Failed:                        gatling.GatlingReportGroup{Percentage: 0},
+			MeanNumberOfRequestsPerSecond: gatling.GatlingReportStats{Total: 100},
+		},
+		CPUPercent: 50,
+		TargetRPS:  100,
+	}
+	result := Evaluate(assertions, values)
+	assert.Equal(t, []AssertionResult{
+		{Assertion: "p99 < 300ms", Severity: SeverityStop, Passed: true, Actual: 250, Threshold: 300},
+		{Assertion: "mean < 80ms", Severity: SeverityFailRun, Passed: false, Actual: 90, Threshold: 80},
+		{Assertion: "failed% <= 0.1", Severity: SeverityFailRun, Passed: true, Actual: 0, Threshold: 0.1},
+		{Assertion: "rps >= 95% of target", Severity: SeverityWarn, Passed: true, Actual: 100, Threshold: 95},
+		{Assertion: "cpu% < 70", Severity: SeverityWarn, Passed: true, Actual: 50, Threshold: 70},
+		{
+			Assertion: "p99.9 < 500ms",
+			Severity:  SeverityFailRun,
+			Error: "specified percentile 99.9 is not matched to GatlingReport percentiles [50 75 95 99], " +
+				"and not derived from simulation.log",
+		},
+	}, result.Assertions)
+	assert.False(t, result.ShouldStop())
+	assert.True(t, result.Failed())
+	assert.Equal(
+		t,
+		"slo violated: mean < 80ms [fail-run] (result: 90), p99.9 < 500ms [fail-run] "+
+			"(specified percentile 99.9 is not matched to GatlingReport percentiles [50 75 95 99], "+
+			"and not derived from simulation.log)",
+		result.Summary(),
+	)
+
+	// rps of target can not be evaluated without target rps.
+	values.TargetRPS = 0
+	result = Evaluate([]Assertion{mustParse("rps >= 95% of target", SeverityStop)}, values)
+	assert.Equal(t, "targetRPS is not set", result.Assertions[0].Error)
+	assert.True(t, result.ShouldStop())
+
+	assert.Nil(t, Evaluate(nil, values))
+}
+
+func TestResultSummary(t *testing.T) {
+	var result *Result
+	assert.Equal(t, "no slo", result.Summary())
+	assert.False(t, result.Failed())
+
+	result = &Result{Assertions: []AssertionResult{
+		{Assertion: "p99 < 300ms", Severity: SeverityStop, Passed: true, Actual: 250, Threshold: 300},
+		{Assertion: "cpu% < 70", Severity: SeverityWarn, Passed: false, Actual: 75, Threshold: 70},
+	}}
+	assert.Equal(t, "slo violated: cpu% < 70 [warn] (result: 75)", result.Summary())
+	assert.False(t, result.Failed())
+	assert.False(t, result.ShouldStop())
+
+	result.Assertions = result.Assertions[:1]
+	assert.Equal(t, "slo met, 1 assertions passed", result.Summary())
+}
+
+func TestPercentiles(t *testing.T) {
+	assertions := []Assertion{
+		{Metric: "p99.9", Percentile: 99.9},
+		{Metric: MetricMean},
+		{Metric: "p99", Percentile: 99},
+	}
+	assert.Equal(t, []float64{99.9, 99}, Percentiles(assertions))
+}